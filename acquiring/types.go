@@ -1,6 +1,11 @@
 package acquiring
 
-import "encoding/json"
+import (
+	"encoding/json"
+
+	"github.com/stremovskyy/go-nova/consts"
+	"github.com/stremovskyy/go-nova/money"
+)
 
 // CreateSessionRequest corresponds to "Create session" (POST /v1/session).
 type CreateSessionRequest struct {
@@ -27,10 +32,10 @@ type CreateSessionResponse struct {
 
 // AddPaymentRequest corresponds to "Add payment" (POST /v1/payment).
 type AddPaymentRequest struct {
-	MerchantID string  `json:"merchant_id"`
-	SessionID  string  `json:"session_id"`
-	Amount     float64 `json:"amount"`
-	ExternalID *string `json:"external_id,omitempty"`
+	MerchantID string       `json:"merchant_id"`
+	SessionID  string       `json:"session_id"`
+	Amount     money.Amount `json:"amount"`
+	ExternalID *string      `json:"external_id,omitempty"`
 
 	UseHold    *bool     `json:"use_hold,omitempty"`
 	Identifier *string   `json:"identifier,omitempty"`
@@ -46,15 +51,15 @@ type Delivery struct {
 }
 
 type Product struct {
-	Description string  `json:"description"`
-	Count       int32   `json:"count"`
-	Price       float64 `json:"price"`
+	Description string       `json:"description"`
+	Count       int32        `json:"count"`
+	Price       money.Amount `json:"price"`
 }
 
 type AddPaymentResponse struct {
-	ID            string   `json:"id"`
-	URL           string   `json:"url"`
-	DeliveryPrice *float64 `json:"delivery_price,omitempty"`
+	ID            string        `json:"id"`
+	URL           string        `json:"url"`
+	DeliveryPrice *money.Amount `json:"delivery_price,omitempty"`
 }
 
 // SessionRequest is the payload used by endpoints that require merchant_id + session_id.
@@ -67,14 +72,14 @@ type SessionRequest struct {
 type CompleteHoldRequest struct {
 	MerchantID string                  `json:"merchant_id"`
 	SessionID  string                  `json:"session_id"`
-	Amount     *float64                `json:"amount,omitempty"`
+	Amount     *money.Amount           `json:"amount,omitempty"`
 	Operations []CompleteHoldOperation `json:"operations,omitempty"`
 }
 
 type CompleteHoldOperation struct {
-	ID                  string  `json:"id"`
-	Amount              float64 `json:"amount"`
-	RecipientIdentifier string  `json:"recipient_identifier"`
+	ID                  string       `json:"id"`
+	Amount              money.Amount `json:"amount"`
+	RecipientIdentifier string       `json:"recipient_identifier"`
 }
 
 type ConfirmDeliveryHoldResponse struct {
@@ -86,12 +91,12 @@ type ConfirmDeliveryHoldResponse struct {
 
 // DeliveryPriceRequest corresponds to "Delivery price" (POST /v1/delivery-price).
 type DeliveryPriceRequest struct {
-	MerchantID         string  `json:"merchant_id"`
-	RecipientCity      string  `json:"recipient_city"`
-	RecipientWarehouse string  `json:"recipient_warehouse"`
-	VolumeWeight       float64 `json:"volume_weight"`
-	Weight             float64 `json:"weight"`
-	Amount             float64 `json:"amount"`
+	MerchantID         string       `json:"merchant_id"`
+	RecipientCity      string       `json:"recipient_city"`
+	RecipientWarehouse string       `json:"recipient_warehouse"`
+	VolumeWeight       float64      `json:"volume_weight"`
+	Weight             float64      `json:"weight"`
+	Amount             money.Amount `json:"amount"`
 }
 
 // DeliveryPriceResponse schema is not fully described in public docs; keep it generic.
@@ -99,34 +104,34 @@ type DeliveryPriceResponse map[string]any
 
 // GetStatusResponse corresponds to "Get status" (POST /v1/get-status).
 type GetStatusResponse struct {
-	ID               string          `json:"id"`
-	Metadata         json.RawMessage `json:"metadata,omitempty"`
-	Paytype          string          `json:"paytype"`
-	ApprovalCode     *string         `json:"approval_code,omitempty"`
-	TerminalName     *string         `json:"terminal_name,omitempty"`
-	Status           string          `json:"status"`
-	CreatedAt        string          `json:"created_at"`
-	ClientPhone      *string         `json:"client_phone,omitempty"`
-	ClientFirstName  *string         `json:"client_first_name,omitempty"`
-	ClientLastName   *string         `json:"client_last_name,omitempty"`
-	ClientPatronymic *string         `json:"client_patronymic,omitempty"`
-	Pan              *string         `json:"pan,omitempty"`
-	Operations       []OperationInfo `json:"operations,omitempty"`
+	ID               string               `json:"id"`
+	Metadata         json.RawMessage      `json:"metadata,omitempty"`
+	Paytype          string               `json:"paytype"`
+	ApprovalCode     *string              `json:"approval_code,omitempty"`
+	TerminalName     *string              `json:"terminal_name,omitempty"`
+	Status           consts.SessionStatus `json:"status"`
+	CreatedAt        string               `json:"created_at"`
+	ClientPhone      *string              `json:"client_phone,omitempty"`
+	ClientFirstName  *string              `json:"client_first_name,omitempty"`
+	ClientLastName   *string              `json:"client_last_name,omitempty"`
+	ClientPatronymic *string              `json:"client_patronymic,omitempty"`
+	Pan              *string              `json:"pan,omitempty"`
+	Operations       []OperationInfo      `json:"operations,omitempty"`
 }
 
 type OperationInfo struct {
-	ExternalID *string `json:"external_id,omitempty"`
-	Amount     float64 `json:"amount"`
+	ExternalID *string      `json:"external_id,omitempty"`
+	Amount     money.Amount `json:"amount"`
 }
 
 // Postback is the current v3 callback payload from NovaPay.
 type Postback struct {
-	ID           string `json:"id"`
-	Status       string `json:"status"`
-	Paytype      string `json:"paytype"`
-	TerminalName string `json:"terminal_name"`
-	RRN          string `json:"RRN"`
-	APPROVAL     int64  `json:"APPROVAL"`
+	ID           string               `json:"id"`
+	Status       consts.SessionStatus `json:"status"`
+	Paytype      string               `json:"paytype"`
+	TerminalName string               `json:"terminal_name"`
+	RRN          string               `json:"RRN"`
+	APPROVAL     int64                `json:"APPROVAL"`
 
 	CreatedAt string          `json:"created_at"`
 	Metadata  json.RawMessage `json:"metadata,omitempty"`
@@ -151,7 +156,7 @@ type PostbackCard struct {
 }
 
 type PostbackPayment struct {
-	ExternalID *string   `json:"external_id,omitempty"`
-	Amount     float64   `json:"amount"`
-	Products   []Product `json:"products,omitempty"`
+	ExternalID *string      `json:"external_id,omitempty"`
+	Amount     money.Amount `json:"amount"`
+	Products   []Product    `json:"products,omitempty"`
 }
@@ -0,0 +1,265 @@
+// Package cassette records NovaPay HTTP traffic to a file and replays it in tests.
+//
+// A Cassette is an http.RoundTripper, so it plugs into the SDK through WithHTTPClient:
+//
+//	cas, err := cassette.New("testdata/hold_flow.json", cassette.ModeReplay)
+//	if err != nil {
+//		t.Fatal(err)
+//	}
+//	client, _ := go_nova.NewClient(
+//		go_nova.WithPrivateKey(key),
+//		go_nova.WithHTTPClient(&http.Client{Transport: cas}),
+//	)
+//
+// Record once against the sandbox with ModeRecord and call Save; replay in CI with
+// ModeReplay. Requests are matched on method, URL path and normalised JSON body.
+// RSA PKCS#1 v1.5 signatures are deterministic for a fixed key, so on replay the
+// x-sign the SDK sends must equal the recorded one: a mismatch means the signed
+// bytes changed, e.g. because of a change in JSON encoding.
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+
+	"github.com/stremovskyy/go-nova/consts"
+)
+
+// Mode selects whether a Cassette talks to the network.
+type Mode int
+
+const (
+	// ModeReplay serves responses from the cassette file and never sends requests.
+	ModeReplay Mode = iota
+	// ModeRecord sends requests through the underlying transport and records them.
+	ModeRecord
+)
+
+// ErrNoInteraction is returned on replay when no recorded interaction matches a request.
+var ErrNoInteraction = errors.New("cassette: no matching interaction")
+
+// SignatureMismatchError is returned on replay when the request matches a recorded
+// interaction but its x-sign differs from the recorded one.
+type SignatureMismatchError struct {
+	Method   string
+	Path     string
+	Recorded string
+	Got      string
+}
+
+func (e *SignatureMismatchError) Error() string {
+	return fmt.Sprintf("cassette: %s %s: x-sign differs from the recorded one (signed bytes changed?)", e.Method, e.Path)
+}
+
+// Interaction is one recorded request/response pair.
+type Interaction struct {
+	Request  Request  `json:"request"`
+	Response Response `json:"response"`
+}
+
+type Request struct {
+	Method string `json:"method"`
+	URL    string `json:"url"`
+	Body   string `json:"body,omitempty"`
+	XSign  string `json:"x_sign,omitempty"`
+}
+
+type Response struct {
+	StatusCode int         `json:"status_code"`
+	Header     http.Header `json:"header,omitempty"`
+	Body       string      `json:"body,omitempty"`
+}
+
+type file struct {
+	Interactions []Interaction `json:"interactions"`
+}
+
+// Option configures a Cassette.
+type Option func(*Cassette)
+
+// WithTransport sets the transport used in ModeRecord. Defaults to http.DefaultTransport.
+func WithTransport(rt http.RoundTripper) Option {
+	return func(c *Cassette) {
+		if rt != nil {
+			c.transport = rt
+		}
+	}
+}
+
+// WithoutSignatureCheck disables the x-sign comparison on replay, e.g. when the
+// cassette was recorded with a key that is not available in CI.
+func WithoutSignatureCheck() Option {
+	return func(c *Cassette) {
+		c.skipSignature = true
+	}
+}
+
+// Cassette is an http.RoundTripper that records or replays interactions.
+type Cassette struct {
+	path          string
+	mode          Mode
+	transport     http.RoundTripper
+	skipSignature bool
+
+	mu           sync.Mutex
+	interactions []Interaction
+	used         []bool
+}
+
+// New creates a cassette backed by the file at path.
+//
+// In ModeReplay the file must exist. In ModeRecord recording starts from scratch and
+// the file is written by Save.
+func New(path string, mode Mode, opts ...Option) (*Cassette, error) {
+	c := &Cassette{path: path, mode: mode, transport: http.DefaultTransport}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(c)
+		}
+	}
+
+	switch mode {
+	case ModeRecord:
+	case ModeReplay:
+		b, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("cassette: %w", err)
+		}
+		var f file
+		if err := json.Unmarshal(b, &f); err != nil {
+			return nil, fmt.Errorf("cassette: decode %s: %w", path, err)
+		}
+		c.interactions = f.Interactions
+		c.used = make([]bool, len(f.Interactions))
+	default:
+		return nil, fmt.Errorf("cassette: unknown mode %d", mode)
+	}
+	return c, nil
+}
+
+// Interactions returns a copy of the recorded or loaded interactions.
+func (c *Cassette) Interactions() []Interaction {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Interaction(nil), c.interactions...)
+}
+
+// Save writes the recorded interactions to the cassette file. It is a no-op in ModeReplay.
+func (c *Cassette) Save() error {
+	if c.mode != ModeRecord {
+		return nil
+	}
+	c.mu.Lock()
+	b, err := json.MarshalIndent(file{Interactions: c.interactions}, "", "  ")
+	c.mu.Unlock()
+	if err != nil {
+		return fmt.Errorf("cassette: encode: %w", err)
+	}
+	if dir := filepath.Dir(c.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			return fmt.Errorf("cassette: %w", err)
+		}
+	}
+	if err := os.WriteFile(c.path, append(b, '\n'), 0o644); err != nil {
+		return fmt.Errorf("cassette: %w", err)
+	}
+	return nil
+}
+
+// RoundTrip implements http.RoundTripper.
+func (c *Cassette) RoundTrip(req *http.Request) (*http.Response, error) {
+	var body []byte
+	if req.Body != nil {
+		b, err := io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("cassette: read request body: %w", err)
+		}
+		body = b
+	}
+
+	if c.mode == ModeRecord {
+		return c.record(req, body)
+	}
+	return c.replay(req, body)
+}
+
+func (c *Cassette) record(req *http.Request, body []byte) (*http.Response, error) {
+	out := req.Clone(req.Context())
+	out.Body = io.NopCloser(bytes.NewReader(body))
+	out.ContentLength = int64(len(body))
+
+	resp, err := c.transport.RoundTrip(out)
+	if err != nil {
+		return nil, err
+	}
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return nil, fmt.Errorf("cassette: read response body: %w", err)
+	}
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+
+	c.mu.Lock()
+	c.interactions = append(c.interactions, Interaction{
+		Request: Request{
+			Method: req.Method,
+			URL:    req.URL.String(),
+			Body:   string(body),
+			XSign:  req.Header.Get(consts.HeaderXSign),
+		},
+		Response: Response{
+			StatusCode: resp.StatusCode,
+			Header:     resp.Header.Clone(),
+			Body:       string(respBody),
+		},
+	})
+	c.mu.Unlock()
+	return resp, nil
+}
+
+func (c *Cassette) replay(req *http.Request, body []byte) (*http.Response, error) {
+	key := normaliseBody(body)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for i, in := range c.interactions {
+		if c.used[i] || in.Request.Method != req.Method || requestPath(in.Request.URL) != req.URL.Path {
+			continue
+		}
+		if normaliseBody([]byte(in.Request.Body)) != key {
+			continue
+		}
+		if !c.skipSignature {
+			if got := req.Header.Get(consts.HeaderXSign); got != in.Request.XSign {
+				return nil, &SignatureMismatchError{Method: req.Method, Path: req.URL.Path, Recorded: in.Request.XSign, Got: got}
+			}
+		}
+		c.used[i] = true
+
+		header := in.Response.Header.Clone()
+		if header == nil {
+			header = http.Header{}
+		}
+		return &http.Response{
+			Status:        fmt.Sprintf("%d %s", in.Response.StatusCode, http.StatusText(in.Response.StatusCode)),
+			StatusCode:    in.Response.StatusCode,
+			Proto:         "HTTP/1.1",
+			ProtoMajor:    1,
+			ProtoMinor:    1,
+			Header:        header,
+			Body:          io.NopCloser(bytes.NewReader([]byte(in.Response.Body))),
+			ContentLength: int64(len(in.Response.Body)),
+			Request:       req,
+		}, nil
+	}
+	return nil, fmt.Errorf("%w: %s %s", ErrNoInteraction, req.Method, req.URL.Path)
+}
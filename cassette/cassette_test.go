@@ -0,0 +1,89 @@
+package cassette_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"path/filepath"
+	"testing"
+
+	go_nova "github.com/stremovskyy/go-nova"
+	"github.com/stremovskyy/go-nova/acquiring"
+	"github.com/stremovskyy/go-nova/cassette"
+	"github.com/stremovskyy/go-nova/novatest"
+)
+
+func TestRecordAndReplay(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	path := filepath.Join(t.TempDir(), "session.json")
+	ctx := context.Background()
+
+	run := func(cas *cassette.Cassette, baseURL string, phone string) (string, error) {
+		client, err := go_nova.NewClient(
+			go_nova.WithPrivateKey(key),
+			go_nova.WithAcquiringBaseURL(baseURL),
+			go_nova.WithHTTPClient(&http.Client{Transport: cas}),
+			go_nova.WithLogger(nil),
+		)
+		if err != nil {
+			t.Fatalf("new client: %v", err)
+		}
+		out, err := client.Acquiring().CreateSession(ctx, &acquiring.CreateSessionRequest{MerchantID: "1", ClientPhone: phone})
+		if err != nil {
+			return "", err
+		}
+		return out.ID, nil
+	}
+
+	srv := novatest.NewServer(novatest.WithClientPublicKey(&key.PublicKey))
+	rec, err := cassette.New(path, cassette.ModeRecord)
+	if err != nil {
+		t.Fatalf("new recorder: %v", err)
+	}
+	recordedID, err := run(rec, srv.URL, "+380000000001")
+	if err != nil {
+		t.Fatalf("record: %v", err)
+	}
+	if err := rec.Save(); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	srv.Close()
+
+	replay, err := cassette.New(path, cassette.ModeReplay)
+	if err != nil {
+		t.Fatalf("load cassette: %v", err)
+	}
+	id, err := run(replay, "http://sandbox.invalid", "+380000000001")
+	if err != nil {
+		t.Fatalf("replay: %v", err)
+	}
+	if id != recordedID {
+		t.Fatalf("replayed id %q, recorded %q", id, recordedID)
+	}
+
+	replay, _ = cassette.New(path, cassette.ModeReplay)
+	if _, err := run(replay, "http://sandbox.invalid", "+380000000002"); !errors.Is(err, cassette.ErrNoInteraction) {
+		t.Fatalf("expected ErrNoInteraction for a different body, got %v", err)
+	}
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	key = otherKey
+	replay, _ = cassette.New(path, cassette.ModeReplay)
+	var mismatch *cassette.SignatureMismatchError
+	if _, err := run(replay, "http://sandbox.invalid", "+380000000001"); !errors.As(err, &mismatch) {
+		t.Fatalf("expected SignatureMismatchError, got %v", err)
+	}
+
+	replay, _ = cassette.New(path, cassette.ModeReplay, cassette.WithoutSignatureCheck())
+	if _, err := run(replay, "http://sandbox.invalid", "+380000000001"); err != nil {
+		t.Fatalf("replay without signature check: %v", err)
+	}
+}
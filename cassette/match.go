@@ -0,0 +1,36 @@
+package cassette
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/url"
+)
+
+// normaliseBody returns a canonical form of a JSON body: object keys sorted, no
+// insignificant whitespace, numbers kept verbatim. Non-JSON bodies are compared as is.
+func normaliseBody(body []byte) string {
+	if len(bytes.TrimSpace(body)) == 0 {
+		return ""
+	}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return string(body)
+	}
+	b, err := json.Marshal(v)
+	if err != nil {
+		return string(body)
+	}
+	return string(b)
+}
+
+// requestPath returns the path of a recorded URL, so cassettes recorded against
+// the sandbox replay against any base URL.
+func requestPath(raw string) string {
+	u, err := url.Parse(raw)
+	if err != nil {
+		return raw
+	}
+	return u.Path
+}
@@ -0,0 +1,67 @@
+package checkout
+
+import (
+	"encoding/json"
+
+	"github.com/stremovskyy/go-nova/acquiring"
+	"github.com/stremovskyy/go-nova/consts"
+)
+
+// Postback is the callback NovaPay sends to CreateSessionRequest.CallbackURL for a
+// checkout session. Besides the payment data it carries delivery and express-waybill details.
+type Postback struct {
+	ID           string               `json:"id"`
+	Status       consts.SessionStatus `json:"status"`
+	Paytype      string               `json:"paytype"`
+	TerminalName string               `json:"terminal_name"`
+	RRN          string               `json:"RRN"`
+	APPROVAL     int64                `json:"APPROVAL"`
+
+	CreatedAt string          `json:"created_at"`
+	Metadata  json.RawMessage `json:"metadata,omitempty"`
+
+	ClientFirstName  string  `json:"client_first_name"`
+	ClientLastName   string  `json:"client_last_name"`
+	ClientPatronymic *string `json:"client_patronymic,omitempty"`
+	ClientPhone      string  `json:"client_phone"`
+	ClientEmail      *string `json:"client_email,omitempty"`
+	ClientIP         *string `json:"client_ip,omitempty"`
+
+	ProcessingResult string            `json:"processing_result"`
+	CardDetails      *PostbackCard     `json:"card_details,omitempty"`
+	Payments         []PostbackPayment `json:"payments,omitempty"`
+
+	Delivery       *Delivery `json:"delivery,omitempty"`
+	ExpressWaybill *string   `json:"express_waybill,omitempty"`
+	RefID          *string   `json:"ref_id,omitempty"`
+
+	// Extra keeps fields not described above.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+type postback Postback
+
+func (p *Postback) UnmarshalJSON(data []byte) error {
+	var v postback
+	extra, err := unmarshalWithExtra(data, &v)
+	if err != nil {
+		return err
+	}
+	*p = Postback(v)
+	p.Extra = extra
+	return nil
+}
+
+func (p Postback) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(postback(p), p.Extra)
+}
+
+// PostbackCard is the card of an acquiring postback.
+type PostbackCard = acquiring.PostbackCard
+
+// PostbackPayment is an acquiring postback payment whose products are checkout
+// products, which may carry an image. Products hides the embedded acquiring field.
+type PostbackPayment struct {
+	acquiring.PostbackPayment
+	Products []Product `json:"products,omitempty"`
+}
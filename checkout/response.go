@@ -0,0 +1,118 @@
+package checkout
+
+import (
+	"encoding/json"
+
+	"github.com/stremovskyy/go-nova/consts"
+	"github.com/stremovskyy/go-nova/money"
+)
+
+// SessionResponse is returned by "Create checkout session".
+type SessionResponse struct {
+	ID  string `json:"id"`
+	URL string `json:"url"`
+
+	// Extra keeps fields not described above, so nothing is lost while the docs are incomplete.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+type sessionResponse SessionResponse
+
+func (r *SessionResponse) UnmarshalJSON(data []byte) error {
+	var v sessionResponse
+	extra, err := unmarshalWithExtra(data, &v)
+	if err != nil {
+		return err
+	}
+	*r = SessionResponse(v)
+	r.Extra = extra
+	return nil
+}
+
+func (r SessionResponse) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(sessionResponse(r), r.Extra)
+}
+
+// PaymentResponse is returned by "Add checkout payment".
+type PaymentResponse struct {
+	ID            string        `json:"id"`
+	URL           string        `json:"url"`
+	DeliveryPrice *money.Amount `json:"delivery_price,omitempty"`
+
+	// Extra keeps fields not described above.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+type paymentResponse PaymentResponse
+
+func (r *PaymentResponse) UnmarshalJSON(data []byte) error {
+	var v paymentResponse
+	extra, err := unmarshalWithExtra(data, &v)
+	if err != nil {
+		return err
+	}
+	*r = PaymentResponse(v)
+	r.Extra = extra
+	return nil
+}
+
+func (r PaymentResponse) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(paymentResponse(r), r.Extra)
+}
+
+// StatusResponse is returned by "Get status" for a checkout session.
+type StatusResponse struct {
+	ID        string               `json:"id"`
+	Status    consts.SessionStatus `json:"status"`
+	Paytype   string               `json:"paytype,omitempty"`
+	CreatedAt string               `json:"created_at,omitempty"`
+	Metadata  json.RawMessage      `json:"metadata,omitempty"`
+
+	ClientPhone      *string `json:"client_phone,omitempty"`
+	ClientFirstName  *string `json:"client_first_name,omitempty"`
+	ClientLastName   *string `json:"client_last_name,omitempty"`
+	ClientPatronymic *string `json:"client_patronymic,omitempty"`
+	Pan              *string `json:"pan,omitempty"`
+
+	Operations []Operation `json:"operations,omitempty"`
+
+	Delivery       *Delivery `json:"delivery,omitempty"`
+	ExpressWaybill *string   `json:"express_waybill,omitempty"`
+	RefID          *string   `json:"ref_id,omitempty"`
+
+	// Extra keeps fields not described above.
+	Extra map[string]json.RawMessage `json:"-"`
+}
+
+type statusResponse StatusResponse
+
+func (r *StatusResponse) UnmarshalJSON(data []byte) error {
+	var v statusResponse
+	extra, err := unmarshalWithExtra(data, &v)
+	if err != nil {
+		return err
+	}
+	*r = StatusResponse(v)
+	r.Extra = extra
+	return nil
+}
+
+func (r StatusResponse) MarshalJSON() ([]byte, error) {
+	return marshalWithExtra(statusResponse(r), r.Extra)
+}
+
+type Operation struct {
+	ExternalID *string      `json:"external_id,omitempty"`
+	Amount     money.Amount `json:"amount"`
+	Products   []Product    `json:"products,omitempty"`
+}
+
+// Delivery describes the Nova Poshta delivery of a checkout session, as reported by
+// "Get status" and checkout postbacks.
+type Delivery struct {
+	VolumeWeight       float64       `json:"volume_weight"`
+	Weight             float64       `json:"weight"`
+	RecipientCity      string        `json:"recipient_city,omitempty"`
+	RecipientWarehouse string        `json:"recipient_warehouse,omitempty"`
+	Price              *money.Amount `json:"price,omitempty"`
+}
@@ -0,0 +1,76 @@
+package checkout
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stremovskyy/go-nova/consts"
+)
+
+func TestStatusResponseKeepsUnknownFields(t *testing.T) {
+	body := []byte(`{"id":"s-1","status":"paid","express_waybill":"20450000000000","delivery":{"volume_weight":1,"weight":2.5,"price":65},"loyalty":{"points":10},"channel":"web"}`)
+
+	var out StatusResponse
+	if err := json.Unmarshal(body, &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if out.ID != "s-1" || out.Status != consts.SessionStatusPaid || out.ExpressWaybill == nil || *out.ExpressWaybill != "20450000000000" {
+		t.Fatalf("unexpected status response: %+v", out)
+	}
+	if out.Delivery == nil || out.Delivery.Weight != 2.5 {
+		t.Fatalf("unexpected delivery: %+v", out.Delivery)
+	}
+	if len(out.Extra) != 2 || string(out.Extra["loyalty"]) != `{"points":10}` || string(out.Extra["channel"]) != `"web"` {
+		t.Fatalf("unexpected extra fields: %v", out.Extra)
+	}
+
+	again, err := json.Marshal(out)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var roundTrip StatusResponse
+	if err := json.Unmarshal(again, &roundTrip); err != nil {
+		t.Fatalf("unmarshal round trip: %v", err)
+	}
+	if len(roundTrip.Extra) != 2 || string(roundTrip.Extra["channel"]) != `"web"` {
+		t.Fatalf("extra fields lost on round trip: %s", again)
+	}
+}
+
+func TestSessionResponseWithoutExtra(t *testing.T) {
+	var out SessionResponse
+	if err := json.Unmarshal([]byte(`{"id":"s-1","url":"https://pay.example/s-1"}`), &out); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if out.ID != "s-1" || out.URL != "https://pay.example/s-1" || out.Extra != nil {
+		t.Fatalf("unexpected session response: %+v", out)
+	}
+}
+
+func TestPostbackPaymentProducts(t *testing.T) {
+	body := []byte(`{"id":"s-1","status":"paid","card_details":{"pan":"4111****1111"},"payments":[{"external_id":"o-1","amount":250,"products":[{"description":"Book","count":1,"price":250,"image":"https://example.com/book.png"}]}]}`)
+
+	var pb Postback
+	if err := json.Unmarshal(body, &pb); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if pb.CardDetails == nil || pb.CardDetails.Pan != "4111****1111" || len(pb.Payments) != 1 {
+		t.Fatalf("unexpected postback: %+v", pb)
+	}
+	p := pb.Payments[0]
+	if p.ExternalID == nil || *p.ExternalID != "o-1" || p.Amount.String() != "250.00" {
+		t.Fatalf("unexpected payment: %+v", p)
+	}
+	if len(p.Products) != 1 || p.Products[0].Image == nil || *p.Products[0].Image != "https://example.com/book.png" {
+		t.Fatalf("checkout products must keep the image: %+v", p.Products)
+	}
+
+	again, err := json.Marshal(pb)
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	var roundTrip Postback
+	if err := json.Unmarshal(again, &roundTrip); err != nil || len(roundTrip.Payments[0].Products) != 1 || roundTrip.Payments[0].Products[0].Image == nil {
+		t.Fatalf("products lost on round trip: %s, %v", again, err)
+	}
+}
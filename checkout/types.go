@@ -1,5 +1,7 @@
 package checkout
 
+import "github.com/stremovskyy/go-nova/money"
+
 // CreateSessionRequest corresponds to "Create checkout session" (POST /v1/checkout/session).
 type CreateSessionRequest struct {
 	MerchantID           string           `json:"merchant_id"`
@@ -18,20 +20,20 @@ type SessionDelivery struct {
 
 // AddPaymentRequest corresponds to "Add checkout payment" (POST /v1/checkout/payment).
 type AddPaymentRequest struct {
-	MerchantID string    `json:"merchant_id"`
-	SessionID  string    `json:"session_id"`
-	ExternalID *string   `json:"external_id,omitempty"`
-	UseHold    *bool     `json:"use_hold,omitempty"`
-	Identifier *string   `json:"identifier,omitempty"`
-	Amount     float64   `json:"amount"`
-	Products   []Product `json:"products,omitempty"`
+	MerchantID string       `json:"merchant_id"`
+	SessionID  string       `json:"session_id"`
+	ExternalID *string      `json:"external_id,omitempty"`
+	UseHold    *bool        `json:"use_hold,omitempty"`
+	Identifier *string      `json:"identifier,omitempty"`
+	Amount     money.Amount `json:"amount"`
+	Products   []Product    `json:"products,omitempty"`
 }
 
 type Product struct {
-	Description *string `json:"description,omitempty"`
-	Count       int32   `json:"count"`
-	Price       float64 `json:"price"`
-	Image       *string `json:"image,omitempty"`
+	Description *string      `json:"description,omitempty"`
+	Count       int32        `json:"count"`
+	Price       money.Amount `json:"price"`
+	Image       *string      `json:"image,omitempty"`
 }
 
 // SessionRequest is used by checkout endpoints that require merchant_id + session_id.
@@ -41,4 +43,6 @@ type SessionRequest struct {
 }
 
 // GenericResponse is used where docs do not fully define response schema.
+//
+// Deprecated: CheckoutService returns SessionResponse, PaymentResponse and StatusResponse.
 type GenericResponse map[string]any
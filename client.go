@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/url"
 	"path"
+	"time"
 
 	"github.com/stremovskyy/go-nova/acquiring"
 	"github.com/stremovskyy/go-nova/checkout"
@@ -32,6 +33,10 @@ type Client struct {
 	acquiring *AcquiringService
 	comfort   *ComfortService
 	checkout  *CheckoutService
+
+	// merchantID is set on clients returned by ForMerchant.
+	merchantID string
+	merchants  map[string]*Client
 }
 
 func NewClient(opts ...Option) (Nova, error) {
@@ -44,20 +49,51 @@ func NewClient(opts ...Option) (Nova, error) {
 			return nil, err
 		}
 	}
+	if err := cfg.checkSigningKeys(); err != nil {
+		return nil, err
+	}
 
+	c := newClient(cfg)
+	if len(cfg.merchants) > 0 {
+		c.merchants = make(map[string]*Client, len(cfg.merchants))
+		for name, m := range cfg.merchants {
+			mc, err := newMerchantClient(cfg, name, m)
+			if err != nil {
+				return nil, err
+			}
+			c.merchants[name] = mc
+		}
+	}
+	return c, nil
+}
+
+func newClient(cfg config) *Client {
 	comfortHeaders := map[string]string{}
 	if cfg.comfortMerchantID != "" {
 		comfortHeaders[consts.HeaderXMerchantID] = cfg.comfortMerchantID
 	}
 
+	externalSigner, comfortSigner := cfg.signers()
 	c := &Client{cfg: cfg}
-	c.externalHTTP = httpclient.New(cfg.httpClient, cfg.externalSigner, cfg.logger, cfg.retryAttempts, cfg.retryWait, nil, cfg.recorder, cfg.logBodies)
-	c.comfortHTTP = httpclient.New(cfg.httpClient, cfg.comfortSigner, cfg.logger, cfg.retryAttempts, cfg.retryWait, comfortHeaders, cfg.recorder, cfg.logBodies)
+	c.externalHTTP = httpclient.New(cfg.httpClient, externalSigner, cfg.logger, 1, 0, nil, cfg.recorder, cfg.logBodies)
+	c.comfortHTTP = httpclient.New(cfg.httpClient, comfortSigner, cfg.logger, 1, 0, comfortHeaders, cfg.recorder, cfg.logBodies)
+	middleware := cfg.middleware
+	if cfg.tracer != nil {
+		middleware = append([]Middleware{tracingMiddleware(cfg.tracer)}, middleware...)
+	}
+	c.externalHTTP.Use(middleware...)
+	c.comfortHTTP.Use(middleware...)
+	c.externalHTTP.SetRetryPolicy(cfg.retryPolicy)
+	c.comfortHTTP.SetRetryPolicy(cfg.retryPolicy)
+	if cfg.metrics != nil {
+		c.externalHTTP.SetMetrics(cfg.metrics)
+		c.comfortHTTP.SetMetrics(cfg.metrics)
+	}
 
 	c.acquiring = &AcquiringService{c: c}
 	c.comfort = &ComfortService{c: c}
 	c.checkout = &CheckoutService{c: c}
-	return c, nil
+	return c
 }
 
 // NewDefaultClient is a convenience wrapper around NewClient() with default configuration.
@@ -90,7 +126,8 @@ func (c *Client) Sign(body []byte) (string, error) {
 	if c == nil || c.cfg.externalSigner == nil {
 		return "", errors.New("client is not initialized")
 	}
-	return c.cfg.externalSigner.Sign(body)
+	signer, _ := c.cfg.signers()
+	return signer.Sign(body)
 }
 
 // SignComfort signs request payload for Comfort API.
@@ -98,23 +135,52 @@ func (c *Client) SignComfort(body []byte) (string, error) {
 	if c == nil || c.cfg.comfortSigner == nil {
 		return "", errors.New("client is not initialized")
 	}
-	return c.cfg.comfortSigner.Sign(body)
+	_, signer := c.cfg.signers()
+	return signer.Sign(body)
 }
 
-// Verify verifies x-sign using configured external public key.
+// Verify verifies x-sign using the configured public key(s).
 func (c *Client) Verify(body []byte, xSign string) error {
 	if c == nil || c.cfg.externalSigner == nil {
 		return errors.New("client is not initialized")
 	}
-	return c.cfg.externalSigner.Verify(body, xSign)
+	start := time.Now()
+	err := c.cfg.externalSigner.Verify(body, xSign)
+	c.observeVerification(APIExternal, start, err)
+	return err
+}
+
+// VerifyKey verifies x-sign like Verify and returns the keyring entry that matched.
+func (c *Client) VerifyKey(body []byte, xSign string) (VerificationKey, error) {
+	if c == nil || c.cfg.externalSigner == nil {
+		return VerificationKey{}, errors.New("client is not initialized")
+	}
+	start := time.Now()
+	key, err := c.cfg.externalSigner.VerifyKey(body, xSign)
+	c.observeVerification(APIExternal, start, err)
+	return key, err
+}
+
+// VerifyComfortKey verifies x-sign like VerifyComfort and returns the keyring entry that matched.
+func (c *Client) VerifyComfortKey(body []byte, xSign string) (VerificationKey, error) {
+	if c == nil || c.cfg.comfortSigner == nil {
+		return VerificationKey{}, errors.New("client is not initialized")
+	}
+	start := time.Now()
+	key, err := c.cfg.comfortSigner.VerifyKey(body, xSign)
+	c.observeVerification(APIComfort, start, err)
+	return key, err
 }
 
-// VerifyComfort verifies x-sign using configured comfort public key.
+// VerifyComfort verifies x-sign using the configured public key(s) and the Comfort hash.
 func (c *Client) VerifyComfort(body []byte, xSign string) error {
 	if c == nil || c.cfg.comfortSigner == nil {
 		return errors.New("client is not initialized")
 	}
-	return c.cfg.comfortSigner.Verify(body, xSign)
+	start := time.Now()
+	err := c.cfg.comfortSigner.Verify(body, xSign)
+	c.observeVerification(APIComfort, start, err)
+	return err
 }
 
 func joinURL(base string, p string) (string, error) {
@@ -130,13 +196,30 @@ func wrapAPIError(err error) error {
 	if err == nil {
 		return nil
 	}
+	var ou *OutcomeUnknownError
+	if errors.As(err, &ou) {
+		return &OutcomeUnknownError{Err: wrapAPIError(ou.Err)}
+	}
 	var hs *httpclient.HTTPStatusError
 	if errors.As(err, &hs) {
-		return &APIError{StatusCode: hs.StatusCode, Body: hs.Body}
+		return newAPIError(hs.StatusCode, hs.Body)
 	}
 	return err
 }
 
+// checkKnownStatus refuses an operation the last known session status does not allow.
+// See WithKnownStatus.
+func checkKnownStatus(runOpts []RunOption, operation string, allowed func(consts.SessionStatus) bool) error {
+	opts := collectRunOptions(runOpts)
+	if opts == nil || opts.knownStatus == "" {
+		return nil
+	}
+	if !allowed(opts.knownStatus) {
+		return &SessionStatusError{Operation: operation, Status: opts.knownStatus}
+	}
+	return nil
+}
+
 func ensureComfortReady(c *Client) error {
 	if c == nil {
 		return errors.New("client is nil")
@@ -153,14 +236,31 @@ func ensureComfortReady(c *Client) error {
 
 type AcquiringService struct{ c *Client }
 
+// MerchantID returns the merchant_id the service fills into requests, empty for an
+// unscoped client.
+func (s *AcquiringService) MerchantID() string {
+	if s == nil {
+		return ""
+	}
+	return s.c.MerchantID()
+}
+
 // CreateSession creates a payment session.
-func (s *AcquiringService) CreateSession(ctx context.Context, req *acquiring.CreateSessionRequest, runOpts ...RunOption) (*acquiring.CreateSessionResponse, error) {
+func (s *AcquiringService) CreateSession(ctx context.Context, req *acquiring.CreateSessionRequest, runOpts ...RunOption) (_ *acquiring.CreateSessionResponse, err error) {
 	if s == nil || s.c == nil {
 		return nil, errors.New("client is nil")
 	}
+	ctx, span := s.c.startSpan(ctx, "AcquiringService.CreateSession")
+	defer func() { endSpan(span, err) }()
 	if req == nil {
 		return nil, &ValidationError{Fields: []FieldError{{Field: "request", Message: "is nil"}}}
 	}
+	scoped := *req
+	if err := s.c.scopeMerchantID(&scoped.MerchantID); err != nil {
+		return nil, err
+	}
+	req = &scoped
+	setSessionAttrs(span, req.MerchantID, "")
 	if err := validateCreateSession(req); err != nil {
 		return nil, err
 	}
@@ -169,25 +269,34 @@ func (s *AcquiringService) CreateSession(ctx context.Context, req *acquiring.Cre
 	if err != nil {
 		return nil, err
 	}
-	if shouldDryRun(runOpts, "POST", full, req) {
+	if s.c.shouldDryRun(runOpts, "POST", full, req) {
 		return nil, nil
 	}
 	var out acquiring.CreateSessionResponse
-	_, _, err = s.c.externalHTTP.DoJSON(ctx, "POST", full, req, &out)
+	_, _, err = s.c.externalHTTP.DoJSON(ctx, "POST", full, req, &out, s.c.requestOptions("POST", consts.AcquiringCreateSessionPath, runOpts)...)
 	if err != nil {
 		return nil, wrapAPIError(err)
 	}
+	setSessionAttrs(span, "", out.ID)
 	return &out, nil
 }
 
 // AddPayment adds order information and returns payment URL.
-func (s *AcquiringService) AddPayment(ctx context.Context, req *acquiring.AddPaymentRequest, runOpts ...RunOption) (*acquiring.AddPaymentResponse, error) {
+func (s *AcquiringService) AddPayment(ctx context.Context, req *acquiring.AddPaymentRequest, runOpts ...RunOption) (_ *acquiring.AddPaymentResponse, err error) {
 	if s == nil || s.c == nil {
 		return nil, errors.New("client is nil")
 	}
+	ctx, span := s.c.startSpan(ctx, "AcquiringService.AddPayment")
+	defer func() { endSpan(span, err) }()
 	if req == nil {
 		return nil, &ValidationError{Fields: []FieldError{{Field: "request", Message: "is nil"}}}
 	}
+	scoped := *req
+	if err := s.c.scopeMerchantID(&scoped.MerchantID); err != nil {
+		return nil, err
+	}
+	req = &scoped
+	setSessionAttrs(span, req.MerchantID, req.SessionID)
 	if err := validateAddPayment(req); err != nil {
 		return nil, err
 	}
@@ -196,11 +305,11 @@ func (s *AcquiringService) AddPayment(ctx context.Context, req *acquiring.AddPay
 	if err != nil {
 		return nil, err
 	}
-	if shouldDryRun(runOpts, "POST", full, req) {
+	if s.c.shouldDryRun(runOpts, "POST", full, req) {
 		return nil, nil
 	}
 	var out acquiring.AddPaymentResponse
-	_, _, err = s.c.externalHTTP.DoJSON(ctx, "POST", full, req, &out)
+	_, _, err = s.c.externalHTTP.DoJSON(ctx, "POST", full, req, &out, s.c.requestOptions("POST", consts.AcquiringAddPaymentPath, runOpts)...)
 	if err != nil {
 		return nil, wrapAPIError(err)
 	}
@@ -208,95 +317,139 @@ func (s *AcquiringService) AddPayment(ctx context.Context, req *acquiring.AddPay
 }
 
 // VoidSession voids or refunds blocked/charged funds.
-func (s *AcquiringService) VoidSession(ctx context.Context, req *acquiring.SessionRequest, runOpts ...RunOption) error {
+func (s *AcquiringService) VoidSession(ctx context.Context, req *acquiring.SessionRequest, runOpts ...RunOption) (err error) {
 	if s == nil || s.c == nil {
 		return errors.New("client is nil")
 	}
+	ctx, span := s.c.startSpan(ctx, "AcquiringService.VoidSession")
+	defer func() { endSpan(span, err) }()
 	if req == nil {
 		return &ValidationError{Fields: []FieldError{{Field: "request", Message: "is nil"}}}
 	}
+	scoped := *req
+	if err := s.c.scopeMerchantID(&scoped.MerchantID); err != nil {
+		return err
+	}
+	req = &scoped
+	setSessionAttrs(span, req.MerchantID, req.SessionID)
 	if err := validateSessionRequest(req); err != nil {
 		return err
 	}
+	if err := checkKnownStatus(runOpts, "void", consts.SessionStatus.CanVoid); err != nil {
+		return err
+	}
 
 	full, err := joinURL(s.c.cfg.acquiringBaseURL, consts.AcquiringVoidSessionPath)
 	if err != nil {
 		return err
 	}
-	if shouldDryRun(runOpts, "POST", full, req) {
+	if s.c.shouldDryRun(runOpts, "POST", full, req) {
 		return nil
 	}
-	_, _, err = s.c.externalHTTP.DoJSON(ctx, "POST", full, req, nil)
+	_, _, err = s.c.externalHTTP.DoJSON(ctx, "POST", full, req, nil, s.c.requestOptions("POST", consts.AcquiringVoidSessionPath, runOpts)...)
 	return wrapAPIError(err)
 }
 
 // CompleteHold confirms previously blocked funds.
-func (s *AcquiringService) CompleteHold(ctx context.Context, req *acquiring.CompleteHoldRequest, runOpts ...RunOption) error {
+func (s *AcquiringService) CompleteHold(ctx context.Context, req *acquiring.CompleteHoldRequest, runOpts ...RunOption) (err error) {
 	if s == nil || s.c == nil {
 		return errors.New("client is nil")
 	}
+	ctx, span := s.c.startSpan(ctx, "AcquiringService.CompleteHold")
+	defer func() { endSpan(span, err) }()
 	if req == nil {
 		return &ValidationError{Fields: []FieldError{{Field: "request", Message: "is nil"}}}
 	}
+	scoped := *req
+	if err := s.c.scopeMerchantID(&scoped.MerchantID); err != nil {
+		return err
+	}
+	req = &scoped
+	setSessionAttrs(span, req.MerchantID, req.SessionID)
 	if err := validateCompleteHold(req); err != nil {
 		return err
 	}
+	if err := checkKnownStatus(runOpts, "complete hold of", consts.SessionStatus.CanCompleteHold); err != nil {
+		return err
+	}
 
 	full, err := joinURL(s.c.cfg.acquiringBaseURL, consts.AcquiringCompleteHoldPath)
 	if err != nil {
 		return err
 	}
-	if shouldDryRun(runOpts, "POST", full, req) {
+	if s.c.shouldDryRun(runOpts, "POST", full, req) {
 		return nil
 	}
-	_, _, err = s.c.externalHTTP.DoJSON(ctx, "POST", full, req, nil)
+	_, _, err = s.c.externalHTTP.DoJSON(ctx, "POST", full, req, nil, s.c.requestOptions("POST", consts.AcquiringCompleteHoldPath, runOpts)...)
 	return wrapAPIError(err)
 }
 
 // ExpireSession force-expires a payment session.
-func (s *AcquiringService) ExpireSession(ctx context.Context, req *acquiring.SessionRequest, runOpts ...RunOption) error {
+func (s *AcquiringService) ExpireSession(ctx context.Context, req *acquiring.SessionRequest, runOpts ...RunOption) (err error) {
 	if s == nil || s.c == nil {
 		return errors.New("client is nil")
 	}
+	ctx, span := s.c.startSpan(ctx, "AcquiringService.ExpireSession")
+	defer func() { endSpan(span, err) }()
 	if req == nil {
 		return &ValidationError{Fields: []FieldError{{Field: "request", Message: "is nil"}}}
 	}
+	scoped := *req
+	if err := s.c.scopeMerchantID(&scoped.MerchantID); err != nil {
+		return err
+	}
+	req = &scoped
+	setSessionAttrs(span, req.MerchantID, req.SessionID)
 	if err := validateSessionRequest(req); err != nil {
 		return err
 	}
+	if err := checkKnownStatus(runOpts, "expire", consts.SessionStatus.CanExpire); err != nil {
+		return err
+	}
 
 	full, err := joinURL(s.c.cfg.acquiringBaseURL, consts.AcquiringExpireSessionPath)
 	if err != nil {
 		return err
 	}
-	if shouldDryRun(runOpts, "POST", full, req) {
+	if s.c.shouldDryRun(runOpts, "POST", full, req) {
 		return nil
 	}
-	_, _, err = s.c.externalHTTP.DoJSON(ctx, "POST", full, req, nil)
+	_, _, err = s.c.externalHTTP.DoJSON(ctx, "POST", full, req, nil, s.c.requestOptions("POST", consts.AcquiringExpireSessionPath, runOpts)...)
 	return wrapAPIError(err)
 }
 
 // ConfirmDeliveryHold confirms protected payment based on delivery status.
-func (s *AcquiringService) ConfirmDeliveryHold(ctx context.Context, req *acquiring.SessionRequest, runOpts ...RunOption) (*acquiring.ConfirmDeliveryHoldResponse, error) {
+func (s *AcquiringService) ConfirmDeliveryHold(ctx context.Context, req *acquiring.SessionRequest, runOpts ...RunOption) (_ *acquiring.ConfirmDeliveryHoldResponse, err error) {
 	if s == nil || s.c == nil {
 		return nil, errors.New("client is nil")
 	}
+	ctx, span := s.c.startSpan(ctx, "AcquiringService.ConfirmDeliveryHold")
+	defer func() { endSpan(span, err) }()
 	if req == nil {
 		return nil, &ValidationError{Fields: []FieldError{{Field: "request", Message: "is nil"}}}
 	}
+	scoped := *req
+	if err := s.c.scopeMerchantID(&scoped.MerchantID); err != nil {
+		return nil, err
+	}
+	req = &scoped
+	setSessionAttrs(span, req.MerchantID, req.SessionID)
 	if err := validateSessionRequest(req); err != nil {
 		return nil, err
 	}
+	if err := checkKnownStatus(runOpts, "confirm delivery hold of", consts.SessionStatus.CanCompleteHold); err != nil {
+		return nil, err
+	}
 
 	full, err := joinURL(s.c.cfg.acquiringBaseURL, consts.AcquiringConfirmDeliveryPath)
 	if err != nil {
 		return nil, err
 	}
-	if shouldDryRun(runOpts, "POST", full, req) {
+	if s.c.shouldDryRun(runOpts, "POST", full, req) {
 		return nil, nil
 	}
 	var out acquiring.ConfirmDeliveryHoldResponse
-	_, _, err = s.c.externalHTTP.DoJSON(ctx, "POST", full, req, &out)
+	_, _, err = s.c.externalHTTP.DoJSON(ctx, "POST", full, req, &out, s.c.requestOptions("POST", consts.AcquiringConfirmDeliveryPath, runOpts)...)
 	if err != nil {
 		return nil, wrapAPIError(err)
 	}
@@ -304,13 +457,21 @@ func (s *AcquiringService) ConfirmDeliveryHold(ctx context.Context, req *acquiri
 }
 
 // PrintExpressWaybill returns express waybill file stream.
-func (s *AcquiringService) PrintExpressWaybill(ctx context.Context, req *acquiring.SessionRequest, runOpts ...RunOption) ([]byte, error) {
+func (s *AcquiringService) PrintExpressWaybill(ctx context.Context, req *acquiring.SessionRequest, runOpts ...RunOption) (_ []byte, err error) {
 	if s == nil || s.c == nil {
 		return nil, errors.New("client is nil")
 	}
+	ctx, span := s.c.startSpan(ctx, "AcquiringService.PrintExpressWaybill")
+	defer func() { endSpan(span, err) }()
 	if req == nil {
 		return nil, &ValidationError{Fields: []FieldError{{Field: "request", Message: "is nil"}}}
 	}
+	scoped := *req
+	if err := s.c.scopeMerchantID(&scoped.MerchantID); err != nil {
+		return nil, err
+	}
+	req = &scoped
+	setSessionAttrs(span, req.MerchantID, req.SessionID)
 	if err := validateSessionRequest(req); err != nil {
 		return nil, err
 	}
@@ -319,10 +480,10 @@ func (s *AcquiringService) PrintExpressWaybill(ctx context.Context, req *acquiri
 	if err != nil {
 		return nil, err
 	}
-	if shouldDryRun(runOpts, "POST", full, req) {
+	if s.c.shouldDryRun(runOpts, "POST", full, req) {
 		return nil, nil
 	}
-	_, raw, err := s.c.externalHTTP.DoJSON(ctx, "POST", full, req, nil)
+	_, raw, err := s.c.externalHTTP.DoJSON(ctx, "POST", full, req, nil, s.c.requestOptions("POST", consts.AcquiringPrintExpressWaybillPath, runOpts)...)
 	if err != nil {
 		return nil, wrapAPIError(err)
 	}
@@ -330,13 +491,21 @@ func (s *AcquiringService) PrintExpressWaybill(ctx context.Context, req *acquiri
 }
 
 // GetStatus returns current session status/details.
-func (s *AcquiringService) GetStatus(ctx context.Context, req *acquiring.SessionRequest, runOpts ...RunOption) (*acquiring.GetStatusResponse, error) {
+func (s *AcquiringService) GetStatus(ctx context.Context, req *acquiring.SessionRequest, runOpts ...RunOption) (_ *acquiring.GetStatusResponse, err error) {
 	if s == nil || s.c == nil {
 		return nil, errors.New("client is nil")
 	}
+	ctx, span := s.c.startSpan(ctx, "AcquiringService.GetStatus")
+	defer func() { endSpan(span, err) }()
 	if req == nil {
 		return nil, &ValidationError{Fields: []FieldError{{Field: "request", Message: "is nil"}}}
 	}
+	scoped := *req
+	if err := s.c.scopeMerchantID(&scoped.MerchantID); err != nil {
+		return nil, err
+	}
+	req = &scoped
+	setSessionAttrs(span, req.MerchantID, req.SessionID)
 	if err := validateSessionRequest(req); err != nil {
 		return nil, err
 	}
@@ -345,11 +514,11 @@ func (s *AcquiringService) GetStatus(ctx context.Context, req *acquiring.Session
 	if err != nil {
 		return nil, err
 	}
-	if shouldDryRun(runOpts, "POST", full, req) {
+	if s.c.shouldDryRun(runOpts, "POST", full, req) {
 		return nil, nil
 	}
 	var out acquiring.GetStatusResponse
-	_, _, err = s.c.externalHTTP.DoJSON(ctx, "POST", full, req, &out)
+	_, _, err = s.c.externalHTTP.DoJSON(ctx, "POST", full, req, &out, s.c.requestOptions("POST", consts.AcquiringGetStatusPath, runOpts)...)
 	if err != nil {
 		return nil, wrapAPIError(err)
 	}
@@ -357,13 +526,21 @@ func (s *AcquiringService) GetStatus(ctx context.Context, req *acquiring.Session
 }
 
 // DeliveryPrice calculates delivery price.
-func (s *AcquiringService) DeliveryPrice(ctx context.Context, req *acquiring.DeliveryPriceRequest, runOpts ...RunOption) (acquiring.DeliveryPriceResponse, error) {
+func (s *AcquiringService) DeliveryPrice(ctx context.Context, req *acquiring.DeliveryPriceRequest, runOpts ...RunOption) (_ acquiring.DeliveryPriceResponse, err error) {
 	if s == nil || s.c == nil {
 		return nil, errors.New("client is nil")
 	}
+	ctx, span := s.c.startSpan(ctx, "AcquiringService.DeliveryPrice")
+	defer func() { endSpan(span, err) }()
 	if req == nil {
 		return nil, &ValidationError{Fields: []FieldError{{Field: "request", Message: "is nil"}}}
 	}
+	scoped := *req
+	if err := s.c.scopeMerchantID(&scoped.MerchantID); err != nil {
+		return nil, err
+	}
+	req = &scoped
+	setSessionAttrs(span, req.MerchantID, "")
 	if err := validateDeliveryPrice(req); err != nil {
 		return nil, err
 	}
@@ -372,11 +549,11 @@ func (s *AcquiringService) DeliveryPrice(ctx context.Context, req *acquiring.Del
 	if err != nil {
 		return nil, err
 	}
-	if shouldDryRun(runOpts, "POST", full, req) {
+	if s.c.shouldDryRun(runOpts, "POST", full, req) {
 		return nil, nil
 	}
 	var out acquiring.DeliveryPriceResponse
-	_, _, err = s.c.externalHTTP.DoJSON(ctx, "POST", full, req, &out)
+	_, _, err = s.c.externalHTTP.DoJSON(ctx, "POST", full, req, &out, s.c.requestOptions("POST", consts.AcquiringDeliveryPricePath, runOpts)...)
 	if err != nil {
 		return nil, wrapAPIError(err)
 	}
@@ -384,18 +561,20 @@ func (s *AcquiringService) DeliveryPrice(ctx context.Context, req *acquiring.Del
 }
 
 // Do performs a signed request against Acquiring base URL.
-func (s *AcquiringService) Do(ctx context.Context, method string, endpointPath string, body any, out any, runOpts ...RunOption) error {
+func (s *AcquiringService) Do(ctx context.Context, method string, endpointPath string, body any, out any, runOpts ...RunOption) (err error) {
 	if s == nil || s.c == nil {
 		return errors.New("client is nil")
 	}
+	ctx, span := s.c.startSpan(ctx, "AcquiringService.Do")
+	defer func() { endSpan(span, err) }()
 	full, err := joinURL(s.c.cfg.acquiringBaseURL, endpointPath)
 	if err != nil {
 		return err
 	}
-	if shouldDryRun(runOpts, method, full, body) {
+	if s.c.shouldDryRun(runOpts, method, full, body) {
 		return nil
 	}
-	_, _, err = s.c.externalHTTP.DoJSON(ctx, method, full, body, out)
+	_, _, err = s.c.externalHTTP.DoJSON(ctx, method, full, body, out, s.c.requestOptions(method, endpointPath, runOpts)...)
 	return wrapAPIError(err)
 }
 
@@ -406,37 +585,47 @@ func (s *AcquiringService) Do(ctx context.Context, method string, endpointPath s
 type ComfortService struct{ c *Client }
 
 // CreateOperations sends payout instructions.
-func (s *ComfortService) CreateOperations(ctx context.Context, req comfort.CreateOperationsRequest, runOpts ...RunOption) ([]comfort.CreateOperationsResponseItem, error) {
+//
+// Every item without GUID gets one (see WithIdempotencyKey), so the returned and
+// reconciled operations can always be matched to the request. The create is never
+// retried blindly; see WithPayoutReconcile.
+func (s *ComfortService) CreateOperations(ctx context.Context, req comfort.CreateOperationsRequest, runOpts ...RunOption) (_ []comfort.CreateOperationsResponseItem, err error) {
 	if s == nil || s.c == nil {
 		return nil, errors.New("client is nil")
 	}
+	ctx, span := s.c.startSpan(ctx, "ComfortService.CreateOperations")
+	defer func() { endSpan(span, err) }()
+	setSessionAttrs(span, s.c.cfg.comfortMerchantID, "")
 	if err := ensureComfortReady(s.c); err != nil {
 		return nil, err
 	}
 	if err := validateComfortCreateOperations(req); err != nil {
 		return nil, err
 	}
+	opts := collectRunOptions(runOpts)
+	if opts == nil {
+		opts = &runOptions{}
+	}
+	req = withPayoutGUIDs(req, opts.idempotencyKey)
 
 	full, err := joinURL(s.c.cfg.comfortBaseURL, consts.ComfortCreateOperationsPath)
 	if err != nil {
 		return nil, err
 	}
-	if shouldDryRun(runOpts, "POST", full, req) {
+	if s.c.shouldDryRun(runOpts, "POST", full, req) {
 		return nil, nil
 	}
-	var out []comfort.CreateOperationsResponseItem
-	_, _, err = s.c.comfortHTTP.DoJSON(ctx, "POST", full, req, &out)
-	if err != nil {
-		return nil, wrapAPIError(err)
-	}
-	return out, nil
+	return s.createOperations(ctx, full, req, opts)
 }
 
 // RefundOperations requests operation refund by public IDs.
-func (s *ComfortService) RefundOperations(ctx context.Context, req *comfort.RefundOperationsRequest, runOpts ...RunOption) ([]string, error) {
+func (s *ComfortService) RefundOperations(ctx context.Context, req *comfort.RefundOperationsRequest, runOpts ...RunOption) (_ []string, err error) {
 	if s == nil || s.c == nil {
 		return nil, errors.New("client is nil")
 	}
+	ctx, span := s.c.startSpan(ctx, "ComfortService.RefundOperations")
+	defer func() { endSpan(span, err) }()
+	setSessionAttrs(span, s.c.cfg.comfortMerchantID, "")
 	if err := ensureComfortReady(s.c); err != nil {
 		return nil, err
 	}
@@ -448,11 +637,11 @@ func (s *ComfortService) RefundOperations(ctx context.Context, req *comfort.Refu
 	if err != nil {
 		return nil, err
 	}
-	if shouldDryRun(runOpts, "POST", full, req) {
+	if s.c.shouldDryRun(runOpts, "POST", full, req) {
 		return nil, nil
 	}
 	var out []string
-	_, _, err = s.c.comfortHTTP.DoJSON(ctx, "POST", full, req, &out)
+	_, _, err = s.c.comfortHTTP.DoJSON(ctx, "POST", full, req, &out, s.c.requestOptions("POST", consts.ComfortRefundOperationsPath, runOpts)...)
 	if err != nil {
 		return nil, wrapAPIError(err)
 	}
@@ -460,10 +649,13 @@ func (s *ComfortService) RefundOperations(ctx context.Context, req *comfort.Refu
 }
 
 // OperationsStatus checks status by operation GUID.
-func (s *ComfortService) OperationsStatus(ctx context.Context, req *comfort.OperationsStatusRequest, runOpts ...RunOption) (*comfort.OperationsStatusResponse, error) {
+func (s *ComfortService) OperationsStatus(ctx context.Context, req *comfort.OperationsStatusRequest, runOpts ...RunOption) (_ *comfort.OperationsStatusResponse, err error) {
 	if s == nil || s.c == nil {
 		return nil, errors.New("client is nil")
 	}
+	ctx, span := s.c.startSpan(ctx, "ComfortService.OperationsStatus")
+	defer func() { endSpan(span, err) }()
+	setSessionAttrs(span, s.c.cfg.comfortMerchantID, "")
 	if err := ensureComfortReady(s.c); err != nil {
 		return nil, err
 	}
@@ -475,11 +667,11 @@ func (s *ComfortService) OperationsStatus(ctx context.Context, req *comfort.Oper
 	if err != nil {
 		return nil, err
 	}
-	if shouldDryRun(runOpts, "POST", full, req) {
+	if s.c.shouldDryRun(runOpts, "POST", full, req) {
 		return nil, nil
 	}
 	var out comfort.OperationsStatusResponse
-	_, _, err = s.c.comfortHTTP.DoJSON(ctx, "POST", full, req, &out)
+	_, _, err = s.c.comfortHTTP.DoJSON(ctx, "POST", full, req, &out, s.c.requestOptions("POST", consts.ComfortOperationsStatusPath, runOpts)...)
 	if err != nil {
 		return nil, wrapAPIError(err)
 	}
@@ -487,10 +679,13 @@ func (s *ComfortService) OperationsStatus(ctx context.Context, req *comfort.Oper
 }
 
 // ChangeRecipientData updates recipient data for operation.
-func (s *ComfortService) ChangeRecipientData(ctx context.Context, req *comfort.ChangeRecipientDataRequest, runOpts ...RunOption) error {
+func (s *ComfortService) ChangeRecipientData(ctx context.Context, req *comfort.ChangeRecipientDataRequest, runOpts ...RunOption) (err error) {
 	if s == nil || s.c == nil {
 		return errors.New("client is nil")
 	}
+	ctx, span := s.c.startSpan(ctx, "ComfortService.ChangeRecipientData")
+	defer func() { endSpan(span, err) }()
+	setSessionAttrs(span, s.c.cfg.comfortMerchantID, "")
 	if err := ensureComfortReady(s.c); err != nil {
 		return err
 	}
@@ -502,18 +697,21 @@ func (s *ComfortService) ChangeRecipientData(ctx context.Context, req *comfort.C
 	if err != nil {
 		return err
 	}
-	if shouldDryRun(runOpts, "POST", full, req) {
+	if s.c.shouldDryRun(runOpts, "POST", full, req) {
 		return nil
 	}
-	_, _, err = s.c.comfortHTTP.DoJSON(ctx, "POST", full, req, nil)
+	_, _, err = s.c.comfortHTTP.DoJSON(ctx, "POST", full, req, nil, s.c.requestOptions("POST", consts.ComfortChangeRecipientDataPath, runOpts)...)
 	return wrapAPIError(err)
 }
 
 // Balance queries current comfort API balance.
-func (s *ComfortService) Balance(ctx context.Context, runOpts ...RunOption) (*comfort.BalanceResponse, error) {
+func (s *ComfortService) Balance(ctx context.Context, runOpts ...RunOption) (_ *comfort.BalanceResponse, err error) {
 	if s == nil || s.c == nil {
 		return nil, errors.New("client is nil")
 	}
+	ctx, span := s.c.startSpan(ctx, "ComfortService.Balance")
+	defer func() { endSpan(span, err) }()
+	setSessionAttrs(span, s.c.cfg.comfortMerchantID, "")
 	if err := ensureComfortReady(s.c); err != nil {
 		return nil, err
 	}
@@ -522,11 +720,11 @@ func (s *ComfortService) Balance(ctx context.Context, runOpts ...RunOption) (*co
 	if err != nil {
 		return nil, err
 	}
-	if shouldDryRun(runOpts, "GET", full, nil) {
+	if s.c.shouldDryRun(runOpts, "GET", full, nil) {
 		return nil, nil
 	}
 	var out comfort.BalanceResponse
-	_, _, err = s.c.comfortHTTP.DoJSON(ctx, "GET", full, nil, &out)
+	_, _, err = s.c.comfortHTTP.DoJSON(ctx, "GET", full, nil, &out, s.c.requestOptions("GET", consts.ComfortBalancePath, runOpts)...)
 	if err != nil {
 		return nil, wrapAPIError(err)
 	}
@@ -534,10 +732,13 @@ func (s *ComfortService) Balance(ctx context.Context, runOpts ...RunOption) (*co
 }
 
 // ExportOperations requests operations export file generation.
-func (s *ComfortService) ExportOperations(ctx context.Context, req *comfort.ExportOperationsRequest, runOpts ...RunOption) (*comfort.ExportOperationsResponse, error) {
+func (s *ComfortService) ExportOperations(ctx context.Context, req *comfort.ExportOperationsRequest, runOpts ...RunOption) (_ *comfort.ExportOperationsResponse, err error) {
 	if s == nil || s.c == nil {
 		return nil, errors.New("client is nil")
 	}
+	ctx, span := s.c.startSpan(ctx, "ComfortService.ExportOperations")
+	defer func() { endSpan(span, err) }()
+	setSessionAttrs(span, s.c.cfg.comfortMerchantID, "")
 	if err := ensureComfortReady(s.c); err != nil {
 		return nil, err
 	}
@@ -549,11 +750,11 @@ func (s *ComfortService) ExportOperations(ctx context.Context, req *comfort.Expo
 	if err != nil {
 		return nil, err
 	}
-	if shouldDryRun(runOpts, "POST", full, req) {
+	if s.c.shouldDryRun(runOpts, "POST", full, req) {
 		return nil, nil
 	}
 	var out comfort.ExportOperationsResponse
-	_, _, err = s.c.comfortHTTP.DoJSON(ctx, "POST", full, req, &out)
+	_, _, err = s.c.comfortHTTP.DoJSON(ctx, "POST", full, req, &out, s.c.requestOptions("POST", consts.ComfortExportOperationsPath, runOpts)...)
 	if err != nil {
 		return nil, wrapAPIError(err)
 	}
@@ -561,10 +762,13 @@ func (s *ComfortService) ExportOperations(ctx context.Context, req *comfort.Expo
 }
 
 // Do performs a signed request against the Comfort base URL.
-func (s *ComfortService) Do(ctx context.Context, method string, endpointPath string, body any, out any, runOpts ...RunOption) error {
+func (s *ComfortService) Do(ctx context.Context, method string, endpointPath string, body any, out any, runOpts ...RunOption) (err error) {
 	if s == nil || s.c == nil {
 		return errors.New("client is nil")
 	}
+	ctx, span := s.c.startSpan(ctx, "ComfortService.Do")
+	defer func() { endSpan(span, err) }()
+	setSessionAttrs(span, s.c.cfg.comfortMerchantID, "")
 	if err := ensureComfortReady(s.c); err != nil {
 		return err
 	}
@@ -572,10 +776,10 @@ func (s *ComfortService) Do(ctx context.Context, method string, endpointPath str
 	if err != nil {
 		return err
 	}
-	if shouldDryRun(runOpts, method, full, body) {
+	if s.c.shouldDryRun(runOpts, method, full, body) {
 		return nil
 	}
-	_, _, err = s.c.comfortHTTP.DoJSON(ctx, method, full, body, out)
+	_, _, err = s.c.comfortHTTP.DoJSON(ctx, method, full, body, out, s.c.requestOptions(method, endpointPath, runOpts)...)
 	return wrapAPIError(err)
 }
 
@@ -586,13 +790,21 @@ func (s *ComfortService) Do(ctx context.Context, method string, endpointPath str
 type CheckoutService struct{ c *Client }
 
 // CreateSession creates checkout session.
-func (s *CheckoutService) CreateSession(ctx context.Context, req *checkout.CreateSessionRequest, runOpts ...RunOption) (checkout.GenericResponse, error) {
+func (s *CheckoutService) CreateSession(ctx context.Context, req *checkout.CreateSessionRequest, runOpts ...RunOption) (_ *checkout.SessionResponse, err error) {
 	if s == nil || s.c == nil {
 		return nil, errors.New("client is nil")
 	}
+	ctx, span := s.c.startSpan(ctx, "CheckoutService.CreateSession")
+	defer func() { endSpan(span, err) }()
 	if req == nil {
 		return nil, &ValidationError{Fields: []FieldError{{Field: "request", Message: "is nil"}}}
 	}
+	scoped := *req
+	if err := s.c.scopeMerchantID(&scoped.MerchantID); err != nil {
+		return nil, err
+	}
+	req = &scoped
+	setSessionAttrs(span, req.MerchantID, "")
 	if err := validateCheckoutCreateSession(req); err != nil {
 		return nil, err
 	}
@@ -601,25 +813,34 @@ func (s *CheckoutService) CreateSession(ctx context.Context, req *checkout.Creat
 	if err != nil {
 		return nil, err
 	}
-	if shouldDryRun(runOpts, "POST", full, req) {
+	if s.c.shouldDryRun(runOpts, "POST", full, req) {
 		return nil, nil
 	}
-	var out checkout.GenericResponse
-	_, _, err = s.c.externalHTTP.DoJSON(ctx, "POST", full, req, &out)
+	var out checkout.SessionResponse
+	_, _, err = s.c.externalHTTP.DoJSON(ctx, "POST", full, req, &out, s.c.requestOptions("POST", consts.CheckoutCreateSessionPath, runOpts)...)
 	if err != nil {
 		return nil, wrapAPIError(err)
 	}
-	return out, nil
+	setSessionAttrs(span, "", out.ID)
+	return &out, nil
 }
 
 // AddPayment adds products into checkout session.
-func (s *CheckoutService) AddPayment(ctx context.Context, req *checkout.AddPaymentRequest, runOpts ...RunOption) (checkout.GenericResponse, error) {
+func (s *CheckoutService) AddPayment(ctx context.Context, req *checkout.AddPaymentRequest, runOpts ...RunOption) (_ *checkout.PaymentResponse, err error) {
 	if s == nil || s.c == nil {
 		return nil, errors.New("client is nil")
 	}
+	ctx, span := s.c.startSpan(ctx, "CheckoutService.AddPayment")
+	defer func() { endSpan(span, err) }()
 	if req == nil {
 		return nil, &ValidationError{Fields: []FieldError{{Field: "request", Message: "is nil"}}}
 	}
+	scoped := *req
+	if err := s.c.scopeMerchantID(&scoped.MerchantID); err != nil {
+		return nil, err
+	}
+	req = &scoped
+	setSessionAttrs(span, req.MerchantID, req.SessionID)
 	if err := validateCheckoutAddPayment(req); err != nil {
 		return nil, err
 	}
@@ -628,48 +849,67 @@ func (s *CheckoutService) AddPayment(ctx context.Context, req *checkout.AddPayme
 	if err != nil {
 		return nil, err
 	}
-	if shouldDryRun(runOpts, "POST", full, req) {
+	if s.c.shouldDryRun(runOpts, "POST", full, req) {
 		return nil, nil
 	}
-	var out checkout.GenericResponse
-	_, _, err = s.c.externalHTTP.DoJSON(ctx, "POST", full, req, &out)
+	var out checkout.PaymentResponse
+	_, _, err = s.c.externalHTTP.DoJSON(ctx, "POST", full, req, &out, s.c.requestOptions("POST", consts.CheckoutAddPaymentPath, runOpts)...)
 	if err != nil {
 		return nil, wrapAPIError(err)
 	}
-	return out, nil
+	return &out, nil
 }
 
 // VoidSession voids checkout session.
-func (s *CheckoutService) VoidSession(ctx context.Context, req *checkout.SessionRequest, runOpts ...RunOption) error {
+func (s *CheckoutService) VoidSession(ctx context.Context, req *checkout.SessionRequest, runOpts ...RunOption) (err error) {
 	if s == nil || s.c == nil {
 		return errors.New("client is nil")
 	}
+	ctx, span := s.c.startSpan(ctx, "CheckoutService.VoidSession")
+	defer func() { endSpan(span, err) }()
 	if req == nil {
 		return &ValidationError{Fields: []FieldError{{Field: "request", Message: "is nil"}}}
 	}
+	scoped := *req
+	if err := s.c.scopeMerchantID(&scoped.MerchantID); err != nil {
+		return err
+	}
+	req = &scoped
+	setSessionAttrs(span, req.MerchantID, req.SessionID)
 	if err := validateCheckoutSessionRequest(req); err != nil {
 		return err
 	}
+	if err := checkKnownStatus(runOpts, "void", consts.SessionStatus.CanVoid); err != nil {
+		return err
+	}
 
 	full, err := joinURL(s.c.cfg.checkoutBaseURL, consts.CheckoutVoidSessionPath)
 	if err != nil {
 		return err
 	}
-	if shouldDryRun(runOpts, "POST", full, req) {
+	if s.c.shouldDryRun(runOpts, "POST", full, req) {
 		return nil
 	}
-	_, _, err = s.c.externalHTTP.DoJSON(ctx, "POST", full, req, nil)
+	_, _, err = s.c.externalHTTP.DoJSON(ctx, "POST", full, req, nil, s.c.requestOptions("POST", consts.CheckoutVoidSessionPath, runOpts)...)
 	return wrapAPIError(err)
 }
 
 // GetStatus returns checkout session status.
-func (s *CheckoutService) GetStatus(ctx context.Context, req *checkout.SessionRequest, runOpts ...RunOption) (checkout.GenericResponse, error) {
+func (s *CheckoutService) GetStatus(ctx context.Context, req *checkout.SessionRequest, runOpts ...RunOption) (_ *checkout.StatusResponse, err error) {
 	if s == nil || s.c == nil {
 		return nil, errors.New("client is nil")
 	}
+	ctx, span := s.c.startSpan(ctx, "CheckoutService.GetStatus")
+	defer func() { endSpan(span, err) }()
 	if req == nil {
 		return nil, &ValidationError{Fields: []FieldError{{Field: "request", Message: "is nil"}}}
 	}
+	scoped := *req
+	if err := s.c.scopeMerchantID(&scoped.MerchantID); err != nil {
+		return nil, err
+	}
+	req = &scoped
+	setSessionAttrs(span, req.MerchantID, req.SessionID)
 	if err := validateCheckoutSessionRequest(req); err != nil {
 		return nil, err
 	}
@@ -678,53 +918,66 @@ func (s *CheckoutService) GetStatus(ctx context.Context, req *checkout.SessionRe
 	if err != nil {
 		return nil, err
 	}
-	if shouldDryRun(runOpts, "POST", full, req) {
+	if s.c.shouldDryRun(runOpts, "POST", full, req) {
 		return nil, nil
 	}
-	var out checkout.GenericResponse
-	_, _, err = s.c.externalHTTP.DoJSON(ctx, "POST", full, req, &out)
+	var out checkout.StatusResponse
+	_, _, err = s.c.externalHTTP.DoJSON(ctx, "POST", full, req, &out, s.c.requestOptions("POST", consts.CheckoutGetStatusPath, runOpts)...)
 	if err != nil {
 		return nil, wrapAPIError(err)
 	}
-	return out, nil
+	return &out, nil
 }
 
 // ExpireSession force-expires checkout session.
-func (s *CheckoutService) ExpireSession(ctx context.Context, req *checkout.SessionRequest, runOpts ...RunOption) error {
+func (s *CheckoutService) ExpireSession(ctx context.Context, req *checkout.SessionRequest, runOpts ...RunOption) (err error) {
 	if s == nil || s.c == nil {
 		return errors.New("client is nil")
 	}
+	ctx, span := s.c.startSpan(ctx, "CheckoutService.ExpireSession")
+	defer func() { endSpan(span, err) }()
 	if req == nil {
 		return &ValidationError{Fields: []FieldError{{Field: "request", Message: "is nil"}}}
 	}
+	scoped := *req
+	if err := s.c.scopeMerchantID(&scoped.MerchantID); err != nil {
+		return err
+	}
+	req = &scoped
+	setSessionAttrs(span, req.MerchantID, req.SessionID)
 	if err := validateCheckoutSessionRequest(req); err != nil {
 		return err
 	}
+	if err := checkKnownStatus(runOpts, "expire", consts.SessionStatus.CanExpire); err != nil {
+		return err
+	}
 
 	full, err := joinURL(s.c.cfg.checkoutBaseURL, consts.CheckoutExpireSessionPath)
 	if err != nil {
 		return err
 	}
-	if shouldDryRun(runOpts, "POST", full, req) {
+	if s.c.shouldDryRun(runOpts, "POST", full, req) {
 		return nil
 	}
-	_, _, err = s.c.externalHTTP.DoJSON(ctx, "POST", full, req, nil)
+	_, _, err = s.c.externalHTTP.DoJSON(ctx, "POST", full, req, nil, s.c.requestOptions("POST", consts.CheckoutExpireSessionPath, runOpts)...)
 	return wrapAPIError(err)
 }
 
 // Do performs a signed request against Checkout base URL.
-func (s *CheckoutService) Do(ctx context.Context, method string, path string, body any, out any, runOpts ...RunOption) error {
+func (s *CheckoutService) Do(ctx context.Context, method string, path string, body any, out any, runOpts ...RunOption) (err error) {
 	if s == nil || s.c == nil {
 		return errors.New("client is nil")
 	}
+	ctx, span := s.c.startSpan(ctx, "CheckoutService.Do")
+	defer func() { endSpan(span, err) }()
 	full, err := joinURL(s.c.cfg.checkoutBaseURL, path)
 	if err != nil {
 		return err
 	}
-	if shouldDryRun(runOpts, method, full, body) {
+	if s.c.shouldDryRun(runOpts, method, full, body) {
 		return nil
 	}
-	_, _, err = s.c.externalHTTP.DoJSON(ctx, method, full, body, out)
+	_, _, err = s.c.externalHTTP.DoJSON(ctx, method, full, body, out, s.c.requestOptions(method, path, runOpts)...)
 	return wrapAPIError(err)
 }
 
@@ -935,8 +1188,8 @@ func validateComfortCreateOperations(req comfort.CreateOperationsRequest) error
 		return ve
 	}
 	for i, op := range req.RawBody {
-		if op.Amount == "" {
-			ve.Add(fmt.Sprintf("RAW_BODY[%d].amount", i), "is required")
+		if !op.Amount.IsPositive() {
+			ve.Add(fmt.Sprintf("RAW_BODY[%d].amount", i), "must be > 0")
 		}
 		if op.Recipient != nil {
 			r := op.Recipient
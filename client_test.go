@@ -5,6 +5,7 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"encoding/json"
+	"errors"
 	"io"
 	"net/http"
 	"net/http/httptest"
@@ -13,8 +14,10 @@ import (
 
 	"github.com/stremovskyy/go-nova/acquiring"
 	"github.com/stremovskyy/go-nova/comfort"
+	"github.com/stremovskyy/go-nova/consts"
 	"github.com/stremovskyy/go-nova/internal/signature"
 	sdklog "github.com/stremovskyy/go-nova/log"
+	"github.com/stremovskyy/go-nova/money"
 	"github.com/stremovskyy/recorder"
 )
 
@@ -96,7 +99,7 @@ func TestExternalAndComfortSigningAndHeaders(t *testing.T) {
 
 	ops, err := client.Comfort().CreateOperations(context.Background(), comfort.CreateOperationsRequest{
 		RawBody: []comfort.CreateOperationItem{
-			{Amount: "1.00"},
+			{Amount: money.MustParse("1.00").Quoted()},
 		},
 	})
 	if err != nil {
@@ -129,7 +132,7 @@ func TestValidateComfortCreateOperationsRawBody(t *testing.T) {
 
 	err = validateComfortCreateOperations(comfort.CreateOperationsRequest{
 		RawBody: []comfort.CreateOperationItem{
-			{Amount: ""},
+			{},
 		},
 	})
 	ve, ok = err.(*ValidationError)
@@ -195,6 +198,44 @@ func TestDryRunSkipsHTTPCall(t *testing.T) {
 	}
 }
 
+func TestKnownStatusRefusesInvalidOperation(t *testing.T) {
+	var hitCount int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hitCount, 1)
+		_, _ = w.Write([]byte(`{}`))
+	}))
+	defer ts.Close()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	client, err := NewClient(
+		WithPrivateKey(key),
+		WithAcquiringBaseURL(ts.URL),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	req := &acquiring.CompleteHoldRequest{MerchantID: "1", SessionID: "s-1"}
+	err = client.Acquiring().CompleteHold(context.Background(), req, WithKnownStatus(consts.SessionStatusCreated))
+	var se *SessionStatusError
+	if !errors.As(err, &se) || se.Status != consts.SessionStatusCreated {
+		t.Fatalf("expected SessionStatusError, got %v", err)
+	}
+	if atomic.LoadInt32(&hitCount) != 0 {
+		t.Fatalf("expected no HTTP calls, got %d", hitCount)
+	}
+
+	if err := client.Acquiring().CompleteHold(context.Background(), req, WithKnownStatus(consts.SessionStatusHolded)); err != nil {
+		t.Fatalf("complete hold of holded session: %v", err)
+	}
+	if atomic.LoadInt32(&hitCount) != 1 {
+		t.Fatalf("expected 1 HTTP call, got %d", hitCount)
+	}
+}
+
 func TestNewClientWithRecorderRecordsTraffic(t *testing.T) {
 	key, err := rsa.GenerateKey(rand.Reader, 2048)
 	if err != nil {
@@ -405,3 +446,49 @@ func (t *testLogger) Errorf(string, ...any) {
 		t.errCount++
 	}
 }
+
+func TestWithMiddlewareWrapsBothAPIs(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Audit") != "1" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		_, _ = w.Write([]byte(`{"balance":"10.00"}`))
+	}))
+	defer ts.Close()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	var signed int32
+	client, err := NewClient(
+		WithPrivateKey(key),
+		WithAcquiringBaseURL(ts.URL),
+		WithComfortBaseURL(ts.URL),
+		WithComfortMerchantID("42"),
+		WithLogger(nil),
+		WithMiddleware(func(next RoundTrip) RoundTrip {
+			return func(ctx context.Context, req *HTTPRequest) (*HTTPResponse, error) {
+				if req.HTTP.Header.Get(consts.HeaderXSign) != "" {
+					atomic.AddInt32(&signed, 1)
+				}
+				req.HTTP.Header.Set("X-Audit", "1")
+				return next(ctx, req)
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	if _, err := client.Comfort().Balance(context.Background()); err != nil {
+		t.Fatalf("comfort balance: %v", err)
+	}
+	if _, err := client.Acquiring().GetStatus(context.Background(), &acquiring.SessionRequest{MerchantID: "1", SessionID: "s-1"}); err != nil {
+		t.Fatalf("get status: %v", err)
+	}
+	if atomic.LoadInt32(&signed) != 2 {
+		t.Fatalf("middleware must see 2 signed requests, saw %d", signed)
+	}
+}
@@ -0,0 +1,205 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+
+	go_nova "github.com/stremovskyy/go-nova"
+	"github.com/stremovskyy/go-nova/acquiring"
+	"github.com/stremovskyy/go-nova/money"
+)
+
+// amountFlag is a flag.Value holding a hryvnia amount such as "100.50".
+type amountFlag struct {
+	amount money.Amount
+	set    bool
+}
+
+func (f *amountFlag) String() string {
+	if f == nil || !f.set {
+		return ""
+	}
+	return f.amount.String()
+}
+
+func (f *amountFlag) Set(s string) error {
+	a, err := money.Parse(s)
+	if err != nil {
+		return err
+	}
+	f.amount, f.set = a, true
+	return nil
+}
+
+// acquiringCall parses the flags of an acquiring command and builds its client.
+func acquiringCall(a *app, fs *flagSet, args []string, requiredFlags ...string) (go_nova.Nova, string, []go_nova.RunOption, error) {
+	if err := parse(fs.FlagSet, args); err != nil {
+		return nil, "", nil, err
+	}
+	if err := required(fs.FlagSet, requiredFlags...); err != nil {
+		return nil, "", nil, err
+	}
+	merchantID, err := fs.g.merchant()
+	if err != nil {
+		return nil, "", nil, err
+	}
+	client, err := fs.g.client()
+	if err != nil {
+		return nil, "", nil, err
+	}
+	return client, merchantID, fs.g.runOptions(a, client.Sign), nil
+}
+
+func cmdSessionCreate(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("session create")
+	phone := fs.String("phone", "", "client phone, e.g. +380XXXXXXXXX (required)")
+	firstName := fs.String("first-name", "", "client first name")
+	lastName := fs.String("last-name", "", "client last name")
+	email := fs.String("email", "", "client email")
+	callbackURL := fs.String("callback-url", a.getenv("NOVAPAY_CALLBACK_URL"), "postback `url` [NOVAPAY_CALLBACK_URL]")
+	successURL := fs.String("success-url", "", "redirect `url` after a successful payment")
+	failURL := fs.String("fail-url", "", "redirect `url` after a failed payment")
+	metadata := fs.String("metadata", "", "metadata as a JSON object")
+
+	client, merchantID, runOpts, err := acquiringCall(a, fs, args, "phone")
+	if err != nil {
+		return err
+	}
+	req := &acquiring.CreateSessionRequest{
+		MerchantID:      merchantID,
+		ClientPhone:     *phone,
+		ClientFirstName: optional(*firstName),
+		ClientLastName:  optional(*lastName),
+		ClientEmail:     optional(*email),
+		CallbackURL:     optional(*callbackURL),
+		SuccessURL:      optional(*successURL),
+		FailURL:         optional(*failURL),
+	}
+	if *metadata != "" {
+		if !json.Valid([]byte(*metadata)) {
+			return fmt.Errorf("-metadata is not valid JSON")
+		}
+		req.Metadata = json.RawMessage(*metadata)
+	}
+	out, err := client.Acquiring().CreateSession(ctx, req, runOpts...)
+	if err != nil {
+		return err
+	}
+	return a.printJSON(out)
+}
+
+func cmdSessionStatus(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("session status")
+	id := fs.String("id", "", "session `id` (required)")
+	client, merchantID, runOpts, err := acquiringCall(a, fs, args, "id")
+	if err != nil {
+		return err
+	}
+	out, err := client.Acquiring().GetStatus(ctx, &acquiring.SessionRequest{MerchantID: merchantID, SessionID: *id}, runOpts...)
+	if err != nil {
+		return err
+	}
+	return a.printJSON(out)
+}
+
+func cmdSessionVoid(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("session void")
+	id := fs.String("id", "", "session `id` (required)")
+	client, merchantID, runOpts, err := acquiringCall(a, fs, args, "id")
+	if err != nil {
+		return err
+	}
+	if err := client.Acquiring().VoidSession(ctx, &acquiring.SessionRequest{MerchantID: merchantID, SessionID: *id}, runOpts...); err != nil {
+		return err
+	}
+	return a.printDone(fs.g, map[string]any{"session_id": *id, "action": "void"})
+}
+
+func cmdSessionExpire(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("session expire")
+	id := fs.String("id", "", "session `id` (required)")
+	client, merchantID, runOpts, err := acquiringCall(a, fs, args, "id")
+	if err != nil {
+		return err
+	}
+	if err := client.Acquiring().ExpireSession(ctx, &acquiring.SessionRequest{MerchantID: merchantID, SessionID: *id}, runOpts...); err != nil {
+		return err
+	}
+	return a.printDone(fs.g, map[string]any{"session_id": *id, "action": "expire"})
+}
+
+func cmdSessionCompleteHold(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("session complete-hold")
+	id := fs.String("id", "", "session `id` (required)")
+	var amount amountFlag
+	fs.Var(&amount, "amount", "amount to charge for a partial completion, e.g. 100.50")
+	client, merchantID, runOpts, err := acquiringCall(a, fs, args, "id")
+	if err != nil {
+		return err
+	}
+	req := &acquiring.CompleteHoldRequest{MerchantID: merchantID, SessionID: *id}
+	if amount.set {
+		req.Amount = &amount.amount
+	}
+	if err := client.Acquiring().CompleteHold(ctx, req, runOpts...); err != nil {
+		return err
+	}
+	return a.printDone(fs.g, map[string]any{"session_id": *id, "action": "complete-hold"})
+}
+
+func cmdWaybillPrint(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("waybill print")
+	id := fs.String("id", "", "session `id` (required)")
+	out := fs.String("out", "", "write the waybill to `file` instead of stdout")
+	client, merchantID, runOpts, err := acquiringCall(a, fs, args, "id")
+	if err != nil {
+		return err
+	}
+	b, err := client.Acquiring().PrintExpressWaybill(ctx, &acquiring.SessionRequest{MerchantID: merchantID, SessionID: *id}, runOpts...)
+	if err != nil || fs.g.dryRun {
+		return err
+	}
+	if *out == "" {
+		_, err := a.stdout.Write(b)
+		return err
+	}
+	if err := os.WriteFile(*out, b, 0o644); err != nil {
+		return err
+	}
+	return a.printJSON(map[string]any{"session_id": *id, "file": *out, "bytes": len(b)})
+}
+
+func cmdDeliveryPrice(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("delivery-price")
+	city := fs.String("city", "", "recipient city ref (required)")
+	warehouse := fs.String("warehouse", "", "recipient warehouse ref (required)")
+	weight := fs.Float64("weight", 0, "weight, kg")
+	volumeWeight := fs.Float64("volume-weight", 0, "volume weight, kg")
+	var amount amountFlag
+	fs.Var(&amount, "amount", "declared amount, e.g. 100.50")
+	client, merchantID, runOpts, err := acquiringCall(a, fs, args, "city", "warehouse")
+	if err != nil {
+		return err
+	}
+	out, err := client.Acquiring().DeliveryPrice(ctx, &acquiring.DeliveryPriceRequest{
+		MerchantID:         merchantID,
+		RecipientCity:      *city,
+		RecipientWarehouse: *warehouse,
+		Weight:             *weight,
+		VolumeWeight:       *volumeWeight,
+		Amount:             amount.amount,
+	}, runOpts...)
+	if err != nil {
+		return err
+	}
+	return a.printJSON(out)
+}
+
+func optional(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
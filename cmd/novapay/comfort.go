@@ -0,0 +1,185 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	go_nova "github.com/stremovskyy/go-nova"
+	"github.com/stremovskyy/go-nova/comfort"
+)
+
+// comfortCall parses the flags of a Comfort command and builds its client.
+func comfortCall(a *app, fs *flagSet, args []string, requiredFlags ...string) (go_nova.Nova, []go_nova.RunOption, error) {
+	if err := parse(fs.FlagSet, args); err != nil {
+		return nil, nil, err
+	}
+	if err := required(fs.FlagSet, requiredFlags...); err != nil {
+		return nil, nil, err
+	}
+	if fs.g.comfortMerchantID == "" {
+		return nil, nil, errors.New("comfort merchant id is required: set -comfort-merchant-id or NOVAPAY_COMFORT_MERCHANT_ID")
+	}
+	client, err := fs.g.client()
+	if err != nil {
+		return nil, nil, err
+	}
+	return client, fs.g.runOptions(a, client.SignComfort), nil
+}
+
+func cmdComfortBalance(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("comfort balance")
+	client, runOpts, err := comfortCall(a, fs, args)
+	if err != nil {
+		return err
+	}
+	out, err := client.Comfort().Balance(ctx, runOpts...)
+	if err != nil {
+		return err
+	}
+	return a.printJSON(out)
+}
+
+func cmdComfortPayout(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("comfort payout")
+	file := fs.String("file", "", "JSON `file` with payout items (array or {\"RAW_BODY\": [...]}); \"-\" reads stdin")
+	var amount amountFlag
+	fs.Var(&amount, "amount", "amount of a single payout, e.g. 100.50")
+	pan := fs.String("pan", "", "card number of a single payout")
+	purpose := fs.String("purpose", "", "purpose of a single payout")
+	guid := fs.String("guid", "", "GUID of a single payout (generated when empty)")
+	idempotencyKey := fs.String("idempotency-key", "", "derive payout GUIDs from `key` so a rerun cannot pay twice (required)")
+	reconcile := fs.Bool("reconcile", false, "check OperationsStatus before retrying a failed create")
+	client, runOpts, err := comfortCall(a, fs, args, "idempotency-key")
+	if err != nil {
+		return err
+	}
+
+	var req comfort.CreateOperationsRequest
+	switch {
+	case *file != "" && amount.set:
+		return errors.New("use either -file or -amount")
+	case *file != "":
+		b, err := a.readPayload(*file)
+		if err != nil {
+			return err
+		}
+		if req, err = decodePayoutItems(b); err != nil {
+			return err
+		}
+	case amount.set:
+		req.RawBody = []comfort.CreateOperationItem{{
+			GUID:      optional(*guid),
+			Amount:    amount.amount.Quoted(),
+			PayoutPAN: optional(*pan),
+			Purpose:   optional(*purpose),
+		}}
+	default:
+		return errors.New("set -amount for a single payout or -file for a batch")
+	}
+
+	runOpts = append(runOpts, go_nova.WithIdempotencyKey(*idempotencyKey))
+	if *reconcile {
+		runOpts = append(runOpts, go_nova.WithPayoutReconcile())
+	}
+	out, err := client.Comfort().CreateOperations(ctx, req, runOpts...)
+	if err != nil {
+		var re *go_nova.PayoutReconcileError
+		if errors.As(err, &re) {
+			_ = a.printJSON(map[string]any{"created": re.Created, "missing": re.Missing, "unknown": re.Unknown})
+		} else {
+			// The payouts may exist: print their GUIDs so they can be looked up.
+			_ = a.printJSON(map[string]any{"guids": payoutGUIDs(req, *idempotencyKey)})
+		}
+		return err
+	}
+	return a.printJSON(out)
+}
+
+// payoutGUIDs returns the GUIDs CreateOperations sends for the items of req.
+func payoutGUIDs(req comfort.CreateOperationsRequest, idempotencyKey string) []string {
+	guids := make([]string, len(req.RawBody))
+	for i, item := range req.RawBody {
+		if item.GUID != nil && *item.GUID != "" {
+			guids[i] = *item.GUID
+			continue
+		}
+		guids[i] = go_nova.PayoutGUID(idempotencyKey, i)
+	}
+	return guids
+}
+
+func decodePayoutItems(b []byte) (comfort.CreateOperationsRequest, error) {
+	var req comfort.CreateOperationsRequest
+	if err := json.Unmarshal(b, &req.RawBody); err == nil {
+		return req, nil
+	}
+	if err := json.Unmarshal(b, &req); err != nil {
+		return req, fmt.Errorf("decode payout items: %w", err)
+	}
+	return req, nil
+}
+
+func cmdComfortRefund(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("comfort refund")
+	ids := fs.String("ids", "", "comma-separated operation public `ids` (required)")
+	client, runOpts, err := comfortCall(a, fs, args, "ids")
+	if err != nil {
+		return err
+	}
+	req := &comfort.RefundOperationsRequest{}
+	for _, id := range strings.Split(*ids, ",") {
+		if id = strings.TrimSpace(id); id != "" {
+			req.RawBody = append(req.RawBody, id)
+		}
+	}
+	out, err := client.Comfort().RefundOperations(ctx, req, runOpts...)
+	if err != nil {
+		return err
+	}
+	return a.printJSON(out)
+}
+
+func cmdComfortStatus(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("comfort status")
+	guid := fs.String("guid", "", "payout `guid` (required)")
+	client, runOpts, err := comfortCall(a, fs, args, "guid")
+	if err != nil {
+		return err
+	}
+	out, err := client.Comfort().OperationsStatus(ctx, &comfort.OperationsStatusRequest{GUID: guid}, runOpts...)
+	if err != nil {
+		return err
+	}
+	return a.printJSON(out)
+}
+
+func cmdComfortExport(ctx context.Context, a *app, args []string) error {
+	fs := a.flags("comfort export")
+	from := fs.String("from", "", "first `date` of the export, YYYY-MM-DD (required)")
+	to := fs.String("to", "", "last `date` of the export, YYYY-MM-DD (required)")
+	email := fs.String("email", "", "recipient `email` (required)")
+	format := fs.String("format", string(comfort.ExportFormatCSV), "export format: CSV, JSON or XLSX")
+	client, runOpts, err := comfortCall(a, fs, args, "from", "to", "email")
+	if err != nil {
+		return err
+	}
+	f := comfort.ExportFormat(strings.ToUpper(*format))
+	switch f {
+	case comfort.ExportFormatCSV, comfort.ExportFormatJSON, comfort.ExportFormatXLSX:
+	default:
+		return fmt.Errorf("unknown -format %q", *format)
+	}
+	out, err := client.Comfort().ExportOperations(ctx, &comfort.ExportOperationsRequest{
+		FromDate:       *from,
+		ToDate:         *to,
+		Format:         &f,
+		RecepientEmail: *email,
+	}, runOpts...)
+	if err != nil {
+		return err
+	}
+	return a.printJSON(out)
+}
@@ -0,0 +1,283 @@
+// Command novapay calls the NovaPay API from the shell for ops and support work.
+//
+// Usage:
+//
+//	novapay <command> [subcommand] [flags]
+//
+// Keys and merchant IDs are read from flags or from the environment (see
+// .env.example); a .env file in the working directory or its parents is loaded
+// first. Results are printed as JSON. Every API command accepts -dry-run, which
+// prints the signed request instead of sending it.
+//
+// Run "novapay help" for the list of commands.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	go_nova "github.com/stremovskyy/go-nova"
+	"github.com/stremovskyy/go-nova/consts"
+	"github.com/stremovskyy/go-nova/internal/dotenv"
+	"github.com/stremovskyy/go-nova/internal/jsonutil"
+)
+
+func main() {
+	if _, err := dotenv.LoadNearest(".env"); err != nil {
+		fmt.Fprintf(os.Stderr, "novapay: load .env: %v\n", err)
+		os.Exit(1)
+	}
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	a := &app{stdin: os.Stdin, stdout: os.Stdout, stderr: os.Stderr, getenv: os.Getenv}
+	os.Exit(a.run(ctx, os.Args[1:]))
+}
+
+// app holds the process I/O so commands can be run from tests.
+type app struct {
+	stdin  io.Reader
+	stdout io.Writer
+	stderr io.Writer
+	getenv func(string) string
+}
+
+// command is one leaf of the command tree, e.g. "session status".
+type command struct {
+	summary string
+	run     func(ctx context.Context, a *app, args []string) error
+}
+
+var commands = map[string]command{
+	"session create":        {"create an acquiring session", cmdSessionCreate},
+	"session status":        {"get the status of a session", cmdSessionStatus},
+	"session void":          {"void a session", cmdSessionVoid},
+	"session expire":        {"expire a session", cmdSessionExpire},
+	"session complete-hold": {"complete a held session, fully or partially", cmdSessionCompleteHold},
+	"waybill print":         {"download the express waybill of a delivery session", cmdWaybillPrint},
+	"delivery-price":        {"calculate the delivery price", cmdDeliveryPrice},
+	"comfort balance":       {"show the Comfort balance", cmdComfortBalance},
+	"comfort payout":        {"create Comfort payouts", cmdComfortPayout},
+	"comfort refund":        {"refund Comfort payouts", cmdComfortRefund},
+	"comfort status":        {"get the status of a Comfort payout", cmdComfortStatus},
+	"comfort export":        {"request an operations export by email", cmdComfortExport},
+	"sign":                  {"print the x-sign of a payload", cmdSign},
+	"verify":                {"verify the x-sign of a payload", cmdVerify},
+}
+
+// errUsage is returned after a usage message has been printed.
+var errUsage = errors.New("usage")
+
+func (a *app) run(ctx context.Context, args []string) int {
+	if len(args) == 0 || args[0] == "help" || args[0] == "-h" || args[0] == "--help" {
+		a.usage()
+		if len(args) == 0 {
+			return 2
+		}
+		return 0
+	}
+
+	name := args[0]
+	rest := args[1:]
+	if _, ok := commands[name]; !ok && len(rest) > 0 {
+		name, rest = args[0]+" "+args[1], args[2:]
+	}
+	cmd, ok := commands[name]
+	if !ok {
+		fmt.Fprintf(a.stderr, "novapay: unknown command %q\n\n", strings.Join(args[:min(2, len(args))], " "))
+		a.usage()
+		return 2
+	}
+
+	if err := cmd.run(ctx, a, rest); err != nil {
+		if errors.Is(err, errUsage) || errors.Is(err, flag.ErrHelp) {
+			return 2
+		}
+		fmt.Fprintf(a.stderr, "novapay: %s: %v\n", name, err)
+		var ae *go_nova.APIError
+		if errors.As(err, &ae) && len(ae.Body) > 0 {
+			fmt.Fprintf(a.stderr, "%s\n", ae.Body)
+		}
+		return 1
+	}
+	return 0
+}
+
+func (a *app) usage() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Fprintln(a.stderr, "Usage: novapay <command> [flags]")
+	fmt.Fprintln(a.stderr)
+	fmt.Fprintln(a.stderr, "Commands:")
+	for _, name := range names {
+		fmt.Fprintf(a.stderr, "  %-24s %s\n", name, commands[name].summary)
+	}
+	fmt.Fprintln(a.stderr)
+	fmt.Fprintln(a.stderr, `Run "novapay <command> -h" for the flags of a command.`)
+}
+
+// globalFlags are accepted by every command.
+type globalFlags struct {
+	privateKey        string
+	comfortPrivateKey string
+	publicKey         string
+	merchantID        string
+	comfortMerchantID string
+	baseURL           string
+	comfortBaseURL    string
+	production        bool
+	timeout           time.Duration
+	dryRun            bool
+	verbose           bool
+}
+
+// flagSet is the flag set of one command together with its global flags.
+type flagSet struct {
+	*flag.FlagSet
+	g *globalFlags
+}
+
+// flags returns a flag set for the command with the global flags registered.
+func (a *app) flags(name string) *flagSet {
+	fs := flag.NewFlagSet("novapay "+name, flag.ContinueOnError)
+	fs.SetOutput(a.stderr)
+
+	g := &globalFlags{}
+	fs.StringVar(&g.privateKey, "private-key", a.getenv("NOVAPAY_PRIVATE_KEY_PATH"), "merchant private key PEM `file` [NOVAPAY_PRIVATE_KEY_PATH]")
+	fs.StringVar(&g.comfortPrivateKey, "comfort-private-key", a.getenv("NOVAPAY_COMFORT_PRIVATE_KEY_PATH"), "separate Comfort private key PEM `file` [NOVAPAY_COMFORT_PRIVATE_KEY_PATH]")
+	fs.StringVar(&g.publicKey, "public-key", a.getenv("NOVAPAY_PUBLIC_KEY_PATH"), "NovaPay public key PEM `file` [NOVAPAY_PUBLIC_KEY_PATH]")
+	fs.StringVar(&g.merchantID, "merchant-id", a.getenv("NOVAPAY_MERCHANT_ID"), "acquiring merchant `id` [NOVAPAY_MERCHANT_ID]")
+	fs.StringVar(&g.comfortMerchantID, "comfort-merchant-id", a.getenv("NOVAPAY_COMFORT_MERCHANT_ID"), "Comfort merchant `id` [NOVAPAY_COMFORT_MERCHANT_ID]")
+	fs.StringVar(&g.baseURL, "base-url", a.getenv("NOVAPAY_BASE_URL"), "acquiring API base `url` [NOVAPAY_BASE_URL]")
+	fs.StringVar(&g.comfortBaseURL, "comfort-base-url", a.getenv("NOVAPAY_COMFORT_BASE_URL"), "Comfort API base `url` [NOVAPAY_COMFORT_BASE_URL]")
+	fs.BoolVar(&g.production, "prod", a.getenv("NOVAPAY_ENV") == "production", "use the production acquiring API [NOVAPAY_ENV=production]")
+	fs.DurationVar(&g.timeout, "timeout", 30*time.Second, "request timeout")
+	fs.BoolVar(&g.dryRun, "dry-run", false, "print the request instead of sending it")
+	fs.BoolVar(&g.verbose, "v", false, "log HTTP requests to stderr")
+	return &flagSet{FlagSet: fs, g: g}
+}
+
+// client builds a client from the global flags.
+func (g *globalFlags) client() (go_nova.Nova, error) {
+	opts := []go_nova.Option{go_nova.WithTimeout(g.timeout)}
+	if !g.verbose {
+		opts = append(opts, go_nova.WithLogger(nil))
+	}
+	if g.privateKey != "" {
+		opts = append(opts, go_nova.WithPrivateKeyFile(g.privateKey))
+	}
+	if g.comfortPrivateKey != "" {
+		opts = append(opts, go_nova.WithComfortPrivateKeyFile(g.comfortPrivateKey))
+	}
+	if g.publicKey != "" {
+		opts = append(opts, go_nova.WithPublicKeyFile(g.publicKey))
+	}
+	if g.comfortMerchantID != "" {
+		opts = append(opts, go_nova.WithComfortMerchantID(g.comfortMerchantID))
+	}
+	switch {
+	case g.baseURL != "":
+		opts = append(opts, go_nova.WithAcquiringBaseURL(g.baseURL))
+	case g.production:
+		opts = append(opts, go_nova.WithAcquiringBaseURL(consts.ProductionAcquiringURL))
+	}
+	if g.comfortBaseURL != "" {
+		opts = append(opts, go_nova.WithComfortBaseURL(g.comfortBaseURL))
+	}
+	return go_nova.NewClient(opts...)
+}
+
+// runOptions returns the per-call options. On -dry-run the request is printed as
+// JSON with the x-sign sign computes for it.
+func (g *globalFlags) runOptions(a *app, sign func([]byte) (string, error)) []go_nova.RunOption {
+	if !g.dryRun {
+		return nil
+	}
+	return []go_nova.RunOption{go_nova.DryRun(func(method string, url string, payload any) {
+		out := map[string]any{"dry_run": true, "method": method, "url": url, "payload": payload}
+		if body, err := jsonutil.Marshal(payload); err == nil {
+			if xSign, err := sign(body); err == nil {
+				out["x_sign"] = xSign
+			}
+		}
+		_ = a.printJSON(out)
+	})}
+}
+
+// merchant returns the acquiring merchant ID or an error naming the flag.
+func (g *globalFlags) merchant() (string, error) {
+	if g.merchantID == "" {
+		return "", errors.New("merchant id is required: set -merchant-id or NOVAPAY_MERCHANT_ID")
+	}
+	return g.merchantID, nil
+}
+
+// parse parses args and rejects positional arguments.
+func parse(fs *flag.FlagSet, args []string) error {
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() > 0 {
+		fmt.Fprintf(fs.Output(), "unexpected argument %q\n", fs.Arg(0))
+		fs.Usage()
+		return errUsage
+	}
+	return nil
+}
+
+// required fails with a usage message when one of the named flags is empty.
+func required(fs *flag.FlagSet, names ...string) error {
+	for _, name := range names {
+		if f := fs.Lookup(name); f != nil && f.Value.String() == "" {
+			fmt.Fprintf(fs.Output(), "flag -%s is required\n", name)
+			fs.Usage()
+			return errUsage
+		}
+	}
+	return nil
+}
+
+// printJSON writes v as indented JSON. Nil results (dry runs) print nothing.
+func (a *app) printJSON(v any) error {
+	if v == nil {
+		return nil
+	}
+	if rv := reflect.ValueOf(v); (rv.Kind() == reflect.Pointer || rv.Kind() == reflect.Map || rv.Kind() == reflect.Slice) && rv.IsNil() {
+		return nil
+	}
+	enc := json.NewEncoder(a.stdout)
+	enc.SetIndent("", "  ")
+	enc.SetEscapeHTML(false)
+	return enc.Encode(v)
+}
+
+// printDone reports a command without a response body. It prints nothing on -dry-run.
+func (a *app) printDone(g *globalFlags, fields map[string]any) error {
+	if g.dryRun {
+		return nil
+	}
+	fields["ok"] = true
+	return a.printJSON(fields)
+}
+
+// readPayload reads a request body from file, or stdin for "" and "-".
+func (a *app) readPayload(file string) ([]byte, error) {
+	if file == "" || file == "-" {
+		return io.ReadAll(a.stdin)
+	}
+	return os.ReadFile(file)
+}
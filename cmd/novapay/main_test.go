@@ -0,0 +1,143 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	go_nova "github.com/stremovskyy/go-nova"
+	"github.com/stremovskyy/go-nova/novatest"
+)
+
+// newTestApp starts an emulator and returns an app whose environment points at it.
+func newTestApp(t *testing.T) (*app, *bytes.Buffer, *bytes.Buffer) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	srv := novatest.NewServer(novatest.WithClientPublicKey(&key.PublicKey))
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	privatePath := filepath.Join(dir, "private.pem")
+	publicPath := filepath.Join(dir, "public.pem")
+	pub, err := x509.MarshalPKIXPublicKey(&key.PublicKey)
+	if err != nil {
+		t.Fatalf("marshal public key: %v", err)
+	}
+	if err := os.WriteFile(privatePath, pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(publicPath, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: pub}), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	env := map[string]string{
+		"NOVAPAY_PRIVATE_KEY_PATH":    privatePath,
+		"NOVAPAY_PUBLIC_KEY_PATH":     publicPath,
+		"NOVAPAY_MERCHANT_ID":         "1",
+		"NOVAPAY_COMFORT_MERCHANT_ID": novatest.DefaultComfortMerchantID,
+		"NOVAPAY_BASE_URL":            srv.URL,
+		"NOVAPAY_COMFORT_BASE_URL":    srv.URL,
+	}
+	var stdout, stderr bytes.Buffer
+	a := &app{stdin: strings.NewReader(""), stdout: &stdout, stderr: &stderr, getenv: func(k string) string { return env[k] }}
+	return a, &stdout, &stderr
+}
+
+func runJSON(t *testing.T, a *app, stdout, stderr *bytes.Buffer, args ...string) map[string]any {
+	t.Helper()
+	stdout.Reset()
+	stderr.Reset()
+	if code := a.run(context.Background(), args); code != 0 {
+		t.Fatalf("%v: exit %d: %s", args, code, stderr.String())
+	}
+	var out map[string]any
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil {
+		t.Fatalf("%v: output is not a JSON object: %q", args, stdout.String())
+	}
+	return out
+}
+
+func TestSessionCommands(t *testing.T) {
+	a, stdout, stderr := newTestApp(t)
+
+	created := runJSON(t, a, stdout, stderr, "session", "create", "-phone", "+380000000000")
+	id, _ := created["id"].(string)
+	if id == "" {
+		t.Fatalf("no session id in %v", created)
+	}
+
+	status := runJSON(t, a, stdout, stderr, "session", "status", "-id", id)
+	if status["status"] != "created" {
+		t.Fatalf("unexpected status: %v", status)
+	}
+
+	dry := runJSON(t, a, stdout, stderr, "session", "expire", "-id", id, "-dry-run")
+	if dry["dry_run"] != true || dry["x_sign"] == "" || !strings.HasSuffix(dry["url"].(string), "/v1/expire") {
+		t.Fatalf("unexpected dry run output: %v", dry)
+	}
+	if status := runJSON(t, a, stdout, stderr, "session", "status", "-id", id); status["status"] != "created" {
+		t.Fatalf("dry run must not expire the session: %v", status)
+	}
+}
+
+func TestComfortBalance(t *testing.T) {
+	a, stdout, stderr := newTestApp(t)
+	out := runJSON(t, a, stdout, stderr, "comfort", "balance")
+	if out["balance"] == nil {
+		t.Fatalf("unexpected balance output: %v", out)
+	}
+}
+
+func TestComfortPayout(t *testing.T) {
+	a, stdout, stderr := newTestApp(t)
+	if code := a.run(context.Background(), []string{"comfort", "payout", "-amount", "10.00"}); code != 2 || !strings.Contains(stderr.String(), "-idempotency-key is required") {
+		t.Fatalf("expected usage error without -idempotency-key, got %d: %s", code, stderr.String())
+	}
+
+	stdout.Reset()
+	stderr.Reset()
+	if code := a.run(context.Background(), []string{"comfort", "payout", "-amount", "10.00", "-idempotency-key", "order-7"}); code != 0 {
+		t.Fatalf("payout: exit %d: %s", code, stderr.String())
+	}
+	var out []map[string]any
+	if err := json.Unmarshal(stdout.Bytes(), &out); err != nil || len(out) != 1 || out[0]["guid"] != go_nova.PayoutGUID("order-7", 0) {
+		t.Fatalf("unexpected payout output: %q", stdout.String())
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	a, stdout, stderr := newTestApp(t)
+	body := `{"merchant_id":"1","session_id":"s-1"}` + "\n"
+
+	a.stdin = strings.NewReader(body)
+	signed := runJSON(t, a, stdout, stderr, "sign")
+	xSign, _ := signed["x_sign"].(string)
+
+	a.stdin = strings.NewReader(body)
+	if out := runJSON(t, a, stdout, stderr, "verify", "-x-sign", xSign); out["valid"] != true {
+		t.Fatalf("expected a valid signature: %v", out)
+	}
+
+	a.stdin = strings.NewReader(`{"tampered":true}`)
+	if code := a.run(context.Background(), []string{"verify", "-x-sign", xSign}); code != 1 {
+		t.Fatalf("expected exit 1 for a bad signature, got %d", code)
+	}
+}
+
+func TestUnknownCommand(t *testing.T) {
+	a, _, stderr := newTestApp(t)
+	if code := a.run(context.Background(), []string{"session", "refund"}); code != 2 || !strings.Contains(stderr.String(), "unknown command") {
+		t.Fatalf("expected usage error, got %d: %s", code, stderr.String())
+	}
+}
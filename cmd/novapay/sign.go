@@ -0,0 +1,75 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"errors"
+)
+
+func cmdSign(_ context.Context, a *app, args []string) error {
+	fs := a.flags("sign")
+	file := fs.String("file", "", "payload `file`; stdin when empty or \"-\"; trailing newlines are ignored")
+	comfortAPI := fs.Bool("comfort", false, "sign for the Comfort API")
+	if err := parse(fs.FlagSet, args); err != nil {
+		return err
+	}
+	body, err := a.readPayload(*file)
+	if err != nil {
+		return err
+	}
+	body = bytes.TrimRight(body, "\r\n")
+
+	client, err := fs.g.client()
+	if err != nil {
+		return err
+	}
+	sign := client.Sign
+	if *comfortAPI {
+		sign = client.SignComfort
+	}
+	xSign, err := sign(body)
+	if err != nil {
+		return err
+	}
+	return a.printJSON(map[string]any{"x_sign": xSign, "bytes": len(body)})
+}
+
+// errInvalidSignature makes verify exit with status 1 after printing the result.
+var errInvalidSignature = errors.New("signature is not valid")
+
+func cmdVerify(_ context.Context, a *app, args []string) error {
+	fs := a.flags("verify")
+	file := fs.String("file", "", "payload `file`; stdin when empty or \"-\"; trailing newlines are ignored")
+	xSign := fs.String("x-sign", "", "x-sign header value (required)")
+	comfortAPI := fs.Bool("comfort", false, "verify a Comfort signature")
+	if err := parse(fs.FlagSet, args); err != nil {
+		return err
+	}
+	if err := required(fs.FlagSet, "x-sign"); err != nil {
+		return err
+	}
+	body, err := a.readPayload(*file)
+	if err != nil {
+		return err
+	}
+	body = bytes.TrimRight(body, "\r\n")
+
+	client, err := fs.g.client()
+	if err != nil {
+		return err
+	}
+	verify := client.VerifyKey
+	if *comfortAPI {
+		verify = client.VerifyComfortKey
+	}
+	key, err := verify(body, *xSign)
+	if err != nil {
+		_ = a.printJSON(map[string]any{"valid": false, "error": err.Error()})
+		return errInvalidSignature
+	}
+	out := map[string]any{"valid": true, "key_id": key.ID}
+	if key.Deprecated {
+		out["deprecated_key"] = true
+	}
+	return a.printJSON(out)
+}
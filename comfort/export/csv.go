@@ -0,0 +1,81 @@
+package export
+
+import (
+	"bufio"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+type csvSource struct {
+	r    *csv.Reader
+	cols []string
+}
+
+func newCSVSource(r io.Reader, comma rune) (*csvSource, error) {
+	br := bufio.NewReader(r)
+	if comma == 0 {
+		line, err := br.Peek(br.Size())
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, bufio.ErrBufferFull) {
+			return nil, fmt.Errorf("export: read csv header: %w", err)
+		}
+		comma = detectComma(string(line))
+	}
+
+	cr := csv.NewReader(br)
+	cr.Comma = comma
+	cr.FieldsPerRecord = -1
+	cr.ReuseRecord = true
+
+	header, err := cr.Read()
+	if err != nil {
+		return nil, fmt.Errorf("export: read csv header: %w", err)
+	}
+	cols := make([]string, len(header))
+	for i, h := range header {
+		cols[i] = normaliseColumn(h)
+	}
+	return &csvSource{r: cr, cols: cols}, nil
+}
+
+// detectComma picks ';' or ',' by counting them in the first line.
+func detectComma(s string) rune {
+	if i := strings.IndexByte(s, '\n'); i >= 0 {
+		s = s[:i]
+	}
+	if strings.Count(s, ";") > strings.Count(s, ",") {
+		return ';'
+	}
+	return ','
+}
+
+func (s *csvSource) columns() []string { return s.cols }
+
+func (s *csvSource) next() (map[string]string, error) {
+	rec, err := s.r.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("export: read csv: %w", err)
+	}
+	return zipRow(s.cols, rec), nil
+}
+
+// zipRow maps cells to their column names, ignoring cells past the header.
+func zipRow(cols []string, cells []string) map[string]string {
+	row := make(map[string]string, len(cols))
+	for i, c := range cols {
+		if c == "" {
+			continue
+		}
+		if i < len(cells) {
+			row[c] = strings.TrimSpace(cells[i])
+		} else {
+			row[c] = ""
+		}
+	}
+	return row
+}
@@ -0,0 +1,407 @@
+// Package export parses Comfort operation exports requested with
+// ComfortService.ExportOperations.
+//
+// NovaPay emails the export as CSV, JSON or XLSX. NewReader reads any of them,
+// checks the column layout and yields typed records one at a time:
+//
+//	r, err := export.NewReader(attachment, comfort.ExportFormatXLSX)
+//	if err != nil {
+//		return err
+//	}
+//	for {
+//		rec, err := r.Next()
+//		if err == io.EOF {
+//			break
+//		}
+//		if err != nil {
+//			return err
+//		}
+//		ledger.Add(rec.GUID, rec.Amount, rec.Status)
+//	}
+package export
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/stremovskyy/go-nova/comfort"
+	"github.com/stremovskyy/go-nova/consts"
+	"github.com/stremovskyy/go-nova/money"
+)
+
+// Column names of the export, after normalisation (lower case, "_" for spaces).
+const (
+	ColumnGUID         = "guid"
+	ColumnPublicID     = "public_id"
+	ColumnStatus       = "status"
+	ColumnAmount       = "amount"
+	ColumnPurpose      = "purpose"
+	ColumnPayoutPAN    = "payout_pan"
+	ColumnRecipient    = "recipient"
+	ColumnPhone        = "phone"
+	ColumnErrorCode    = "error_code"
+	ColumnErrorMessage = "error_message"
+	ColumnCreatedAt    = "created_at"
+	ColumnUpdatedAt    = "updated_at"
+)
+
+// RequiredColumns must be present in every export.
+var RequiredColumns = []string{ColumnGUID, ColumnPublicID, ColumnStatus, ColumnAmount, ColumnCreatedAt}
+
+var knownColumns = map[string]bool{
+	ColumnGUID:         true,
+	ColumnPublicID:     true,
+	ColumnStatus:       true,
+	ColumnAmount:       true,
+	ColumnPurpose:      true,
+	ColumnPayoutPAN:    true,
+	ColumnRecipient:    true,
+	ColumnPhone:        true,
+	ColumnErrorCode:    true,
+	ColumnErrorMessage: true,
+	ColumnCreatedAt:    true,
+	ColumnUpdatedAt:    true,
+}
+
+// Record is one payout operation of an export.
+type Record struct {
+	GUID         string
+	PublicID     string
+	Status       consts.OperationStatus
+	Amount       money.Amount
+	Purpose      string
+	PayoutPAN    string
+	Recipient    string
+	Phone        string
+	ErrorCode    string
+	ErrorMessage string
+	CreatedAt    time.Time
+	// UpdatedAt is zero when the export has no such column or the cell is empty.
+	UpdatedAt time.Time
+
+	// Extra keeps columns the package does not know, by normalised name.
+	Extra map[string]string
+}
+
+// LayoutError is returned by NewReader when the header does not match the expected layout.
+type LayoutError struct {
+	Missing   []string
+	Duplicate []string
+}
+
+func (e *LayoutError) Error() string {
+	if e == nil {
+		return "export: invalid column layout"
+	}
+	var parts []string
+	if len(e.Missing) > 0 {
+		parts = append(parts, "missing "+strings.Join(e.Missing, ", "))
+	}
+	if len(e.Duplicate) > 0 {
+		parts = append(parts, "duplicate "+strings.Join(e.Duplicate, ", "))
+	}
+	return "export: invalid column layout: " + strings.Join(parts, "; ")
+}
+
+// RowError is returned by Reader.Next when a row cannot be converted.
+//
+// The reader has moved past the row, so iteration may continue.
+type RowError struct {
+	// Row is the 1-based data row number, not counting the header.
+	Row    int
+	Column string
+	Err    error
+}
+
+func (e *RowError) Error() string {
+	if e == nil {
+		return "export: invalid row"
+	}
+	if e.Column == "" {
+		return fmt.Sprintf("export: row %d: %v", e.Row, e.Err)
+	}
+	return fmt.Sprintf("export: row %d: %s: %v", e.Row, e.Column, e.Err)
+}
+
+func (e *RowError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Err
+}
+
+// rowSource yields rows as column name → cell text.
+type rowSource interface {
+	columns() []string
+	next() (map[string]string, error)
+}
+
+// Reader iterates over the records of an export.
+type Reader struct {
+	src rowSource
+	row int
+	loc *time.Location
+}
+
+// Option configures a Reader.
+type Option func(*options)
+
+type options struct {
+	comma rune
+	loc   *time.Location
+}
+
+// WithComma sets the CSV field delimiter. By default it is detected from the
+// header line (',' or ';').
+func WithComma(r rune) Option {
+	return func(o *options) {
+		o.comma = r
+	}
+}
+
+// WithLocation sets the time zone of timestamps without an offset. Defaults to Europe/Kyiv,
+// or UTC when the time zone database is not available.
+func WithLocation(loc *time.Location) Option {
+	return func(o *options) {
+		if loc != nil {
+			o.loc = loc
+		}
+	}
+}
+
+// NewReader reads the header of an export in the given format and validates the layout.
+//
+// CSV and JSON are streamed from r. XLSX is a zip archive and is read into memory first.
+func NewReader(r io.Reader, format comfort.ExportFormat, opts ...Option) (*Reader, error) {
+	o := &options{loc: defaultLocation()}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+
+	var (
+		src rowSource
+		err error
+	)
+	switch comfort.ExportFormat(strings.ToUpper(string(format))) {
+	case comfort.ExportFormatCSV:
+		src, err = newCSVSource(r, o.comma)
+	case comfort.ExportFormatJSON:
+		src, err = newJSONSource(r)
+	case comfort.ExportFormatXLSX:
+		src, err = newXLSXSource(r)
+	default:
+		return nil, fmt.Errorf("export: unsupported format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := validateLayout(src.columns()); err != nil {
+		return nil, err
+	}
+	return &Reader{src: src, loc: o.loc}, nil
+}
+
+// Columns returns the normalised column names of the export.
+func (r *Reader) Columns() []string {
+	return append([]string(nil), r.src.columns()...)
+}
+
+// Next returns the next record, or io.EOF after the last one.
+func (r *Reader) Next() (*Record, error) {
+	for {
+		cells, err := r.src.next()
+		if err != nil {
+			return nil, err
+		}
+		r.row++
+		if isBlank(cells) {
+			continue
+		}
+		return r.record(cells)
+	}
+}
+
+// ReadAll returns all remaining records. It stops at the first error.
+func (r *Reader) ReadAll() ([]Record, error) {
+	var out []Record
+	for {
+		rec, err := r.Next()
+		if errors.Is(err, io.EOF) {
+			return out, nil
+		}
+		if err != nil {
+			return out, err
+		}
+		out = append(out, *rec)
+	}
+}
+
+func (r *Reader) record(cells map[string]string) (*Record, error) {
+	rec := &Record{
+		GUID:         cells[ColumnGUID],
+		PublicID:     cells[ColumnPublicID],
+		Status:       consts.OperationStatus(strings.ToLower(cells[ColumnStatus])),
+		Purpose:      cells[ColumnPurpose],
+		PayoutPAN:    cells[ColumnPayoutPAN],
+		Recipient:    cells[ColumnRecipient],
+		Phone:        cells[ColumnPhone],
+		ErrorCode:    cells[ColumnErrorCode],
+		ErrorMessage: cells[ColumnErrorMessage],
+	}
+	if rec.GUID == "" {
+		return nil, &RowError{Row: r.row, Column: ColumnGUID, Err: errors.New("empty")}
+	}
+
+	amount, err := parseAmount(cells[ColumnAmount])
+	if err != nil {
+		return nil, &RowError{Row: r.row, Column: ColumnAmount, Err: err}
+	}
+	rec.Amount = amount
+
+	if rec.CreatedAt, err = parseTime(cells[ColumnCreatedAt], r.loc); err != nil {
+		return nil, &RowError{Row: r.row, Column: ColumnCreatedAt, Err: err}
+	}
+	if v := cells[ColumnUpdatedAt]; v != "" {
+		if rec.UpdatedAt, err = parseTime(v, r.loc); err != nil {
+			return nil, &RowError{Row: r.row, Column: ColumnUpdatedAt, Err: err}
+		}
+	}
+
+	for col, v := range cells {
+		if !knownColumns[col] {
+			if rec.Extra == nil {
+				rec.Extra = map[string]string{}
+			}
+			rec.Extra[col] = v
+		}
+	}
+	return rec, nil
+}
+
+func validateLayout(columns []string) error {
+	seen := map[string]int{}
+	for _, c := range columns {
+		if c != "" {
+			seen[c]++
+		}
+	}
+	e := &LayoutError{}
+	for _, c := range RequiredColumns {
+		if seen[c] == 0 {
+			e.Missing = append(e.Missing, c)
+		}
+	}
+	for c, n := range seen {
+		if n > 1 {
+			e.Duplicate = append(e.Duplicate, c)
+		}
+	}
+	sort.Strings(e.Duplicate)
+	if len(e.Missing) > 0 || len(e.Duplicate) > 0 {
+		return e
+	}
+	return nil
+}
+
+// normaliseColumn lowercases name and replaces every non-alphanumeric run with "_".
+func normaliseColumn(name string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			underscore = false
+			continue
+		}
+		if !underscore && b.Len() > 0 {
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "_")
+}
+
+func isBlank(cells map[string]string) bool {
+	for _, v := range cells {
+		if v != "" {
+			return false
+		}
+	}
+	return true
+}
+
+// parseAmount accepts "1250.50", "1 250,50" and "1250".
+func parseAmount(s string) (money.Amount, error) {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsSpace(r) {
+			return -1
+		}
+		return r
+	}, s)
+	if s == "" {
+		return 0, errors.New("empty")
+	}
+	// The last '.' or ',' is the decimal separator unless it repeats ("1.250.000");
+	// other separators group thousands: "1,250.50", "1.250,50", "1'250.50".
+	dec := strings.LastIndexAny(s, ".,")
+	if dec >= 0 && strings.Count(s, s[dec:dec+1]) > 1 {
+		dec = -1
+	}
+	var b strings.Builder
+	for i, r := range s {
+		switch {
+		case i == dec:
+			b.WriteByte('.')
+		case r == '.' || r == ',' || r == '\'':
+		default:
+			b.WriteRune(r)
+		}
+	}
+	return money.Parse(b.String())
+}
+
+var timeLayouts = []string{
+	time.RFC3339Nano,
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"02.01.2006 15:04:05",
+	"02.01.2006 15:04",
+	"2006-01-02",
+	"02.01.2006",
+}
+
+// excelEpoch is day zero of spreadsheet date serials.
+var excelEpoch = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+
+func parseTime(s string, loc *time.Location) (time.Time, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return time.Time{}, errors.New("empty")
+	}
+	for _, layout := range timeLayouts {
+		if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+			return t, nil
+		}
+	}
+	// XLSX stores dates as a day count with the time as the fraction.
+	if f, err := strconv.ParseFloat(s, 64); err == nil && f > 0 {
+		t := excelEpoch.Add(time.Duration(f * float64(24*time.Hour))).Round(time.Second)
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), t.Minute(), t.Second(), 0, loc), nil
+	}
+	return time.Time{}, fmt.Errorf("unrecognised time %q", s)
+}
+
+func defaultLocation() *time.Location {
+	if loc, err := time.LoadLocation("Europe/Kyiv"); err == nil {
+		return loc
+	}
+	return time.UTC
+}
@@ -0,0 +1,163 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stremovskyy/go-nova/comfort"
+	"github.com/stremovskyy/go-nova/consts"
+	"github.com/stremovskyy/go-nova/money"
+)
+
+func checkRecords(t *testing.T, r *Reader) {
+	t.Helper()
+	recs, err := r.ReadAll()
+	if err != nil {
+		t.Fatalf("read all: %v", err)
+	}
+	if len(recs) != 2 {
+		t.Fatalf("expected 2 records, got %+v", recs)
+	}
+	first := recs[0]
+	if first.GUID != "g-1" || first.PublicID != "op-1" || first.Status != consts.OperationStatusSuccess || first.Amount != money.MustParse("1250.50") {
+		t.Fatalf("unexpected first record: %+v", first)
+	}
+	want := time.Date(2026, 10, 1, 12, 30, 0, 0, time.UTC)
+	if !first.CreatedAt.Equal(want) {
+		t.Fatalf("created_at = %s, want %s", first.CreatedAt, want)
+	}
+	if recs[1].Status != consts.OperationStatusFailed || recs[1].Amount != money.MustParse("10") {
+		t.Fatalf("unexpected second record: %+v", recs[1])
+	}
+}
+
+func TestReaderCSV(t *testing.T) {
+	data := "\ufeffGUID;Public ID;Status;Amount;Created At;Branch\n" +
+		"g-1;op-1;success;1 250,50;2026-10-01 12:30:00;Kyiv\n" +
+		";;;;;\n" +
+		"g-2;op-2;FAILED;10;01.10.2026 13:00;Lviv\n"
+	r, err := NewReader(strings.NewReader(data), comfort.ExportFormatCSV, WithLocation(time.UTC))
+	if err != nil {
+		t.Fatalf("new reader: %v", err)
+	}
+	checkRecords(t, r)
+}
+
+func TestReaderJSON(t *testing.T) {
+	data := `{"total": 2, "operations": [
+		{"guid": "g-1", "public_id": "op-1", "status": "success", "amount": 1250.5, "created_at": "2026-10-01T12:30:00Z"},
+		{"guid": "g-2", "public_id": "op-2", "status": "failed", "amount": "10.00", "created_at": "2026-10-01T13:00:00Z", "purpose": null}
+	]}`
+	r, err := NewReader(strings.NewReader(data), comfort.ExportFormatJSON)
+	if err != nil {
+		t.Fatalf("new reader: %v", err)
+	}
+	checkRecords(t, r)
+}
+
+func TestReaderXLSX(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	add := func(name, body string) {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatalf("zip: %v", err)
+		}
+		_, _ = w.Write([]byte(body))
+	}
+	add("xl/workbook.xml", `<workbook xmlns="http://schemas.openxmlformats.org/spreadsheetml/2006/main" xmlns:r="http://schemas.openxmlformats.org/officeDocument/2006/relationships"><sheets><sheet name="Operations" sheetId="1" r:id="rId1"/></sheets></workbook>`)
+	add("xl/_rels/workbook.xml.rels", `<Relationships xmlns="http://schemas.openxmlformats.org/package/2006/relationships"><Relationship Id="rId1" Target="worksheets/ops.xml"/></Relationships>`)
+	add("xl/sharedStrings.xml", `<sst><si><t>guid</t></si><si><t>public_id</t></si><si><t>status</t></si><si><t>amount</t></si><si><r><t>created</t></r><r><t>_at</t></r></si><si><t>success</t></si></sst>`)
+	add("xl/worksheets/ops.xml", `<worksheet><sheetData>
+		<row r="1"><c r="A1" t="s"><v>0</v></c><c r="B1" t="s"><v>1</v></c><c r="C1" t="s"><v>2</v></c><c r="D1" t="s"><v>3</v></c><c r="E1" t="s"><v>4</v></c></row>
+		<row r="2"><c r="A2" t="inlineStr"><is><t>g-1</t></is></c><c r="B2" t="str"><v>op-1</v></c><c r="C2" t="s"><v>5</v></c><c r="D2"><v>1250.5</v></c><c r="E2"><v>46296.520833333336</v></c></row>
+		<row r="3"><c r="A3" t="inlineStr"><is><t>g-2</t></is></c><c r="B3" t="inlineStr"><is><t>op-2</t></is></c><c r="C3" t="inlineStr"><is><t>failed</t></is></c><c r="D3"><v>10</v></c><c r="E3" t="inlineStr"><is><t>2026-10-01 13:00:00</t></is></c></row>
+	</sheetData></worksheet>`)
+	if err := zw.Close(); err != nil {
+		t.Fatalf("zip: %v", err)
+	}
+
+	r, err := NewReader(&buf, comfort.ExportFormatXLSX, WithLocation(time.UTC))
+	if err != nil {
+		t.Fatalf("new reader: %v", err)
+	}
+	checkRecords(t, r)
+}
+
+func TestXLSXCells(t *testing.T) {
+	s := &xlsxSource{}
+	cells, err := s.cells(xlsxRow{Cells: []xlsxCell{{Ref: "B2", Value: "b"}, {Value: "c"}, {Ref: "E2", Value: "e"}, {Value: "f"}}})
+	if err != nil || strings.Join(cells, ",") != ",b,c,,e,f" {
+		t.Fatalf("cells without a reference must follow the previous one, got %q, %v", cells, err)
+	}
+	if _, err := s.cells(xlsxRow{Cells: []xlsxCell{{Ref: "ZZZZZZZZ1", Value: "x"}}}); err == nil {
+		t.Fatal("a column beyond XFD must be rejected")
+	}
+	if col, err := columnIndex("XFD1"); err != nil || col != maxXLSXColumns-1 {
+		t.Fatalf("XFD: got %d, %v", col, err)
+	}
+}
+
+func TestParseAmount(t *testing.T) {
+	for in, want := range map[string]string{
+		"1250.50":       "1250.50",
+		"12,5":          "12.50",
+		"1 250,50":      "1250.50",
+		"1\u00a0250,50": "1250.50",
+		"1\u202f250,50": "1250.50",
+		"1,250.50":      "1250.50",
+		"1.250,50":      "1250.50",
+		"1'250.50":      "1250.50",
+		"1,250,000":     "1250000",
+		"-1,250.50":     "-1250.50",
+	} {
+		got, err := parseAmount(in)
+		if err != nil || got != money.MustParse(want) {
+			t.Fatalf("parseAmount(%q) = %s, %v; want %s", in, got, err, want)
+		}
+	}
+	for _, in := range []string{"", "abc", "1.2x"} {
+		if _, err := parseAmount(in); err == nil {
+			t.Fatalf("parseAmount(%q) must fail", in)
+		}
+	}
+}
+
+func TestReaderLayout(t *testing.T) {
+	_, err := NewReader(strings.NewReader("guid,status,status,amount\n"), comfort.ExportFormatCSV)
+	var le *LayoutError
+	if !errors.As(err, &le) {
+		t.Fatalf("expected LayoutError, got %v", err)
+	}
+	if strings.Join(le.Missing, ",") != "public_id,created_at" || strings.Join(le.Duplicate, ",") != "status" {
+		t.Fatalf("unexpected layout error: %+v", le)
+	}
+}
+
+func TestReaderRowError(t *testing.T) {
+	data := "guid,public_id,status,amount,created_at\n" +
+		"g-1,op-1,success,abc,2026-10-01\n" +
+		"g-2,op-2,success,5,2026-10-01\n"
+	r, err := NewReader(strings.NewReader(data), comfort.ExportFormatCSV)
+	if err != nil {
+		t.Fatalf("new reader: %v", err)
+	}
+
+	_, err = r.Next()
+	var re *RowError
+	if !errors.As(err, &re) || re.Row != 1 || re.Column != ColumnAmount {
+		t.Fatalf("expected amount RowError on row 1, got %v", err)
+	}
+	rec, err := r.Next()
+	if err != nil || rec.GUID != "g-2" {
+		t.Fatalf("expected to continue after a bad row, got %+v, %v", rec, err)
+	}
+	if _, err := r.Next(); !errors.Is(err, io.EOF) {
+		t.Fatalf("expected EOF, got %v", err)
+	}
+}
@@ -0,0 +1,127 @@
+package export
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// jsonSource streams an array of objects, or an object with the array under
+// "operations", "items" or "data".
+type jsonSource struct {
+	dec   *json.Decoder
+	cols  []string
+	first map[string]string
+	done  bool
+}
+
+func newJSONSource(r io.Reader) (*jsonSource, error) {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := openArray(dec); err != nil {
+		return nil, err
+	}
+
+	s := &jsonSource{dec: dec}
+	first, err := s.read()
+	if errors.Is(err, io.EOF) {
+		// An empty export has no layout to check.
+		s.cols = append([]string(nil), RequiredColumns...)
+		return s, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	// The layout is taken from the first object.
+	for c := range first {
+		s.cols = append(s.cols, c)
+	}
+	s.first = first
+	return s, nil
+}
+
+func openArray(dec *json.Decoder) error {
+	tok, err := dec.Token()
+	if err != nil {
+		return fmt.Errorf("export: read json: %w", err)
+	}
+	if tok == json.Delim('[') {
+		return nil
+	}
+	if tok != json.Delim('{') {
+		return errors.New("export: json export must be an array of operations")
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return fmt.Errorf("export: read json: %w", err)
+		}
+		switch key {
+		case "operations", "items", "data":
+			tok, err := dec.Token()
+			if err != nil {
+				return fmt.Errorf("export: read json: %w", err)
+			}
+			if tok != json.Delim('[') {
+				return fmt.Errorf("export: json %q must be an array", key)
+			}
+			return nil
+		}
+		var skip json.RawMessage
+		if err := dec.Decode(&skip); err != nil {
+			return fmt.Errorf("export: read json: %w", err)
+		}
+	}
+	return errors.New("export: json export has no operations array")
+}
+
+func (s *jsonSource) columns() []string { return s.cols }
+
+func (s *jsonSource) next() (map[string]string, error) {
+	if s.first != nil {
+		row := s.first
+		s.first = nil
+		return row, nil
+	}
+	row, err := s.read()
+	if err != nil {
+		return nil, err
+	}
+	for _, c := range s.cols {
+		if _, ok := row[c]; !ok {
+			row[c] = ""
+		}
+	}
+	return row, nil
+}
+
+func (s *jsonSource) read() (map[string]string, error) {
+	if s.done || !s.dec.More() {
+		s.done = true
+		return nil, io.EOF
+	}
+	var obj map[string]json.RawMessage
+	if err := s.dec.Decode(&obj); err != nil {
+		s.done = true
+		return nil, fmt.Errorf("export: read json: %w", err)
+	}
+	row := make(map[string]string, len(obj))
+	for k, v := range obj {
+		row[normaliseColumn(k)] = jsonText(v)
+	}
+	return row, nil
+}
+
+// jsonText returns strings and numbers as text, null as "" and anything else as raw JSON.
+func jsonText(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return strings.TrimSpace(s)
+	}
+	if string(raw) == "null" {
+		return ""
+	}
+	return string(raw)
+}
@@ -0,0 +1,271 @@
+package export
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+)
+
+// xlsxSource reads the first worksheet of an XLSX workbook. Rows are decoded one
+// at a time from the sheet XML; only shared strings are loaded up front.
+type xlsxSource struct {
+	sheet   io.ReadCloser
+	dec     *xml.Decoder
+	strings []string
+	cols    []string
+	done    bool
+}
+
+func newXLSXSource(r io.Reader) (*xlsxSource, error) {
+	b, err := io.ReadAll(r)
+	if err != nil {
+		return nil, fmt.Errorf("export: read xlsx: %w", err)
+	}
+	zr, err := zip.NewReader(bytes.NewReader(b), int64(len(b)))
+	if err != nil {
+		return nil, fmt.Errorf("export: open xlsx: %w", err)
+	}
+	files := make(map[string]*zip.File, len(zr.File))
+	for _, f := range zr.File {
+		files[f.Name] = f
+	}
+
+	shared, err := readSharedStrings(files["xl/sharedStrings.xml"])
+	if err != nil {
+		return nil, err
+	}
+	sheetPath, err := firstSheetPath(files)
+	if err != nil {
+		return nil, err
+	}
+	f, ok := files[sheetPath]
+	if !ok {
+		return nil, fmt.Errorf("export: xlsx worksheet %s not found", sheetPath)
+	}
+	rc, err := f.Open()
+	if err != nil {
+		return nil, fmt.Errorf("export: open xlsx worksheet: %w", err)
+	}
+
+	s := &xlsxSource{sheet: rc, dec: xml.NewDecoder(rc), strings: shared}
+	header, err := s.readRow()
+	if errors.Is(err, io.EOF) {
+		return nil, errors.New("export: xlsx worksheet is empty")
+	}
+	if err != nil {
+		return nil, err
+	}
+	s.cols = make([]string, len(header))
+	for i, h := range header {
+		s.cols[i] = normaliseColumn(h)
+	}
+	return s, nil
+}
+
+func (s *xlsxSource) columns() []string { return s.cols }
+
+func (s *xlsxSource) next() (map[string]string, error) {
+	cells, err := s.readRow()
+	if err != nil {
+		return nil, err
+	}
+	return zipRow(s.cols, cells), nil
+}
+
+// readRow returns the cells of the next <row>, with gaps for skipped columns.
+func (s *xlsxSource) readRow() ([]string, error) {
+	if s.done {
+		return nil, io.EOF
+	}
+	for {
+		tok, err := s.dec.Token()
+		if errors.Is(err, io.EOF) {
+			s.close()
+			return nil, io.EOF
+		}
+		if err != nil {
+			s.close()
+			return nil, fmt.Errorf("export: read xlsx worksheet: %w", err)
+		}
+		if se, ok := tok.(xml.StartElement); ok && se.Name.Local == "row" {
+			var row xlsxRow
+			if err := s.dec.DecodeElement(&row, &se); err != nil {
+				s.close()
+				return nil, fmt.Errorf("export: read xlsx row: %w", err)
+			}
+			return s.cells(row)
+		}
+	}
+}
+
+func (s *xlsxSource) cells(row xlsxRow) ([]string, error) {
+	var out []string
+	col := -1
+	for _, c := range row.Cells {
+		// A cell without a reference follows the previous one.
+		col++
+		if c.Ref != "" {
+			idx, err := columnIndex(c.Ref)
+			if err != nil {
+				return nil, fmt.Errorf("export: xlsx cell %q: %w", c.Ref, err)
+			}
+			col = idx
+		}
+		for len(out) <= col {
+			out = append(out, "")
+		}
+
+		switch c.Type {
+		case "s":
+			n, err := strconv.Atoi(strings.TrimSpace(c.Value))
+			if err != nil || n < 0 || n >= len(s.strings) {
+				return nil, fmt.Errorf("export: xlsx cell %q: bad shared string index %q", c.Ref, c.Value)
+			}
+			out[col] = s.strings[n]
+		case "inlineStr":
+			out[col] = c.Inline.text()
+		default:
+			out[col] = c.Value
+		}
+	}
+	return out, nil
+}
+
+func (s *xlsxSource) close() {
+	s.done = true
+	_ = s.sheet.Close()
+}
+
+type xlsxRow struct {
+	Cells []xlsxCell `xml:"c"`
+}
+
+type xlsxCell struct {
+	Ref    string     `xml:"r,attr"`
+	Type   string     `xml:"t,attr"`
+	Value  string     `xml:"v"`
+	Inline xlsxString `xml:"is"`
+}
+
+// xlsxString is a shared or inline string: plain <t> or rich-text runs <r><t>.
+type xlsxString struct {
+	T    string `xml:"t"`
+	Runs []struct {
+		T string `xml:"t"`
+	} `xml:"r"`
+}
+
+func (s xlsxString) text() string {
+	if len(s.Runs) == 0 {
+		return s.T
+	}
+	var b strings.Builder
+	b.WriteString(s.T)
+	for _, r := range s.Runs {
+		b.WriteString(r.T)
+	}
+	return b.String()
+}
+
+func readSharedStrings(f *zip.File) ([]string, error) {
+	if f == nil {
+		return nil, nil
+	}
+	var sst struct {
+		Items []xlsxString `xml:"si"`
+	}
+	if err := decodeZipXML(f, &sst); err != nil {
+		return nil, err
+	}
+	out := make([]string, len(sst.Items))
+	for i, si := range sst.Items {
+		out[i] = si.text()
+	}
+	return out, nil
+}
+
+// firstSheetPath resolves the first sheet of xl/workbook.xml through its relationships.
+func firstSheetPath(files map[string]*zip.File) (string, error) {
+	const fallback = "xl/worksheets/sheet1.xml"
+
+	wb, rels := files["xl/workbook.xml"], files["xl/_rels/workbook.xml.rels"]
+	if wb == nil || rels == nil {
+		return fallback, nil
+	}
+	var workbook struct {
+		Sheets []struct {
+			ID string `xml:"http://schemas.openxmlformats.org/officeDocument/2006/relationships id,attr"`
+		} `xml:"sheets>sheet"`
+	}
+	if err := decodeZipXML(wb, &workbook); err != nil {
+		return "", err
+	}
+	var relationships struct {
+		Items []struct {
+			ID     string `xml:"Id,attr"`
+			Target string `xml:"Target,attr"`
+		} `xml:"Relationship"`
+	}
+	if err := decodeZipXML(rels, &relationships); err != nil {
+		return "", err
+	}
+	if len(workbook.Sheets) == 0 {
+		return "", errors.New("export: xlsx workbook has no sheets")
+	}
+	for _, rel := range relationships.Items {
+		if rel.ID != workbook.Sheets[0].ID {
+			continue
+		}
+		if strings.HasPrefix(rel.Target, "/") {
+			return strings.TrimPrefix(rel.Target, "/"), nil
+		}
+		return path.Join("xl", rel.Target), nil
+	}
+	return fallback, nil
+}
+
+func decodeZipXML(f *zip.File, v any) error {
+	rc, err := f.Open()
+	if err != nil {
+		return fmt.Errorf("export: open xlsx %s: %w", f.Name, err)
+	}
+	defer rc.Close()
+	if err := xml.NewDecoder(rc).Decode(v); err != nil {
+		return fmt.Errorf("export: decode xlsx %s: %w", f.Name, err)
+	}
+	return nil
+}
+
+// maxXLSXColumns is the number of worksheet columns, A to XFD.
+const maxXLSXColumns = 16384
+
+// columnIndex converts the letters of a cell reference ("C7") to a 0-based column.
+func columnIndex(ref string) (int, error) {
+	n := 0
+	for _, r := range ref {
+		switch {
+		case r >= 'A' && r <= 'Z':
+			n = n*26 + int(r-'A'+1)
+		case r >= 'a' && r <= 'z':
+			n = n*26 + int(r-'a'+1)
+		default:
+			if n == 0 {
+				return 0, errors.New("no column letters")
+			}
+			return n - 1, nil
+		}
+		if n > maxXLSXColumns {
+			return 0, errors.New("column is beyond XFD")
+		}
+	}
+	if n == 0 {
+		return 0, errors.New("no column letters")
+	}
+	return n - 1, nil
+}
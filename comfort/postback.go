@@ -0,0 +1,25 @@
+package comfort
+
+import (
+	"github.com/stremovskyy/go-nova/consts"
+	"github.com/stremovskyy/go-nova/money"
+)
+
+// Postback is the Comfort payout status callback.
+//
+// It is signed like Comfort requests (x-sign, SHA-1 by default) and describes one
+// payout operation identified by GUID and PublicID.
+type Postback struct {
+	GUID      string                 `json:"guid"`
+	PublicID  string                 `json:"public_id"`
+	Status    consts.OperationStatus `json:"status"`
+	Amount    money.StringAmount     `json:"amount"`
+	Purpose   *string                `json:"purpose,omitempty"`
+	PayoutPAN *string                `json:"payout_pan,omitempty"`
+	Recipient *Recipient             `json:"recipient,omitempty"`
+
+	ErrorCode    *string `json:"error_code,omitempty"`
+	ErrorMessage *string `json:"error_message,omitempty"`
+	CreatedAt    string  `json:"created_at,omitempty"`
+	UpdatedAt    string  `json:"updated_at,omitempty"`
+}
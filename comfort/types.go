@@ -1,13 +1,18 @@
 package comfort
 
+import (
+	"github.com/stremovskyy/go-nova/consts"
+	"github.com/stremovskyy/go-nova/money"
+)
+
 // CreateOperationItem is one payout item for POST /v1/operations/create.
 type CreateOperationItem struct {
-	GUID                 *string    `json:"guid,omitempty"`
-	Amount               string     `json:"amount"`
-	Purpose              *string    `json:"purpose,omitempty"`
-	PayoutPAN            *string    `json:"payout_pan,omitempty"`
-	RefundOnFailedPayout *bool      `json:"refund_on_failed_payout,omitempty"`
-	Recipient            *Recipient `json:"recipient,omitempty"`
+	GUID                 *string            `json:"guid,omitempty"`
+	Amount               money.StringAmount `json:"amount"`
+	Purpose              *string            `json:"purpose,omitempty"`
+	PayoutPAN            *string            `json:"payout_pan,omitempty"`
+	RefundOnFailedPayout *bool              `json:"refund_on_failed_payout,omitempty"`
+	Recipient            *Recipient         `json:"recipient,omitempty"`
 }
 
 type Recipient struct {
@@ -44,8 +49,8 @@ type OperationsStatusRequest struct {
 }
 
 type OperationsStatusResponse struct {
-	Status   string `json:"status"`
-	PublicID string `json:"public_id"`
+	Status   consts.OperationStatus `json:"status"`
+	PublicID string                 `json:"public_id"`
 }
 
 // ChangeRecipientDataRequest corresponds to POST /v1/operations/change-recipient-data.
@@ -87,5 +92,5 @@ type ExportOperationsResponse struct {
 }
 
 type BalanceResponse struct {
-	Balance string `json:"balance"`
+	Balance money.StringAmount `json:"balance"`
 }
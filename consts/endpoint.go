@@ -0,0 +1,65 @@
+package consts
+
+import "net/http"
+
+// RetrySafety tells whether an endpoint may be called again when the outcome of a
+// call is unknown, e.g. after the connection broke while waiting for the response.
+type RetrySafety int
+
+const (
+	// RetryUnsafe endpoints move money or change a session; a repeat may do it twice.
+	RetryUnsafe RetrySafety = iota
+	// RetryIdempotentWithKey endpoints carry a client key, such as an operation GUID,
+	// under which NovaPay applies a repeated request only once.
+	RetryIdempotentWithKey
+	// RetrySafe endpoints only read data.
+	RetrySafe
+)
+
+func (s RetrySafety) String() string {
+	switch s {
+	case RetryUnsafe:
+		return "unsafe"
+	case RetryIdempotentWithKey:
+		return "idempotent_with_key"
+	case RetrySafe:
+		return "safe"
+	default:
+		return "unknown"
+	}
+}
+
+// EndpointSafety classifies the endpoint at path (one of the paths above).
+//
+// The Checkout void, expire and get-status endpoints share their acquiring paths
+// and class. Comfort CreateOperations and ChangeRecipientData are RetryUnsafe:
+// NovaPay is not known to dedup them by GUID, so a create is only repeated after
+// WithPayoutReconcile found nothing. No endpoint is RetryIdempotentWithKey yet.
+// Unknown paths are RetrySafe for GET and HEAD and RetryUnsafe otherwise.
+func EndpointSafety(method, path string) RetrySafety {
+	switch path {
+	case AcquiringGetStatusPath,
+		AcquiringDeliveryPricePath,
+		AcquiringPrintExpressWaybillPath,
+		ComfortOperationsStatusPath,
+		ComfortBalancePath:
+		return RetrySafe
+	case AcquiringCreateSessionPath,
+		AcquiringAddPaymentPath,
+		AcquiringVoidSessionPath,
+		AcquiringCompleteHoldPath,
+		AcquiringExpireSessionPath,
+		AcquiringConfirmDeliveryPath,
+		CheckoutCreateSessionPath,
+		CheckoutAddPaymentPath,
+		ComfortCreateOperationsPath,
+		ComfortChangeRecipientDataPath,
+		ComfortRefundOperationsPath,
+		ComfortExportOperationsPath:
+		return RetryUnsafe
+	}
+	if method == http.MethodGet || method == http.MethodHead {
+		return RetrySafe
+	}
+	return RetryUnsafe
+}
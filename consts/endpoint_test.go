@@ -0,0 +1,27 @@
+package consts
+
+import "testing"
+
+func TestEndpointSafety(t *testing.T) {
+	cases := map[string]RetrySafety{
+		AcquiringGetStatusPath:         RetrySafe,
+		ComfortBalancePath:             RetrySafe,
+		ComfortCreateOperationsPath:    RetryUnsafe,
+		ComfortChangeRecipientDataPath: RetryUnsafe,
+		AcquiringAddPaymentPath:        RetryUnsafe,
+		AcquiringCompleteHoldPath:      RetryUnsafe,
+		AcquiringVoidSessionPath:       RetryUnsafe,
+		CheckoutAddPaymentPath:         RetryUnsafe,
+	}
+	for path, want := range cases {
+		if got := EndpointSafety("POST", path); got != want {
+			t.Fatalf("%s: got %s, want %s", path, got, want)
+		}
+	}
+	if got := EndpointSafety("GET", "/v1/unknown"); got != RetrySafe {
+		t.Fatalf("unknown GET: got %s", got)
+	}
+	if got := EndpointSafety("POST", "/v1/unknown"); got != RetryUnsafe {
+		t.Fatalf("unknown POST: got %s", got)
+	}
+}
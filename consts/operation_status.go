@@ -0,0 +1,36 @@
+package consts
+
+// OperationStatus is the status of a Comfort payout operation.
+type OperationStatus string
+
+const (
+	OperationStatusCreated    OperationStatus = "created"
+	OperationStatusProcessing OperationStatus = "processing"
+	OperationStatusSuccess    OperationStatus = "success"
+	OperationStatusFailed     OperationStatus = "failed"
+	OperationStatusRefunded   OperationStatus = "refunded"
+)
+
+// IsKnown reports whether s is one of the known operation statuses.
+func (s OperationStatus) IsKnown() bool {
+	switch s {
+	case OperationStatusCreated,
+		OperationStatusProcessing,
+		OperationStatusSuccess,
+		OperationStatusFailed,
+		OperationStatusRefunded:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsFinal reports whether the payout is finished: paid out, failed or refunded.
+func (s OperationStatus) IsFinal() bool {
+	switch s {
+	case OperationStatusSuccess, OperationStatusFailed, OperationStatusRefunded:
+		return true
+	default:
+		return false
+	}
+}
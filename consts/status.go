@@ -17,3 +17,90 @@ const (
 	SessionStatusProcessingVoid           SessionStatus = "processing_void"
 	SessionStatusVoided                   SessionStatus = "voided"
 )
+
+// IsKnown reports whether s is one of the documented session statuses.
+func (s SessionStatus) IsKnown() bool {
+	switch s {
+	case SessionStatusCreated,
+		SessionStatusExpired,
+		SessionStatusProcessing,
+		SessionStatusHolded,
+		SessionStatusHoldConfirmed,
+		SessionStatusProcessingHoldCompletion,
+		SessionStatusPaid,
+		SessionStatusFailed,
+		SessionStatusProcessingVoid,
+		SessionStatusVoided:
+		return true
+	default:
+		return false
+	}
+}
+
+// sessionTransitions lists the legal status changes of a session.
+//
+// The happy paths are created → processing → paid and
+// created → processing → holded → processing_hold_completion → hold_confirmed → paid,
+// with voided, failed and expired branches.
+var sessionTransitions = map[SessionStatus][]SessionStatus{
+	SessionStatusCreated:                  {SessionStatusProcessing, SessionStatusExpired},
+	SessionStatusProcessing:               {SessionStatusHolded, SessionStatusPaid, SessionStatusFailed},
+	SessionStatusHolded:                   {SessionStatusProcessingHoldCompletion, SessionStatusHoldConfirmed, SessionStatusProcessingVoid, SessionStatusVoided},
+	SessionStatusProcessingHoldCompletion: {SessionStatusHoldConfirmed, SessionStatusHolded, SessionStatusFailed},
+	SessionStatusHoldConfirmed:            {SessionStatusPaid, SessionStatusProcessingVoid, SessionStatusVoided},
+	SessionStatusPaid:                     {SessionStatusProcessingVoid, SessionStatusVoided},
+	SessionStatusProcessingVoid:           {SessionStatusVoided, SessionStatusFailed},
+}
+
+// NextStatuses returns the statuses a session in status s may move to.
+func (s SessionStatus) NextStatuses() []SessionStatus {
+	next := sessionTransitions[s]
+	out := make([]SessionStatus, len(next))
+	copy(out, next)
+	return out
+}
+
+// CanTransitionTo reports whether a session may move from s to next.
+func (s SessionStatus) CanTransitionTo(next SessionStatus) bool {
+	for _, st := range sessionTransitions[s] {
+		if st == next {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTerminal reports whether no further status change is possible.
+func (s SessionStatus) IsTerminal() bool {
+	return s.IsKnown() && len(sessionTransitions[s]) == 0
+}
+
+// IsProcessing reports whether NovaPay is still working on the last operation.
+func (s SessionStatus) IsProcessing() bool {
+	switch s {
+	case SessionStatusProcessing, SessionStatusProcessingHoldCompletion, SessionStatusProcessingVoid:
+		return true
+	default:
+		return false
+	}
+}
+
+// IsHeld reports whether funds are blocked on the customer card and not yet charged.
+func (s SessionStatus) IsHeld() bool {
+	return s == SessionStatusHolded || s == SessionStatusProcessingHoldCompletion
+}
+
+// CanVoid reports whether VoidSession is allowed in status s.
+func (s SessionStatus) CanVoid() bool {
+	return s.CanTransitionTo(SessionStatusProcessingVoid)
+}
+
+// CanCompleteHold reports whether CompleteHold (or ConfirmDeliveryHold) is allowed in status s.
+func (s SessionStatus) CanCompleteHold() bool {
+	return s == SessionStatusHolded
+}
+
+// CanExpire reports whether ExpireSession is allowed in status s.
+func (s SessionStatus) CanExpire() bool {
+	return s.CanTransitionTo(SessionStatusExpired)
+}
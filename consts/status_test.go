@@ -0,0 +1,77 @@
+package consts
+
+import "testing"
+
+func TestSessionStatusTransitions(t *testing.T) {
+	path := []SessionStatus{
+		SessionStatusCreated,
+		SessionStatusProcessing,
+		SessionStatusHolded,
+		SessionStatusProcessingHoldCompletion,
+		SessionStatusHoldConfirmed,
+		SessionStatusPaid,
+		SessionStatusProcessingVoid,
+		SessionStatusVoided,
+	}
+	for i := 1; i < len(path); i++ {
+		if !path[i-1].CanTransitionTo(path[i]) {
+			t.Fatalf("expected %s -> %s to be legal", path[i-1], path[i])
+		}
+	}
+
+	if SessionStatusPaid.CanTransitionTo(SessionStatusHolded) {
+		t.Fatalf("paid -> holded must be illegal")
+	}
+	if SessionStatusVoided.CanTransitionTo(SessionStatusPaid) {
+		t.Fatalf("voided -> paid must be illegal")
+	}
+}
+
+func TestSessionStatusPredicates(t *testing.T) {
+	tests := []struct {
+		status                                       SessionStatus
+		terminal, held, canVoid, canComplete, expire bool
+	}{
+		{status: SessionStatusCreated, expire: true},
+		{status: SessionStatusProcessing},
+		{status: SessionStatusHolded, held: true, canVoid: true, canComplete: true},
+		{status: SessionStatusProcessingHoldCompletion, held: true},
+		{status: SessionStatusHoldConfirmed, canVoid: true},
+		{status: SessionStatusPaid, canVoid: true},
+		{status: SessionStatusProcessingVoid},
+		{status: SessionStatusVoided, terminal: true},
+		{status: SessionStatusFailed, terminal: true},
+		{status: SessionStatusExpired, terminal: true},
+		{status: SessionStatus("unknown")},
+	}
+	for _, tc := range tests {
+		if got := tc.status.IsTerminal(); got != tc.terminal {
+			t.Errorf("%s.IsTerminal() = %v", tc.status, got)
+		}
+		if got := tc.status.IsHeld(); got != tc.held {
+			t.Errorf("%s.IsHeld() = %v", tc.status, got)
+		}
+		if got := tc.status.CanVoid(); got != tc.canVoid {
+			t.Errorf("%s.CanVoid() = %v", tc.status, got)
+		}
+		if got := tc.status.CanCompleteHold(); got != tc.canComplete {
+			t.Errorf("%s.CanCompleteHold() = %v", tc.status, got)
+		}
+		if got := tc.status.CanExpire(); got != tc.expire {
+			t.Errorf("%s.CanExpire() = %v", tc.status, got)
+		}
+	}
+}
+
+func TestOperationStatusIsFinal(t *testing.T) {
+	for _, s := range []OperationStatus{OperationStatusSuccess, OperationStatusFailed, OperationStatusRefunded} {
+		if !s.IsFinal() {
+			t.Fatalf("%s must be final", s)
+		}
+	}
+	for _, s := range []OperationStatus{OperationStatusCreated, OperationStatusProcessing, "unknown"} {
+		if s.IsFinal() {
+			t.Fatalf("%s must not be final", s)
+		}
+	}
+}
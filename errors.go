@@ -1,8 +1,15 @@
 package go_nova
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/stremovskyy/go-nova/consts"
+	"github.com/stremovskyy/go-nova/internal/httpclient"
 )
 
 // ValidationError indicates that a request is missing required fields or contains invalid data.
@@ -43,16 +50,78 @@ func IsValidationError(err error) bool {
 	return errors.As(err, &ve)
 }
 
+// SessionStatusError indicates that an operation is not allowed in the session's last known status.
+//
+// It is returned before any request is sent; see WithKnownStatus.
+type SessionStatusError struct {
+	Operation string
+	Status    consts.SessionStatus
+}
+
+func (e *SessionStatusError) Error() string {
+	if e == nil {
+		return "session status error"
+	}
+	return fmt.Sprintf("cannot %s session in status %q", e.Operation, e.Status)
+}
+
+// IsSessionStatusError checks whether err is a *SessionStatusError.
+func IsSessionStatusError(err error) bool {
+	var se *SessionStatusError
+	return errors.As(err, &se)
+}
+
+// Sentinel errors matched by *APIError via errors.Is.
+//
+// They are recognised from the exact code of the NovaPay error envelope or, when it has
+// no code, from a few exact messages such as "Invalid signature".
+var (
+	ErrSessionNotFound      = errors.New("novapay: session not found")
+	ErrOperationNotFound    = errors.New("novapay: operation not found")
+	ErrInvalidSignature     = errors.New("novapay: invalid signature")
+	ErrInsufficientFunds    = errors.New("novapay: insufficient funds")
+	ErrAlreadyVoided        = errors.New("novapay: session already voided")
+	ErrInvalidSessionStatus = errors.New("novapay: operation not allowed in session status")
+)
+
+// ErrOutcomeUnknown is matched by *OutcomeUnknownError: a call to an unsafe endpoint
+// (see consts.EndpointSafety) failed after NovaPay may have executed it, e.g. the
+// connection broke while waiting for the response or NovaPay answered 5xx. Such
+// calls are never retried automatically; check the result with GetStatus before
+// trying again.
+var ErrOutcomeUnknown = httpclient.ErrOutcomeUnknown
+
+// OutcomeUnknownError wraps the failure of such a call, a transport error or an
+// *APIError, so errors.As still finds it.
+type OutcomeUnknownError = httpclient.OutcomeUnknownError
+
 // APIError represents a non-2xx response from NovaPay.
+//
+// Code, Message, Type and Details are filled from the error envelope when the body
+// contains one; Body always keeps the raw response.
 type APIError struct {
 	StatusCode int
 	Body       []byte
+
+	Code    string
+	Message string
+	Type    string
+	Details []FieldError
 }
 
 func (e *APIError) Error() string {
 	if e == nil {
 		return "novapay api error"
 	}
+	if e.Code != "" || e.Message != "" {
+		msg := e.Message
+		if e.Code != "" && msg != "" {
+			msg = e.Code + ": " + msg
+		} else if e.Code != "" {
+			msg = e.Code
+		}
+		return fmt.Sprintf("novapay api error: status %d: %s", e.StatusCode, msg)
+	}
 	if len(e.Body) == 0 {
 		return fmt.Sprintf("novapay api error: status %d", e.StatusCode)
 	}
@@ -62,3 +131,177 @@ func (e *APIError) Error() string {
 	}
 	return fmt.Sprintf("novapay api error: status %d: %s", e.StatusCode, string(b))
 }
+
+// Is reports whether e matches one of the sentinel errors above.
+func (e *APIError) Is(target error) bool {
+	if e == nil {
+		return false
+	}
+	m, ok := apiErrorMatchers[target]
+	if !ok {
+		return false
+	}
+	if code := normalizeErrorText(e.Code); code != "" {
+		return m.codes[code]
+	}
+	return m.messages[normalizeErrorText(e.Message)]
+}
+
+// apiErrorMatcher lists the normalized error codes of a sentinel and the exact
+// messages that identify it when the envelope has no code.
+type apiErrorMatcher struct {
+	codes    map[string]bool
+	messages map[string]bool
+}
+
+func matchTexts(texts ...string) map[string]bool {
+	out := make(map[string]bool, len(texts))
+	for _, t := range texts {
+		out[t] = true
+	}
+	return out
+}
+
+var apiErrorMatchers = map[error]apiErrorMatcher{
+	ErrSessionNotFound: {
+		codes:    matchTexts("session_not_found"),
+		messages: matchTexts("session_not_found"),
+	},
+	ErrOperationNotFound: {
+		codes:    matchTexts("operation_not_found"),
+		messages: matchTexts("operation_not_found"),
+	},
+	ErrInvalidSignature: {
+		codes:    matchTexts("invalid_signature", "invalid_sign", "invalid_x_sign"),
+		messages: matchTexts("invalid_signature", "invalid_x_sign"),
+	},
+	ErrInsufficientFunds: {
+		codes:    matchTexts("insufficient_funds"),
+		messages: matchTexts("insufficient_funds", "insufficient_funds_on_balance"),
+	},
+	ErrAlreadyVoided: {
+		codes:    matchTexts("session_already_voided", "already_voided"),
+		messages: matchTexts("session_already_voided", "session_is_already_voided"),
+	},
+	ErrInvalidSessionStatus: {
+		codes:    matchTexts("invalid_session_status", "invalid_status"),
+		messages: matchTexts("invalid_session_status"),
+	},
+}
+
+// normalizeErrorText lowercases s and replaces every non-alphanumeric run with "_".
+func normalizeErrorText(s string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(strings.TrimSpace(s)) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+			underscore = false
+			continue
+		}
+		if !underscore && b.Len() > 0 {
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+	return strings.TrimSuffix(b.String(), "_")
+}
+
+// newAPIError builds an APIError and decodes the NovaPay error envelope if present.
+//
+// Accepted shapes:
+//
+//	{"code": "...", "message": "...", "type": "...", "errors": [{"field": "...", "message": "..."}]}
+//	{"error": {"code": ..., "message": ...}}
+//	{"error": "message"}
+//
+// Details may also be an object of field → message(s) under "errors", "details" or "fields".
+func newAPIError(statusCode int, body []byte) *APIError {
+	e := &APIError{StatusCode: statusCode, Body: body}
+
+	var top map[string]json.RawMessage
+	if err := json.Unmarshal(body, &top); err != nil {
+		return e
+	}
+	if nested, ok := top["error"]; ok {
+		var inner map[string]json.RawMessage
+		if err := json.Unmarshal(nested, &inner); err == nil {
+			top = inner
+		} else {
+			e.Message = rawString(nested)
+		}
+	}
+
+	e.Code = firstRawString(top, "code", "error_code", "errorCode")
+	if e.Message == "" {
+		e.Message = firstRawString(top, "message", "error_message", "errorMessage", "description", "detail")
+	}
+	e.Type = firstRawString(top, "type", "error_type", "errorType")
+	for _, key := range []string{"errors", "details", "fields"} {
+		if raw, ok := top[key]; ok {
+			e.Details = append(e.Details, decodeErrorDetails(raw)...)
+		}
+	}
+	return e
+}
+
+func firstRawString(m map[string]json.RawMessage, keys ...string) string {
+	for _, k := range keys {
+		if raw, ok := m[k]; ok {
+			if s := rawString(raw); s != "" {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// rawString returns a JSON string or number as text.
+func rawString(raw json.RawMessage) string {
+	var s string
+	if err := json.Unmarshal(raw, &s); err == nil {
+		return s
+	}
+	var n json.Number
+	if err := json.Unmarshal(raw, &n); err == nil {
+		return n.String()
+	}
+	return ""
+}
+
+func decodeErrorDetails(raw json.RawMessage) []FieldError {
+	var list []map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &list); err == nil {
+		out := make([]FieldError, 0, len(list))
+		for _, item := range list {
+			out = append(out, FieldError{
+				Field:   firstRawString(item, "field", "name", "path", "loc"),
+				Message: firstRawString(item, "message", "msg", "error", "description"),
+			})
+		}
+		return out
+	}
+
+	var byField map[string]json.RawMessage
+	if err := json.Unmarshal(raw, &byField); err != nil {
+		return nil
+	}
+	fields := make([]string, 0, len(byField))
+	for f := range byField {
+		fields = append(fields, f)
+	}
+	sort.Strings(fields)
+
+	var out []FieldError
+	for _, f := range fields {
+		var msgs []string
+		if err := json.Unmarshal(byField[f], &msgs); err == nil {
+			for _, m := range msgs {
+				out = append(out, FieldError{Field: f, Message: m})
+			}
+			continue
+		}
+		out = append(out, FieldError{Field: f, Message: rawString(byField[f])})
+	}
+	return out
+}
@@ -0,0 +1,92 @@
+package go_nova
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+)
+
+func TestNewAPIErrorDecodesEnvelope(t *testing.T) {
+	tests := []struct {
+		name     string
+		body     string
+		code     string
+		message  string
+		typ      string
+		details  []FieldError
+		sentinel error
+	}{
+		{
+			name:     "flat",
+			body:     `{"code":"session_not_found","message":"session \"s-1\" not found"}`,
+			code:     "session_not_found",
+			message:  `session "s-1" not found`,
+			sentinel: ErrSessionNotFound,
+		},
+		{
+			name:     "nested with details",
+			body:     `{"error":{"code":422,"message":"Validation failed","type":"validation","errors":[{"field":"amount","message":"must be > 0"}]}}`,
+			code:     "422",
+			message:  "Validation failed",
+			typ:      "validation",
+			details:  []FieldError{{Field: "amount", Message: "must be > 0"}},
+			sentinel: nil,
+		},
+		{
+			name:     "error string",
+			body:     `{"error":"Invalid signature"}`,
+			message:  "Invalid signature",
+			sentinel: ErrInvalidSignature,
+		},
+		{
+			name:     "details by field",
+			body:     `{"message":"Insufficient funds on balance","details":{"RAW_BODY[0].amount":["too large"]}}`,
+			message:  "Insufficient funds on balance",
+			details:  []FieldError{{Field: "RAW_BODY[0].amount", Message: "too large"}},
+			sentinel: ErrInsufficientFunds,
+		},
+		{
+			name:     "already voided code",
+			body:     `{"code":"SESSION_ALREADY_VOIDED"}`,
+			code:     "SESSION_ALREADY_VOIDED",
+			sentinel: ErrAlreadyVoided,
+		},
+		{
+			name:    "unrelated message",
+			body:    `{"message":"signature of the card holder is missing, insufficient data"}`,
+			message: "signature of the card holder is missing, insufficient data",
+		},
+		{
+			name:    "unrelated code",
+			body:    `{"code":"recipient_not_found","message":"Invalid signature"}`,
+			code:    "recipient_not_found",
+			message: "Invalid signature",
+		},
+		{
+			name: "not json",
+			body: `<html>Bad Gateway</html>`,
+		},
+	}
+
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			e := newAPIError(400, []byte(tc.body))
+			if e.Code != tc.code || e.Message != tc.message || e.Type != tc.typ {
+				t.Fatalf("unexpected envelope: code=%q message=%q type=%q", e.Code, e.Message, e.Type)
+			}
+			if fmt.Sprint(e.Details) != fmt.Sprint(tc.details) {
+				t.Fatalf("unexpected details: %+v", e.Details)
+			}
+			if string(e.Body) != tc.body {
+				t.Fatalf("raw body must be preserved")
+			}
+
+			var err error = fmt.Errorf("call: %w", e)
+			for _, s := range []error{ErrSessionNotFound, ErrOperationNotFound, ErrInvalidSignature, ErrInsufficientFunds, ErrAlreadyVoided, ErrInvalidSessionStatus} {
+				if got, want := errors.Is(err, s), s == tc.sentinel; got != want {
+					t.Fatalf("errors.Is(err, %v) = %v, want %v", s, got, want)
+				}
+			}
+		})
+	}
+}
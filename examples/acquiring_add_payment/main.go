@@ -10,8 +10,9 @@ import (
 
 	go_nova "github.com/stremovskyy/go-nova"
 	"github.com/stremovskyy/go-nova/acquiring"
-	"github.com/stremovskyy/go-nova/examples/internal/dotenv"
+	"github.com/stremovskyy/go-nova/internal/dotenv"
 	"github.com/stremovskyy/go-nova/internal/utils"
+	"github.com/stremovskyy/go-nova/money"
 )
 
 func main() {
@@ -54,7 +55,7 @@ func main() {
 		ctx, &acquiring.AddPaymentRequest{
 			MerchantID: "2",
 			SessionID:  session.ID,
-			Amount:     1.25,
+			Amount:     money.MustParse("1.25"),
 			ExternalID: utils.Ref(uuid.New().String()),
 		},
 	)
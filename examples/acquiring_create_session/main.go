@@ -8,7 +8,7 @@ import (
 
 	go_nova "github.com/stremovskyy/go-nova"
 	"github.com/stremovskyy/go-nova/acquiring"
-	"github.com/stremovskyy/go-nova/examples/internal/dotenv"
+	"github.com/stremovskyy/go-nova/internal/dotenv"
 )
 
 func main() {
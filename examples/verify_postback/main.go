@@ -1,16 +1,16 @@
 package main
 
 import (
-	"encoding/json"
-	"io"
+	"context"
 	stdlog "log"
 	"net/http"
 	"os"
-	"strings"
 
 	go_nova "github.com/stremovskyy/go-nova"
 	"github.com/stremovskyy/go-nova/acquiring"
-	"github.com/stremovskyy/go-nova/examples/internal/dotenv"
+	"github.com/stremovskyy/go-nova/internal/dotenv"
+	"github.com/stremovskyy/go-nova/money"
+	"github.com/stremovskyy/go-nova/postback"
 )
 
 func main() {
@@ -30,41 +30,25 @@ func main() {
 		stdlog.Fatal(err)
 	}
 
-	http.HandleFunc("/novapay/callback", func(w http.ResponseWriter, r *http.Request) {
-		defer func() { _ = r.Body.Close() }()
-		body, err := io.ReadAll(r.Body)
-		if err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
-			return
-		}
-
-		xSign := strings.TrimSpace(r.Header.Get("x-sign"))
-		if xSign == "" {
-			stdlog.Printf("auth failed: missing x-sign header")
-			http.Error(w, "auth failed", http.StatusUnauthorized)
-			return
-		}
-
-		if err := client.Verify(body, xSign); err != nil {
-			stdlog.Printf("auth failed: %v", err)
-			http.Error(w, "auth failed", http.StatusUnauthorized)
-			return
-		}
-
-		var pb acquiring.Postback
-		if err := json.Unmarshal(body, &pb); err != nil {
-			http.Error(w, "invalid json", http.StatusBadRequest)
-			return
-		}
-
-		totalAmount := 0.0
+	logPostback := func(_ context.Context, pb *acquiring.Postback) error {
+		totalAmount := money.Zero
 		for _, p := range pb.Payments {
-			totalAmount += p.Amount
+			totalAmount = totalAmount.Add(p.Amount)
 		}
+		stdlog.Printf("postback: id=%s status=%s paytype=%s amount=%s", pb.ID, pb.Status, pb.Paytype, totalAmount)
+		return nil
+	}
 
-		stdlog.Printf("postback: id=%s status=%s paytype=%s amount=%.2f", pb.ID, pb.Status, pb.Paytype, totalAmount)
-		w.WriteHeader(http.StatusOK)
-	})
+	http.Handle("/novapay/callback", postback.NewHandler(client,
+		postback.OnHolded(logPostback),
+		postback.OnPaid(logPostback),
+		postback.OnVoided(logPostback),
+		postback.OnFailed(logPostback),
+		postback.OnUnknownStatus(logPostback),
+		postback.OnVerifyError(func(_ *http.Request, err error) {
+			stdlog.Printf("auth failed: %v", err)
+		}),
+	))
 
 	stdlog.Printf("listening on :8080 (public key=%s)", publicKeyPath)
 	stdlog.Fatal(http.ListenAndServe(":8080", nil))
@@ -12,6 +12,11 @@ type Nova interface {
 	SignComfort(body []byte) (string, error)
 	Verify(body []byte, xSign string) error
 	VerifyComfort(body []byte, xSign string) error
+	VerifyKey(body []byte, xSign string) (VerificationKey, error)
+	VerifyComfortKey(body []byte, xSign string) (VerificationKey, error)
+
+	ForMerchant(name string) (Nova, error)
+	MerchantID() string
 
 	SetLogLevel(level log.Level)
 }
@@ -9,12 +9,15 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"net/http/httptrace"
 	"net/url"
 	"strings"
+	"sync/atomic"
 	"time"
 	"unicode/utf8"
 
 	"github.com/google/uuid"
+	"github.com/stremovskyy/go-nova/consts"
 	"github.com/stremovskyy/go-nova/internal/jsonutil"
 	"github.com/stremovskyy/go-nova/log"
 	"github.com/stremovskyy/recorder"
@@ -22,6 +25,7 @@ import (
 
 // Signer produces the NovaPay x-sign header value.
 //
+// It mirrors the public go_nova.Signer, which internal packages cannot import.
 // The SDK signs the exact request body bytes it sends.
 type Signer interface {
 	Sign(body []byte) (string, error)
@@ -34,12 +38,48 @@ type Client struct {
 	signer         Signer
 	logger         log.Logger
 	logBodies      bool
-	retryAttempts  int
-	retryWait      time.Duration
+	retryPolicy    RetryPolicy
 	defaultHeaders map[string]string
 	recorder       recorder.Recorder
+
+	middleware []Middleware
+	roundTrip  RoundTrip
+	metrics    Metrics
+}
+
+// Metrics receives per-attempt measurements of DoJSON.
+//
+// It is the part of the public go_nova.Metrics the HTTP client reports to.
+type Metrics interface {
+	ObserveRequest(method, endpoint string, status int, duration time.Duration)
+	ObserveRetry(method, endpoint string)
 }
 
+// Request is a signed request on its way to NovaPay.
+//
+// HTTP carries every header including x-sign; Body holds the exact bytes that were
+// signed and will be sent. Changing Body after signing invalidates x-sign.
+// Attempt is 1 for the first try and grows with every retry.
+type Request struct {
+	HTTP    *http.Request
+	Body    []byte
+	Attempt int
+}
+
+// Response is a NovaPay response before status checks and JSON decoding.
+//
+// HTTP.Body is already consumed; the bytes are in Body.
+type Response struct {
+	HTTP *http.Response
+	Body []byte
+}
+
+// RoundTrip sends a signed request and reads the response.
+type RoundTrip func(ctx context.Context, req *Request) (*Response, error)
+
+// Middleware wraps a RoundTrip, e.g. to add headers, log or measure requests.
+type Middleware func(next RoundTrip) RoundTrip
+
 // New creates an internal HTTP client.
 func New(httpClient *http.Client, signer Signer, logger log.Logger, retryAttempts int, retryWait time.Duration, defaultHeaders map[string]string, rec recorder.Recorder, logBodies bool) *Client {
 	if httpClient == nil {
@@ -54,40 +94,194 @@ func New(httpClient *http.Client, signer Signer, logger log.Logger, retryAttempt
 	if retryWait <= 0 {
 		retryWait = 300 * time.Millisecond
 	}
-	return &Client{
+	c := &Client{
 		httpClient:     httpClient,
 		signer:         signer,
 		logger:         logger,
 		logBodies:      logBodies,
-		retryAttempts:  retryAttempts,
-		retryWait:      retryWait,
 		defaultHeaders: cloneHeaders(defaultHeaders),
 		recorder:       rec,
 	}
+	c.roundTrip = c.send
+	c.retryPolicy = doublingRetry{attempts: retryAttempts, wait: retryWait}
+	return c
+}
+
+// Use appends middleware to the chain around every request attempt.
+//
+// The first middleware registered is the outermost one: it sees the request first
+// and the response last.
+func (c *Client) Use(mw ...Middleware) {
+	for _, m := range mw {
+		if m != nil {
+			c.middleware = append(c.middleware, m)
+		}
+	}
+	rt := RoundTrip(c.send)
+	for i := len(c.middleware) - 1; i >= 0; i-- {
+		rt = c.middleware[i](rt)
+	}
+	c.roundTrip = rt
+}
+
+// SetMetrics reports every attempt and retry of DoJSON to m.
+func (c *Client) SetMetrics(m Metrics) {
+	c.metrics = m
+}
+
+// RetryAttempt describes a failed attempt to a RetryPolicy.
+type RetryAttempt struct {
+	// Attempt is the number of the failed attempt, starting at 1.
+	Attempt int
+	Method  string
+	URL     string
+	// StatusCode is 0 when no response was received.
+	StatusCode int
+	// Header holds the response headers, e.g. Retry-After; nil without a response.
+	Header http.Header
+	Err    error
+}
+
+// RetryPolicy decides whether a failed attempt is repeated and how long to wait first.
+//
+// DoJSON asks it about non-2xx responses and transport errors; cancelled requests and
+// errors building the request or decoding the response are never retried.
+type RetryPolicy interface {
+	Next(a RetryAttempt) (wait time.Duration, retry bool)
+}
+
+// doublingRetry retries transient failures up to attempts times, doubling wait.
+// It is the policy of clients without SetRetryPolicy.
+type doublingRetry struct {
+	attempts int
+	wait     time.Duration
+}
+
+func (p doublingRetry) Next(a RetryAttempt) (time.Duration, bool) {
+	if a.Attempt >= p.attempts || !isRetryable(a.Err) {
+		return 0, false
+	}
+	return p.wait << (a.Attempt - 1), true
+}
+
+// SetRetryPolicy replaces the retry attempts and wait given to New.
+func (c *Client) SetRetryPolicy(p RetryPolicy) {
+	if p != nil {
+		c.retryPolicy = p
+	}
+}
+
+// RequestOption adjusts a single DoJSON call.
+type RequestOption func(*requestOptions)
+
+type requestOptions struct {
+	noRetry     bool
+	retryPolicy RetryPolicy
+	safety      consts.RetrySafety
+	classified  bool
+}
+
+// NoRetry sends the request exactly once, whatever the retry configuration.
+//
+// Use it for calls that are not safe to repeat, such as creating payouts.
+func NoRetry() RequestOption {
+	return func(o *requestOptions) {
+		o.noRetry = true
+	}
+}
+
+// WithRetryPolicy overrides the client retry policy for one call. NoRetry wins over it.
+func WithRetryPolicy(p RetryPolicy) RequestOption {
+	return func(o *requestOptions) {
+		if p != nil {
+			o.retryPolicy = p
+		}
+	}
+}
+
+// WithRetrySafety declares the class of the endpoint; see consts.EndpointSafety.
+//
+// A call to a consts.RetryUnsafe endpoint is never repeated once NovaPay may have
+// received it: a transport error after the request was written, a timeout or a 5xx
+// response is returned as an *OutcomeUnknownError instead. Only failures that
+// certainly left NovaPay untouched (connection errors before writing, 429) go to the
+// retry policy. Calls without WithRetrySafety are treated as consts.RetrySafe.
+func WithRetrySafety(s consts.RetrySafety) RequestOption {
+	return func(o *requestOptions) {
+		o.safety, o.classified = s, true
+	}
+}
+
+// ErrOutcomeUnknown is matched by *OutcomeUnknownError.
+var ErrOutcomeUnknown = errors.New("novapay: request outcome unknown")
+
+// OutcomeUnknownError reports a failed call to an unsafe endpoint that NovaPay may
+// nevertheless have executed. Err is the underlying failure.
+type OutcomeUnknownError struct {
+	Err error
+}
+
+func (e *OutcomeUnknownError) Error() string {
+	if e == nil || e.Err == nil {
+		return ErrOutcomeUnknown.Error()
+	}
+	return ErrOutcomeUnknown.Error() + ": " + e.Err.Error()
+}
+
+func (e *OutcomeUnknownError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Err
+}
+
+// Is reports whether target is ErrOutcomeUnknown.
+func (e *OutcomeUnknownError) Is(target error) bool {
+	return target == ErrOutcomeUnknown
 }
 
 // DoJSON sends a request to url and unmarshals the JSON response into out (if out != nil).
 // It returns the http response and the raw response body.
-func (c *Client) DoJSON(ctx context.Context, method, url string, body any, out any) (*http.Response, []byte, error) {
+func (c *Client) DoJSON(ctx context.Context, method, url string, body any, out any, opts ...RequestOption) (*http.Response, []byte, error) {
 	if ctx == nil {
 		ctx = context.Background()
 	}
+	var ro requestOptions
+	for _, opt := range opts {
+		if opt != nil {
+			opt(&ro)
+		}
+	}
+	policy := c.retryPolicy
+	if ro.retryPolicy != nil {
+		policy = ro.retryPolicy
+	}
 
-	var lastErr error
-	wait := c.retryWait
-	for attempt := 1; attempt <= c.retryAttempts; attempt++ {
-		c.logger.Debugf("[NovaPay HTTP] request: method=%s url=%s attempt=%d/%d", method, url, attempt, c.retryAttempts)
-		resp, raw, err := c.doOnce(ctx, method, url, body, out)
+	for attempt := 1; ; attempt++ {
+		c.logger.Debugf("[NovaPay HTTP] request: method=%s url=%s attempt=%d", method, url, attempt)
+		start := time.Now()
+		resp, raw, sent, err := c.doOnce(ctx, method, url, body, out, attempt)
+		c.observeRequest(method, url, resp, start)
 		if err == nil {
 			if resp != nil {
 				c.logger.Debugf("[NovaPay HTTP] response: method=%s url=%s status=%d response=%s", method, url, resp.StatusCode, logBody(raw, c.logBodies))
 			}
 			return resp, raw, nil
 		}
-		lastErr = err
+		if ro.classified && ro.safety == consts.RetryUnsafe && mayHaveExecuted(err, resp, sent) {
+			c.logger.Errorf("[NovaPay HTTP] request outcome unknown, not retrying unsafe call: method=%s url=%s attempt=%d err=%v", method, url, attempt, err)
+			return resp, raw, &OutcomeUnknownError{Err: err}
+		}
 
-		// Retry only on transient errors.
-		if !isRetryable(err, resp) || attempt == c.retryAttempts {
+		wait, retry := time.Duration(0), false
+		if !ro.noRetry && retryCandidate(err) && policy != nil {
+			a := RetryAttempt{Attempt: attempt, Method: method, URL: url, Err: err}
+			if resp != nil {
+				a.StatusCode, a.Header = resp.StatusCode, resp.Header
+			}
+			wait, retry = policy.Next(a)
+		}
+		if !retry {
 			if resp != nil {
 				c.logger.Errorf("[NovaPay HTTP] request failed: method=%s url=%s status=%d err=%v response=%s", method, url, resp.StatusCode, err, logBody(raw, c.logBodies))
 			} else {
@@ -96,23 +290,28 @@ func (c *Client) DoJSON(ctx context.Context, method, url string, body any, out a
 			return resp, raw, err
 		}
 		c.logger.Warnf("[NovaPay HTTP] request retry: method=%s url=%s attempt=%d wait=%s err=%v", method, url, attempt, wait, err)
+		if c.metrics != nil {
+			c.metrics.ObserveRetry(method, EndpointPath(url))
+		}
+		timer := time.NewTimer(wait)
 		select {
 		case <-ctx.Done():
+			timer.Stop()
 			return resp, raw, ctx.Err()
-		case <-time.After(wait):
-			wait *= 2
+		case <-timer.C:
 		}
 	}
-	return nil, nil, lastErr
 }
 
-func (c *Client) doOnce(ctx context.Context, method, url string, body any, out any) (*http.Response, []byte, error) {
+// doOnce makes a single attempt. sent is false only when the attempt certainly failed
+// before any request bytes were written.
+func (c *Client) doOnce(ctx context.Context, method, url string, body any, out any, attempt int) (*http.Response, []byte, bool, error) {
 	requestID := nextRequestID()
 
 	bodyBytes, err := prepareBody(body)
 	if err != nil {
 		c.recordError(ctx, requestID, err)
-		return nil, nil, err
+		return nil, nil, false, err
 	}
 	// NovaPay signature is calculated on the request body.
 	sigInput := bodyBytes
@@ -120,15 +319,19 @@ func (c *Client) doOnce(ctx context.Context, method, url string, body any, out a
 		sigInput = []byte{}
 	}
 
-	var reader io.Reader
-	if bodyBytes != nil {
-		reader = bytes.NewReader(bodyBytes)
-	}
+	var connecting, writing atomic.Bool
+	ctx = httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GetConn:          func(string) { connecting.Store(true) },
+		WroteHeaderField: func(string, []string) { writing.Store(true) },
+		WroteRequest:     func(httptrace.WroteRequestInfo) { writing.Store(true) },
+	})
+	// Without GetConn the transport is unknown and the request may have been sent.
+	mayBeSent := func() bool { return writing.Load() || !connecting.Load() }
 
-	req, err := http.NewRequestWithContext(ctx, method, url, reader)
+	req, err := http.NewRequestWithContext(ctx, method, url, nil)
 	if err != nil {
 		c.recordError(ctx, requestID, err)
-		return nil, nil, err
+		return nil, nil, false, err
 	}
 
 	req.Header.Set("Accept", "application/json")
@@ -145,7 +348,7 @@ func (c *Client) doOnce(ctx context.Context, method, url string, body any, out a
 		sig, err := c.signer.Sign(sigInput)
 		if err != nil {
 			c.recordError(ctx, requestID, err)
-			return nil, nil, err
+			return nil, nil, false, err
 		}
 		req.Header.Set("x-sign", sig)
 	}
@@ -154,18 +357,25 @@ func (c *Client) doOnce(ctx context.Context, method, url string, body any, out a
 
 	c.recordRequest(ctx, requestID, sigInput)
 
-	resp, err := c.httpClient.Do(req)
+	rt := c.roundTrip
+	if rt == nil {
+		rt = c.send
+	}
+	res, err := rt(ctx, &Request{HTTP: req, Body: bodyBytes, Attempt: attempt})
+	var resp *http.Response
+	if res != nil {
+		resp = res.HTTP
+	}
 	if err != nil {
 		c.recordError(ctx, requestID, err)
-		return nil, nil, err
+		return resp, nil, mayBeSent(), err
 	}
-	defer resp.Body.Close()
-
-	raw, err := io.ReadAll(resp.Body)
-	if err != nil {
+	if resp == nil {
+		err := errors.New("middleware returned no response")
 		c.recordError(ctx, requestID, err)
-		return resp, nil, err
+		return nil, nil, mayBeSent(), err
 	}
+	raw := res.Body
 	c.recordResponse(ctx, requestID, raw)
 
 	c.logger.Debugf("[NovaPay HTTP] response received: request_id=%s method=%s url=%s status=%d response=%s", requestID, method, url, resp.StatusCode, logBody(raw, c.logBodies))
@@ -173,18 +383,76 @@ func (c *Client) doOnce(ctx context.Context, method, url string, body any, out a
 	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
 		statusErr := &HTTPStatusError{StatusCode: resp.StatusCode, Body: raw}
 		c.recordError(ctx, requestID, statusErr)
-		return resp, raw, statusErr
+		return resp, raw, true, statusErr
 	}
 
 	if out != nil {
 		if err := json.Unmarshal(raw, out); err != nil {
 			decErr := fmt.Errorf("decode json response: %w", err)
 			c.recordError(ctx, requestID, decErr)
-			return resp, raw, decErr
+			return resp, raw, true, decErr
 		}
 	}
 
-	return resp, raw, nil
+	return resp, raw, true, nil
+}
+
+// mayHaveExecuted reports whether a failed attempt may have been executed by NovaPay
+// without the SDK learning the result: a 5xx response, a response whose body could not
+// be read, or no response after the request was (possibly) sent.
+func mayHaveExecuted(err error, resp *http.Response, sent bool) bool {
+	var hs *HTTPStatusError
+	if errors.As(err, &hs) {
+		return hs.StatusCode >= 500
+	}
+	return resp != nil || sent
+}
+
+func (c *Client) observeRequest(method, rawURL string, resp *http.Response, start time.Time) {
+	if c.metrics == nil {
+		return
+	}
+	status := 0
+	if resp != nil {
+		status = resp.StatusCode
+	}
+	c.metrics.ObserveRequest(method, EndpointPath(rawURL), status, time.Since(start))
+}
+
+// EndpointPath returns the path of rawURL, the endpoint label of metrics.
+func EndpointPath(rawURL string) string {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return rawURL
+	}
+	return u.Path
+}
+
+// send is the innermost RoundTrip: it sends req.Body with the ctx of the middleware
+// chain and reads the whole response.
+func (c *Client) send(ctx context.Context, r *Request) (*Response, error) {
+	if ctx != nil && ctx != r.HTTP.Context() {
+		r.HTTP = r.HTTP.WithContext(ctx)
+	}
+	if r.Body != nil {
+		body := r.Body
+		r.HTTP.Body = io.NopCloser(bytes.NewReader(body))
+		r.HTTP.ContentLength = int64(len(body))
+		r.HTTP.GetBody = func() (io.ReadCloser, error) {
+			return io.NopCloser(bytes.NewReader(body)), nil
+		}
+	}
+	resp, err := c.httpClient.Do(r.HTTP)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	raw, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return &Response{HTTP: resp}, err
+	}
+	return &Response{HTTP: resp, Body: raw}, nil
 }
 
 // HTTPStatusError indicates a non-2xx response.
@@ -208,7 +476,28 @@ func (e *HTTPStatusError) Error() string {
 	return fmt.Sprintf("unexpected status: %d: %s", e.StatusCode, string(b))
 }
 
-func isRetryable(err error, resp *http.Response) bool {
+// isRetryable reports whether err is a transient failure: 429, 5xx (see
+// RetryableStatus) or a transport error.
+func isRetryable(err error) bool {
+	if !retryCandidate(err) {
+		return false
+	}
+	var hs *HTTPStatusError
+	if errors.As(err, &hs) {
+		return RetryableStatus(hs.StatusCode)
+	}
+	return true
+}
+
+// RetryableStatus reports whether a response code is worth retrying by default:
+// 429 and 5xx except 501.
+func RetryableStatus(code int) bool {
+	return code == http.StatusTooManyRequests || (code >= 500 && code != http.StatusNotImplemented)
+}
+
+// retryCandidate reports whether a RetryPolicy may retry err: a non-2xx response or
+// a transport error, but not a cancelled request.
+func retryCandidate(err error) bool {
 	if err == nil {
 		return false
 	}
@@ -217,27 +506,14 @@ func isRetryable(err error, resp *http.Response) bool {
 	}
 	var hs *HTTPStatusError
 	if errors.As(err, &hs) {
-		// Retry 5xx and rate limiting.
-		return hs.StatusCode == http.StatusTooManyRequests || (hs.StatusCode >= 500 && hs.StatusCode != http.StatusNotImplemented)
+		return true
 	}
-
-	// Retry only transport-level errors.
 	var ue *url.Error
 	if errors.As(err, &ue) {
-		if errors.Is(ue.Err, context.Canceled) || errors.Is(ue.Err, context.DeadlineExceeded) {
-			return false
-		}
-		var ne net.Error
-		if errors.As(ue.Err, &ne) {
-			return true
-		}
 		return true
 	}
 	var ne net.Error
-	if errors.As(err, &ne) {
-		return true
-	}
-	return false
+	return errors.As(err, &ne)
 }
 
 func prepareBody(body any) ([]byte, error) {
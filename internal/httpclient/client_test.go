@@ -4,11 +4,15 @@ import (
 	"context"
 	"errors"
 	"net/http"
+	"net/http/httptest"
+	"strings"
 	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/google/uuid"
+
+	"github.com/stremovskyy/go-nova/consts"
 )
 
 func TestNextRequestIDIsUUIDv4(t *testing.T) {
@@ -27,19 +31,19 @@ func TestNextRequestIDIsUUIDv4(t *testing.T) {
 }
 
 func TestIsRetryable(t *testing.T) {
-	if isRetryable(nil, nil) {
+	if isRetryable(nil) {
 		t.Fatalf("nil error must not be retryable")
 	}
-	if isRetryable(errors.New("boom"), nil) {
+	if isRetryable(errors.New("boom")) {
 		t.Fatalf("plain non-network error must not be retryable")
 	}
-	if !isRetryable(&HTTPStatusError{StatusCode: http.StatusInternalServerError}, nil) {
+	if !isRetryable(&HTTPStatusError{StatusCode: http.StatusInternalServerError}) {
 		t.Fatalf("500 should be retryable")
 	}
-	if !isRetryable(&HTTPStatusError{StatusCode: http.StatusTooManyRequests}, nil) {
+	if !isRetryable(&HTTPStatusError{StatusCode: http.StatusTooManyRequests}) {
 		t.Fatalf("429 should be retryable")
 	}
-	if isRetryable(&HTTPStatusError{StatusCode: http.StatusBadRequest}, nil) {
+	if isRetryable(&HTTPStatusError{StatusCode: http.StatusBadRequest}) {
 		t.Fatalf("400 must not be retryable")
 	}
 }
@@ -66,3 +70,125 @@ func (s *countingFailSigner) Sign([]byte) (string, error) {
 	atomic.AddInt32(&s.calls, 1)
 	return "", errors.New("sign failed")
 }
+
+func TestDoJSONNoRetry(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer ts.Close()
+
+	c := New(ts.Client(), nil, nil, 3, time.Millisecond, nil, nil, false)
+	if _, _, err := c.DoJSON(context.Background(), http.MethodPost, ts.URL, map[string]string{}, nil, NoRetry()); err == nil {
+		t.Fatalf("expected error")
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("expected exactly 1 attempt with NoRetry, got %d", got)
+	}
+
+	atomic.StoreInt32(&calls, 0)
+	_, _, _ = c.DoJSON(context.Background(), http.MethodPost, ts.URL, map[string]string{}, nil)
+	if got := atomic.LoadInt32(&calls); got != 3 {
+		t.Fatalf("expected 3 attempts without NoRetry, got %d", got)
+	}
+}
+
+func TestDoJSONUnsafeBodyReadFailure(t *testing.T) {
+	var calls int32
+	release := make(chan struct{})
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		// Headers prove NovaPay processed the call; the body never arrives.
+		w.Header().Set("Content-Length", "64")
+		w.WriteHeader(http.StatusOK)
+		w.(http.Flusher).Flush()
+		<-release
+	}))
+	defer ts.Close()
+	defer close(release)
+
+	hc := ts.Client()
+	hc.Timeout = 100 * time.Millisecond
+	c := New(hc, nil, nil, 3, time.Millisecond, nil, nil, false)
+	_, _, err := c.DoJSON(context.Background(), http.MethodPost, ts.URL, map[string]string{}, nil, WithRetrySafety(consts.RetryUnsafe))
+	if !errors.Is(err, ErrOutcomeUnknown) {
+		t.Fatalf("want ErrOutcomeUnknown, got %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 1 {
+		t.Fatalf("unsafe call must be sent once, got %d attempts", got)
+	}
+}
+
+func TestMiddlewareContextReachesTransport(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+	}))
+	defer ts.Close()
+
+	c := New(ts.Client(), nil, nil, 1, time.Millisecond, nil, nil, false)
+	c.Use(func(next RoundTrip) RoundTrip {
+		return func(ctx context.Context, req *Request) (*Response, error) {
+			ctx, cancel := context.WithCancel(ctx)
+			cancel()
+			return next(ctx, req)
+		}
+	})
+	if _, _, err := c.DoJSON(context.Background(), http.MethodPost, ts.URL, map[string]string{}, nil); !errors.Is(err, context.Canceled) {
+		t.Fatalf("want context.Canceled from the middleware ctx, got %v", err)
+	}
+	if got := atomic.LoadInt32(&calls); got != 0 {
+		t.Fatalf("cancelled request must not be sent, server saw %d", got)
+	}
+}
+
+type staticSigner string
+
+func (s staticSigner) Sign([]byte) (string, error) { return string(s), nil }
+
+func TestMiddleware(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("X-Proxy-Auth") != "token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		_, _ = w.Write([]byte(`{"ok":true}`))
+	}))
+	defer ts.Close()
+
+	var order []string
+	var seenSign, seenBody, seenResponse string
+	c := New(ts.Client(), staticSigner("sig"), nil, 1, time.Millisecond, nil, nil, false)
+	c.Use(
+		func(next RoundTrip) RoundTrip {
+			return func(ctx context.Context, req *Request) (*Response, error) {
+				order = append(order, "outer")
+				seenSign, seenBody = req.HTTP.Header.Get("x-sign"), string(req.Body)
+				res, err := next(ctx, req)
+				if res != nil {
+					seenResponse = string(res.Body)
+				}
+				return res, err
+			}
+		},
+		func(next RoundTrip) RoundTrip {
+			return func(ctx context.Context, req *Request) (*Response, error) {
+				order = append(order, "inner")
+				req.HTTP.Header.Set("X-Proxy-Auth", "token")
+				return next(ctx, req)
+			}
+		},
+	)
+
+	var out struct{ OK bool }
+	if _, _, err := c.DoJSON(context.Background(), http.MethodPost, ts.URL, map[string]string{"a": "b"}, &out); err != nil {
+		t.Fatalf("do json: %v", err)
+	}
+	if !out.OK || strings.Join(order, ",") != "outer,inner" {
+		t.Fatalf("unexpected result %+v, order %v", out, order)
+	}
+	if seenSign != "sig" || seenBody != `{"a":"b"}` || seenResponse != `{"ok":true}` {
+		t.Fatalf("middleware saw x-sign=%q body=%q response=%q", seenSign, seenBody, seenResponse)
+	}
+}
@@ -0,0 +1,120 @@
+package signature
+
+import (
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/hex"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// VerificationKey is one public key of a verification keyring.
+//
+// A zero NotBefore/NotAfter leaves that side of the validity window open.
+type VerificationKey struct {
+	ID         string
+	Key        *rsa.PublicKey
+	NotBefore  time.Time
+	NotAfter   time.Time
+	Deprecated bool
+}
+
+// ValidAt reports whether t is inside the key's validity window.
+func (k VerificationKey) ValidAt(t time.Time) bool {
+	if !k.NotBefore.IsZero() && t.Before(k.NotBefore) {
+		return false
+	}
+	if !k.NotAfter.IsZero() && t.After(k.NotAfter) {
+		return false
+	}
+	return true
+}
+
+// Fingerprint returns the first 8 bytes of the SHA-256 of the PKIX encoding of key, hex encoded.
+//
+// It is used as the key ID when none is configured.
+func Fingerprint(key *rsa.PublicKey) string {
+	if key == nil {
+		return ""
+	}
+	der, err := x509.MarshalPKIXPublicKey(key)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(der)
+	return hex.EncodeToString(sum[:8])
+}
+
+// ParseRSAPublicKeysPEM parses every public key block of a PEM bundle, in order.
+func ParseRSAPublicKeysPEM(pemBytes []byte) ([]*rsa.PublicKey, error) {
+	var keys []*rsa.PublicKey
+	rest := pemBytes
+	for {
+		var block *pem.Block
+		block, rest = pem.Decode(rest)
+		if block == nil {
+			break
+		}
+		k, err := ParseRSAPublicKeyPEM(pem.EncodeToMemory(block))
+		if err != nil {
+			return nil, fmt.Errorf("signature: key %d: %w", len(keys)+1, err)
+		}
+		keys = append(keys, k)
+	}
+	if len(keys) == 0 {
+		return nil, errors.New("signature: invalid PEM (no block)")
+	}
+	return keys, nil
+}
+
+// VerifyKey verifies x-sign like Verify and returns the key that matched.
+//
+// Keys are tried in order, skipping those outside their validity window. PublicKey, if set,
+// is tried last as a key without ID or window. OnDeprecatedKey is called when the matching
+// key is deprecated.
+func (s *RSASigner) VerifyKey(body []byte, signatureBase64 string) (VerificationKey, error) {
+	if s == nil || (s.PublicKey == nil && len(s.Keys) == 0) {
+		return VerificationKey{}, errors.New("signature: public key is not configured")
+	}
+	sig, err := decodeSignatureBase64(signatureBase64)
+	if err != nil {
+		return VerificationKey{}, err
+	}
+	h, sum, err := digest(s.Hash, body)
+	if err != nil {
+		return VerificationKey{}, err
+	}
+
+	now := time.Now()
+	if s.Now != nil {
+		now = s.Now()
+	}
+	keys := s.Keys
+	if s.PublicKey != nil {
+		keys = append(keys[:len(keys):len(keys)], VerificationKey{ID: Fingerprint(s.PublicKey), Key: s.PublicKey})
+	}
+
+	tried := 0
+	lastErr := error(rsa.ErrVerification)
+	for _, k := range keys {
+		if k.Key == nil || !k.ValidAt(now) {
+			continue
+		}
+		tried++
+		if err := rsa.VerifyPKCS1v15(k.Key, h, sum, sig); err != nil {
+			lastErr = err
+			continue
+		}
+		if k.Deprecated && s.OnDeprecatedKey != nil {
+			s.OnDeprecatedKey(k)
+		}
+		return k, nil
+	}
+	if tried == 0 {
+		return VerificationKey{}, fmt.Errorf("signature: verify failed: no key valid at %s", now.Format(time.RFC3339))
+	}
+	return VerificationKey{}, fmt.Errorf("signature: verify failed: no key matched (%d tried): %w", tried, lastErr)
+}
@@ -12,6 +12,7 @@ import (
 	"errors"
 	"fmt"
 	"strings"
+	"time"
 )
 
 // HashAlgorithm controls which hash is used for RSA PKCS#1 v1.5 signatures.
@@ -40,23 +41,39 @@ func digest(algo HashAlgorithm, data []byte) (hash crypto.Hash, sum []byte, err
 
 // RSASigner signs and/or verifies NovaPay x-sign signatures using RSA PKCS#1 v1.5.
 //
-// If PrivateKey is nil, Sign will return an error.
-// If PublicKey is nil, Verify will return an error.
+// Signing is delegated to Signer when set (e.g. a KMS/HSM key), otherwise to PrivateKey.
+// If both are nil, Sign will return an error.
+// If both PublicKey and Keys are empty, Verify will return an error.
 type RSASigner struct {
 	PrivateKey *rsa.PrivateKey
 	PublicKey  *rsa.PublicKey
 	Hash       HashAlgorithm
+
+	// Signer, if set, is used instead of PrivateKey. Its key must be RSA; it receives
+	// the body digest and crypto.SHA256/crypto.SHA1 as opts.
+	Signer crypto.Signer
+
+	// Keys is a verification keyring tried in order before PublicKey; see VerifyKey.
+	Keys []VerificationKey
+	// OnDeprecatedKey, if set, is called when a deprecated key verified a signature.
+	OnDeprecatedKey func(VerificationKey)
+	// Now overrides the clock used for key validity windows.
+	Now func() time.Time
 }
 
 func (s *RSASigner) Sign(body []byte) (string, error) {
-	if s == nil || s.PrivateKey == nil {
+	if s == nil || (s.Signer == nil && s.PrivateKey == nil) {
 		return "", errors.New("signature: private key is not configured")
 	}
 	h, sum, err := digest(s.Hash, body)
 	if err != nil {
 		return "", err
 	}
-	sig, err := rsa.SignPKCS1v15(rand.Reader, s.PrivateKey, h, sum)
+	var signer crypto.Signer = s.PrivateKey
+	if s.Signer != nil {
+		signer = s.Signer
+	}
+	sig, err := signer.Sign(rand.Reader, sum, h)
 	if err != nil {
 		return "", fmt.Errorf("signature: rsa sign: %w", err)
 	}
@@ -64,21 +81,8 @@ func (s *RSASigner) Sign(body []byte) (string, error) {
 }
 
 func (s *RSASigner) Verify(body []byte, signatureBase64 string) error {
-	if s == nil || s.PublicKey == nil {
-		return errors.New("signature: public key is not configured")
-	}
-	sig, err := decodeSignatureBase64(signatureBase64)
-	if err != nil {
-		return err
-	}
-	h, sum, err := digest(s.Hash, body)
-	if err != nil {
-		return err
-	}
-	if err := rsa.VerifyPKCS1v15(s.PublicKey, h, sum, sig); err != nil {
-		return fmt.Errorf("signature: verify failed: %w", err)
-	}
-	return nil
+	_, err := s.VerifyKey(body, signatureBase64)
+	return err
 }
 
 func decodeSignatureBase64(signatureBase64 string) ([]byte, error) {
@@ -0,0 +1,73 @@
+package go_nova
+
+import (
+	"crypto/rsa"
+	"fmt"
+	"time"
+
+	"github.com/stremovskyy/go-nova/internal/signature"
+)
+
+// VerificationKey is a public key of the verification keyring, as reported by
+// Client.VerifyKey and passed to the WithDeprecatedKeyHook callback.
+type VerificationKey = signature.VerificationKey
+
+// KeyOption configures a key added by WithPublicKeyPEM, WithPublicKeyFile or WithPublicKey.
+type KeyOption func(*VerificationKey)
+
+// KeyID names the key. Without it the key is identified by its fingerprint.
+//
+// When a PEM bundle holds several keys they are named id#1, id#2, ...
+func KeyID(id string) KeyOption {
+	return func(k *VerificationKey) {
+		k.ID = id
+	}
+}
+
+// KeyValidity limits the key to signatures checked within [notBefore, notAfter].
+// A zero time leaves that side open.
+func KeyValidity(notBefore, notAfter time.Time) KeyOption {
+	return func(k *VerificationKey) {
+		k.NotBefore = notBefore
+		k.NotAfter = notAfter
+	}
+}
+
+// KeyDeprecated marks the key as deprecated: it is still accepted, but every match
+// fires the WithDeprecatedKeyHook callback.
+func KeyDeprecated() KeyOption {
+	return func(k *VerificationKey) {
+		k.Deprecated = true
+	}
+}
+
+// keyrings returns the signers whose keyrings a public key option of scope extends.
+// Keyrings a merchant inherited from the base client are emptied first.
+func (cfg *config) keyrings(scope keyScope) []*signature.RSASigner {
+	out := cfg.rsaSigners(scope)
+	for _, s := range cfg.rsaSigners(scope & cfg.inheritedKeys) {
+		s.Keys = nil
+	}
+	cfg.inheritedKeys &^= scope
+	return out
+}
+
+func addVerificationKeys(signers []*signature.RSASigner, keys []*rsa.PublicKey, opts []KeyOption) {
+	for i, key := range keys {
+		vk := VerificationKey{Key: key}
+		for _, opt := range opts {
+			if opt != nil {
+				opt(&vk)
+			}
+		}
+		switch {
+		case vk.ID == "":
+			vk.ID = signature.Fingerprint(key)
+		case len(keys) > 1:
+			vk.ID = fmt.Sprintf("%s#%d", vk.ID, i+1)
+		}
+		for _, s := range signers {
+			s.Keys = append(s.Keys, vk)
+		}
+	}
+}
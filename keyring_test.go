@@ -0,0 +1,91 @@
+package go_nova
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+	"time"
+
+	"github.com/stremovskyy/go-nova/internal/signature"
+)
+
+func TestVerifyKeyringRotation(t *testing.T) {
+	oldKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	newKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	expiredKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	pemOf := func(keys ...*rsa.PrivateKey) []byte {
+		var out []byte
+		for _, k := range keys {
+			der, err := x509.MarshalPKIXPublicKey(&k.PublicKey)
+			if err != nil {
+				t.Fatalf("marshal public key: %v", err)
+			}
+			out = append(out, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})...)
+		}
+		return out
+	}
+
+	var deprecated []string
+	client, err := NewClient(
+		WithPrivateKey(newKey),
+		WithLogger(nil),
+		WithPublicKeyPEM(pemOf(newKey), KeyID("2026")),
+		WithPublicKeyPEM(pemOf(oldKey), KeyID("2025"), KeyDeprecated()),
+		WithPublicKeyPEM(pemOf(expiredKey), KeyValidity(time.Time{}, time.Now().Add(-time.Hour))),
+		WithDeprecatedKeyHook(func(k VerificationKey) {
+			deprecated = append(deprecated, k.ID)
+		}),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	body := []byte(`{"id":"s-1","status":"paid"}`)
+	sign := func(k *rsa.PrivateKey) string {
+		sig, err := (&signature.RSASigner{PrivateKey: k, Hash: signature.HashSHA256}).Sign(body)
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		return sig
+	}
+
+	k, err := client.VerifyKey(body, sign(newKey))
+	if err != nil || k.ID != "2026" {
+		t.Fatalf("new key: got %q, %v", k.ID, err)
+	}
+	if len(deprecated) != 0 {
+		t.Fatalf("hook must not fire for the current key")
+	}
+
+	k, err = client.VerifyKey(body, sign(oldKey))
+	if err != nil || k.ID != "2025" {
+		t.Fatalf("old key: got %q, %v", k.ID, err)
+	}
+	if len(deprecated) != 1 || deprecated[0] != "2025" {
+		t.Fatalf("expected deprecated hook for 2025, got %v", deprecated)
+	}
+
+	if err := client.Verify(body, sign(expiredKey)); err == nil {
+		t.Fatalf("expired key must be rejected")
+	}
+
+	bundle, err := NewClient(WithLogger(nil), WithPublicKeyPEM(pemOf(oldKey, newKey), KeyID("bundle")))
+	if err != nil {
+		t.Fatalf("new client with bundle: %v", err)
+	}
+	if k, err := bundle.VerifyKey(body, sign(newKey)); err != nil || k.ID != "bundle#2" {
+		t.Fatalf("bundle: got %q, %v", k.ID, err)
+	}
+}
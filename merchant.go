@@ -1,13 +1,108 @@
 package go_nova
 
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
 // Merchant holds NovaPay merchant information.
 //
 // Today the API requires passing merchant_id inside request bodies.
-// This struct is a convenience to keep integrations tidy.
+// Register merchants with WithMerchant and use Client.ForMerchant to get services
+// that fill merchant_id automatically.
 type Merchant struct {
 	ID string
+
+	// Options are applied on top of the client configuration for this merchant only,
+	// e.g. its own keys, Comfort merchant ID or base URLs.
+	Options []Option
+}
+
+func NewMerchant(id string, opts ...Option) Merchant {
+	return Merchant{ID: id, Options: opts}
+}
+
+// WithMerchant registers a merchant under name; see Client.ForMerchant.
+func WithMerchant(name string, m Merchant) Option {
+	return func(cfg *config) error {
+		name = strings.TrimSpace(name)
+		if name == "" {
+			return errors.New("merchant name is empty")
+		}
+		if strings.TrimSpace(m.ID) == "" {
+			return fmt.Errorf("merchant %q: id is empty", name)
+		}
+		if _, ok := cfg.merchants[name]; ok {
+			return fmt.Errorf("merchant %q is already registered", name)
+		}
+		if cfg.merchants == nil {
+			cfg.merchants = map[string]Merchant{}
+		}
+		cfg.merchants[name] = m
+		return nil
+	}
+}
+
+// ForMerchant returns a client scoped to the merchant registered under name.
+//
+// Its Acquiring and Checkout services set merchant_id on every request and reject a
+// request that carries a different one. The Comfort service uses the merchant's
+// Comfort merchant ID, if one was configured.
+func (c *Client) ForMerchant(name string) (Nova, error) {
+	if c == nil {
+		return nil, errors.New("client is not initialized")
+	}
+	mc, ok := c.merchants[name]
+	if !ok {
+		return nil, fmt.Errorf("merchant %q is not registered", name)
+	}
+	return mc, nil
+}
+
+// MerchantID returns the merchant_id the client is scoped to, empty for an unscoped client.
+func (c *Client) MerchantID() string {
+	if c == nil {
+		return ""
+	}
+	return c.merchantID
 }
 
-func NewMerchant(id string) Merchant {
-	return Merchant{ID: id}
+func newMerchantClient(base config, name string, m Merchant) (*Client, error) {
+	cfg := base.clone()
+	cfg.inheritedKeys = scopeAll
+	for _, opt := range m.Options {
+		if opt == nil {
+			continue
+		}
+		if err := opt(&cfg); err != nil {
+			return nil, fmt.Errorf("merchant %q: %w", name, err)
+		}
+	}
+	if len(cfg.merchants) > 0 {
+		return nil, fmt.Errorf("merchant %q: nested merchants are not supported", name)
+	}
+	if err := cfg.checkSigningKeys(); err != nil {
+		return nil, fmt.Errorf("merchant %q: %w", name, err)
+	}
+	c := newClient(cfg)
+	c.merchantID = m.ID
+	return c, nil
+}
+
+// scopeMerchantID fills *merchantID for a merchant-scoped client.
+//
+// It fails if the request already names another merchant.
+func (c *Client) scopeMerchantID(merchantID *string) error {
+	if c.merchantID == "" {
+		return nil
+	}
+	if *merchantID != "" && *merchantID != c.merchantID {
+		return &ValidationError{Fields: []FieldError{{
+			Field:   "merchant_id",
+			Message: fmt.Sprintf("%q does not match scoped merchant %q", *merchantID, c.merchantID),
+		}}}
+	}
+	*merchantID = c.merchantID
+	return nil
 }
@@ -0,0 +1,141 @@
+package go_nova
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/stremovskyy/go-nova/acquiring"
+	"github.com/stremovskyy/go-nova/internal/signature"
+)
+
+func TestForMerchantFillsMerchantID(t *testing.T) {
+	baseKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	shopBKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	var (
+		mu   sync.Mutex
+		seen = map[string]*rsa.PublicKey{}
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		var req struct {
+			MerchantID string `json:"merchant_id"`
+		}
+		_ = json.Unmarshal(body, &req)
+		for _, k := range []*rsa.PublicKey{&baseKey.PublicKey, &shopBKey.PublicKey} {
+			if (&signature.RSASigner{PublicKey: k, Hash: signature.HashSHA256}).Verify(body, r.Header.Get("x-sign")) == nil {
+				mu.Lock()
+				seen[req.MerchantID] = k
+				mu.Unlock()
+			}
+		}
+		_, _ = w.Write([]byte(`{"id":"s-1","status":"created"}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(
+		WithPrivateKey(baseKey),
+		WithAcquiringBaseURL(ts.URL),
+		WithLogger(nil),
+		WithMerchant("shop-a", NewMerchant("101")),
+		WithMerchant("shop-b", NewMerchant("202", WithPrivateKey(shopBKey))),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	shopA, err := client.ForMerchant("shop-a")
+	if err != nil {
+		t.Fatalf("for merchant: %v", err)
+	}
+	shopB, err := client.ForMerchant("shop-b")
+	if err != nil {
+		t.Fatalf("for merchant: %v", err)
+	}
+
+	ctx := context.Background()
+	req := &acquiring.SessionRequest{SessionID: "s-1"}
+	if _, err := shopA.Acquiring().GetStatus(ctx, req); err != nil {
+		t.Fatalf("shop-a get status: %v", err)
+	}
+	if _, err := shopB.Acquiring().GetStatus(ctx, req); err != nil {
+		t.Fatalf("shop-b get status: %v", err)
+	}
+	if req.MerchantID != "" {
+		t.Fatalf("caller's request must not be modified, got merchant_id %q", req.MerchantID)
+	}
+	if seen["101"] != &baseKey.PublicKey || seen["202"] != &shopBKey.PublicKey {
+		t.Fatalf("unexpected merchant/key pairs: %v", seen)
+	}
+
+	_, err = shopA.Acquiring().GetStatus(ctx, &acquiring.SessionRequest{MerchantID: "202", SessionID: "s-1"})
+	if !IsValidationError(err) {
+		t.Fatalf("expected validation error for mismatched merchant_id, got %v", err)
+	}
+
+	if _, err := client.ForMerchant("shop-c"); err == nil {
+		t.Fatalf("expected error for unregistered merchant")
+	}
+	if sig, _ := client.Sign([]byte(`{}`)); (&signature.RSASigner{PublicKey: &baseKey.PublicKey, Hash: signature.HashSHA256}).Verify([]byte(`{}`), sig) != nil {
+		t.Fatalf("merchant options must not change the base client key")
+	}
+}
+
+func TestMerchantKeysReplaceInheritedSigning(t *testing.T) {
+	shopKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	novaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	client, err := NewClient(
+		WithSigner(staticSigner("agent-signature")),
+		WithPublicKey(&novaKey.PublicKey),
+		WithLogger(nil),
+		WithMerchant("shop", NewMerchant("101", WithPrivateKey(shopKey), WithPublicKey(&shopKey.PublicKey))),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	shop, err := client.ForMerchant("shop")
+	if err != nil {
+		t.Fatalf("for merchant: %v", err)
+	}
+
+	body := []byte(`{}`)
+	sig, err := shop.Sign(body)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if err := shop.Verify(body, sig); err != nil {
+		t.Fatalf("merchant key must sign its requests instead of the base signer: %v", err)
+	}
+	if sig, _ := client.Sign(body); sig != "agent-signature" {
+		t.Fatalf("base client must keep its signer, got %q", sig)
+	}
+	novaSig, err := (&signature.RSASigner{PrivateKey: novaKey, Hash: signature.HashSHA256}).Sign(body)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	if err := shop.Verify(body, novaSig); err == nil {
+		t.Fatal("merchant public key must replace the inherited keyring")
+	}
+	if err := client.Verify(body, novaSig); err != nil {
+		t.Fatalf("base client keyring must be unchanged: %v", err)
+	}
+}
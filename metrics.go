@@ -0,0 +1,60 @@
+package go_nova
+
+import (
+	"errors"
+	"time"
+
+	"github.com/stremovskyy/go-nova/internal/httpclient"
+)
+
+// Metrics receives measurements of SDK calls; see WithMetrics.
+//
+// The novaprom package implements it with Prometheus collectors. Methods are called
+// from concurrent requests and must not block.
+type Metrics interface {
+	// ObserveRequest is called after every HTTP attempt, retries included. status is
+	// 0 when no response was received.
+	ObserveRequest(method, endpoint string, status int, duration time.Duration)
+	// ObserveRetry is called before a failed attempt is repeated.
+	ObserveRetry(method, endpoint string)
+	// ObserveDryRun is called for every request skipped by DryRun.
+	ObserveDryRun(method, endpoint string)
+	// ObserveVerification is called by the Verify methods, and so for every postback
+	// checked with this client. api is APIExternal or APIComfort; err is the
+	// verification error or nil.
+	ObserveVerification(api string, err error, duration time.Duration)
+}
+
+// API names passed to Metrics.ObserveVerification.
+const (
+	APIExternal = "external"
+	APIComfort  = "comfort"
+)
+
+// WithMetrics reports request counts, latency, retries, dry runs and signature
+// verifications to m. Endpoints are URL paths such as "/v1/session".
+func WithMetrics(m Metrics) Option {
+	return func(cfg *config) error {
+		if m == nil {
+			return errors.New("metrics is nil")
+		}
+		cfg.metrics = m
+		return nil
+	}
+}
+
+// observeVerification reports a signature check to the configured metrics.
+func (c *Client) observeVerification(api string, start time.Time, err error) {
+	if c == nil || c.cfg.metrics == nil {
+		return
+	}
+	c.cfg.metrics.ObserveVerification(api, err, time.Since(start))
+}
+
+// observeDryRun reports a skipped request to the configured metrics.
+func (c *Client) observeDryRun(method, rawURL string) {
+	if c == nil || c.cfg.metrics == nil {
+		return
+	}
+	c.cfg.metrics.ObserveDryRun(method, httpclient.EndpointPath(rawURL))
+}
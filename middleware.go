@@ -0,0 +1,40 @@
+package go_nova
+
+import "github.com/stremovskyy/go-nova/internal/httpclient"
+
+// HTTPRequest is a signed request on its way to NovaPay.
+//
+// HTTP carries every header including x-sign; Body holds the exact bytes that were
+// signed. Headers may be added freely, but changing Body invalidates x-sign.
+type HTTPRequest = httpclient.Request
+
+// HTTPResponse is a NovaPay response before status checks and JSON decoding.
+// Its body has already been read into Body.
+type HTTPResponse = httpclient.Response
+
+// RoundTrip sends a signed request and reads the response.
+type RoundTrip = httpclient.RoundTrip
+
+// Middleware wraps every HTTP attempt the SDK makes, including retries.
+type Middleware = httpclient.Middleware
+
+// WithMiddleware adds middleware around every request to the Acquiring, Checkout and
+// Comfort APIs. The first middleware given is the outermost one.
+//
+//	go_nova.WithMiddleware(func(next go_nova.RoundTrip) go_nova.RoundTrip {
+//		return func(ctx context.Context, req *go_nova.HTTPRequest) (*go_nova.HTTPResponse, error) {
+//			req.HTTP.Header.Set("Proxy-Authorization", token)
+//			audit.Log(req.HTTP.URL.Path, req.Body, req.HTTP.Header.Get("x-sign"))
+//			return next(ctx, req)
+//		}
+//	})
+func WithMiddleware(mw ...Middleware) Option {
+	return func(c *config) error {
+		for _, m := range mw {
+			if m != nil {
+				c.middleware = append(c.middleware, m)
+			}
+		}
+		return nil
+	}
+}
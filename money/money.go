@@ -0,0 +1,208 @@
+// Package money provides an exact hryvnia amount type for NovaPay payloads.
+//
+// Amount stores kopecks in an int64, so sums and comparisons never suffer from
+// float64 rounding. It marshals to the same JSON number encoding/json produces
+// for the equivalent float64 (100.5, 1.25, 100), which keeps signed request
+// bodies byte-for-byte stable.
+package money
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// Amount is an exact amount in kopecks (1/100 UAH).
+type Amount int64
+
+// Zero is the zero amount.
+const Zero Amount = 0
+
+// FromKopecks returns the amount of k kopecks.
+func FromKopecks(k int64) Amount {
+	return Amount(k)
+}
+
+// FromHryvnias returns the amount of h whole hryvnias.
+func FromHryvnias(h int64) Amount {
+	return Amount(h * 100)
+}
+
+// FromFloat converts f to the nearest kopeck, rounding half away from zero.
+//
+// Use it only to migrate existing float64 values; prefer Parse for user input.
+func FromFloat(f float64) Amount {
+	return Amount(math.Round(f * 100))
+}
+
+// Parse parses a decimal amount such as "100.50", "100.5" or "-3".
+//
+// More than two fractional digits are rejected unless they are zeros.
+func Parse(s string) (Amount, error) {
+	return parse(s, false)
+}
+
+// parse parses s in hryvnias. With round set, fractions of a kopeck are rounded
+// half to even instead of rejected.
+func parse(s string, round bool) (Amount, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return 0, errors.New("money: empty amount")
+	}
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return 0, fmt.Errorf("money: invalid amount %q", s)
+	}
+	r.Mul(r, big.NewRat(100, 1))
+	if !r.IsInt() && !round {
+		return 0, fmt.Errorf("money: amount %q has more than two fractional digits", s)
+	}
+	n := roundHalfEven(r)
+	if !n.IsInt64() {
+		return 0, fmt.Errorf("money: amount %q is out of range", s)
+	}
+	return Amount(n.Int64()), nil
+}
+
+// roundHalfEven returns r rounded to the nearest integer, ties to even.
+func roundHalfEven(r *big.Rat) *big.Int {
+	q, m := new(big.Int).QuoRem(r.Num(), r.Denom(), new(big.Int))
+	m.Abs(m).Lsh(m, 1)
+	if c := m.Cmp(r.Denom()); c > 0 || (c == 0 && q.Bit(0) == 1) {
+		q.Add(q, big.NewInt(int64(r.Sign())))
+	}
+	return q
+}
+
+// MustParse is like Parse but panics on error. Intended for constants, tests and examples.
+func MustParse(s string) Amount {
+	a, err := Parse(s)
+	if err != nil {
+		panic(err)
+	}
+	return a
+}
+
+// Sum adds all amounts.
+func Sum(amounts ...Amount) Amount {
+	var total Amount
+	for _, a := range amounts {
+		total += a
+	}
+	return total
+}
+
+// Kopecks returns a as a number of kopecks.
+func (a Amount) Kopecks() int64 { return int64(a) }
+
+// Float64 returns a in hryvnias. The result may be inexact.
+func (a Amount) Float64() float64 { return float64(a) / 100 }
+
+// Add returns a + b.
+func (a Amount) Add(b Amount) Amount { return a + b }
+
+// Sub returns a - b.
+func (a Amount) Sub(b Amount) Amount { return a - b }
+
+// Mul returns a multiplied by n, e.g. a unit price by a quantity.
+func (a Amount) Mul(n int64) Amount { return a * Amount(n) }
+
+// Neg returns -a.
+func (a Amount) Neg() Amount { return -a }
+
+// Cmp returns -1, 0 or +1 when a is less than, equal to or greater than b.
+func (a Amount) Cmp(b Amount) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// IsZero reports whether a is zero.
+func (a Amount) IsZero() bool { return a == 0 }
+
+// IsPositive reports whether a is greater than zero.
+func (a Amount) IsPositive() bool { return a > 0 }
+
+// IsNegative reports whether a is less than zero.
+func (a Amount) IsNegative() bool { return a < 0 }
+
+// String formats a with exactly two fractional digits, e.g. "100.50".
+func (a Amount) String() string {
+	sign := ""
+	k := int64(a)
+	if k < 0 {
+		sign = "-"
+	}
+	u := uint64(k)
+	if k < 0 {
+		u = uint64(-(k + 1)) + 1
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, u/100, u%100)
+}
+
+// Quoted returns a as a StringAmount.
+func (a Amount) Quoted() StringAmount {
+	return StringAmount{Amount: a}
+}
+
+// MarshalJSON encodes a as a JSON number with trailing fractional zeros removed.
+func (a Amount) MarshalJSON() ([]byte, error) {
+	s := a.String()
+	s = strings.TrimRight(s, "0")
+	s = strings.TrimSuffix(s, ".")
+	return []byte(s), nil
+}
+
+// UnmarshalJSON accepts a JSON number or a string holding a decimal amount.
+// null leaves a unchanged.
+//
+// Unlike Parse it does not fail on fractions of a kopeck: a response carrying
+// 1.005 decodes to 1.00 and 1.015 to 1.02 (half to even), so one odd amount does
+// not make a whole response unreadable.
+func (a *Amount) UnmarshalJSON(b []byte) error {
+	b = bytes.TrimSpace(b)
+	if bytes.Equal(b, []byte("null")) {
+		return nil
+	}
+	s := string(b)
+	if len(b) > 0 && b[0] == '"' {
+		var err error
+		s, err = strconv.Unquote(s)
+		if err != nil {
+			return fmt.Errorf("money: invalid amount %s", b)
+		}
+	}
+	v, err := parse(s, true)
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+// StringAmount is an Amount encoded as a JSON string with two fractional digits ("100.50").
+//
+// The Comfort API sends and expects amounts in this form.
+type StringAmount struct {
+	Amount
+}
+
+// MarshalJSON encodes s as a JSON string such as "100.50".
+func (s StringAmount) MarshalJSON() ([]byte, error) {
+	return json.Marshal(s.Amount.String())
+}
+
+// UnmarshalJSON accepts a JSON string or number holding a decimal amount.
+func (s *StringAmount) UnmarshalJSON(b []byte) error {
+	return s.Amount.UnmarshalJSON(b)
+}
@@ -0,0 +1,110 @@
+package money
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stremovskyy/go-nova/internal/jsonutil"
+)
+
+func TestMarshalMatchesFloat64Encoding(t *testing.T) {
+	for _, f := range []float64{0, 0.01, 0.1, 1, 1.25, 100.5, 100.05, 999999.99, -3.4} {
+		want, err := jsonutil.Marshal(f)
+		if err != nil {
+			t.Fatalf("marshal float %v: %v", f, err)
+		}
+		got, err := jsonutil.Marshal(FromFloat(f))
+		if err != nil {
+			t.Fatalf("marshal amount %v: %v", f, err)
+		}
+		if string(got) != string(want) {
+			t.Fatalf("amount %v: got %s want %s", f, got, want)
+		}
+	}
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Amount
+		wantErr bool
+	}{
+		{in: "100.50", want: 10050},
+		{in: "100.5", want: 10050},
+		{in: "100", want: 10000},
+		{in: " 0.01 ", want: 1},
+		{in: "-3.40", want: -340},
+		{in: "1.2300", want: 123},
+		{in: "1.005", wantErr: true},
+		{in: "", wantErr: true},
+		{in: "abc", wantErr: true},
+	}
+	for _, tc := range tests {
+		got, err := Parse(tc.in)
+		if tc.wantErr {
+			if err == nil {
+				t.Fatalf("Parse(%q): expected error, got %v", tc.in, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("Parse(%q): %v", tc.in, err)
+		}
+		if got != tc.want {
+			t.Fatalf("Parse(%q): got %d want %d", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestArithmeticIsExact(t *testing.T) {
+	// 0.1 + 0.2 != 0.3 in float64.
+	if got := MustParse("0.1").Add(MustParse("0.2")); got != MustParse("0.3") {
+		t.Fatalf("0.1 + 0.2 = %s", got)
+	}
+	if got := Sum(MustParse("19.99").Mul(3), MustParse("0.03")); got.String() != "60.00" {
+		t.Fatalf("unexpected sum: %s", got)
+	}
+	if MustParse("10").Cmp(MustParse("9.99")) != 1 || MustParse("1").Sub(MustParse("1.01")).String() != "-0.01" {
+		t.Fatalf("unexpected comparison or subtraction result")
+	}
+}
+
+func TestUnmarshalJSON(t *testing.T) {
+	var v struct {
+		Number Amount       `json:"number"`
+		Str    Amount       `json:"str"`
+		Quoted StringAmount `json:"quoted"`
+	}
+	if err := json.Unmarshal([]byte(`{"number":150.25,"str":"12.30","quoted":"0.50"}`), &v); err != nil {
+		t.Fatalf("unmarshal: %v", err)
+	}
+	if v.Number != 15025 || v.Str != 1230 || v.Quoted.Amount != 50 {
+		t.Fatalf("unexpected values: %+v", v)
+	}
+
+	for in, want := range map[string]Amount{
+		`1.001`:    100,
+		`1.005`:    100,
+		`1.015`:    102,
+		`1.0051`:   101,
+		`"-1.005"`: -100,
+		`-1.015`:   -102,
+		`-0.006`:   -1,
+	} {
+		var a Amount
+		if err := json.Unmarshal([]byte(in), &a); err != nil || a != want {
+			t.Fatalf("unmarshal %s: got %d, %v; want %d (half to even)", in, a, err, want)
+		}
+	}
+	if err := json.Unmarshal([]byte(`{"number":"1.2.3"}`), &v); err == nil {
+		t.Fatalf("expected error for an invalid amount")
+	}
+
+	b, err := jsonutil.Marshal(MustParse("1").Quoted())
+	if err != nil {
+		t.Fatalf("marshal: %v", err)
+	}
+	if string(b) != `"1.00"` {
+		t.Fatalf("unexpected StringAmount encoding: %s", b)
+	}
+}
@@ -0,0 +1,93 @@
+// Package novaotel traces go-nova SDK calls with OpenTelemetry.
+//
+//	client, err := go_nova.NewClient(
+//		go_nova.WithPrivateKeyFile(path),
+//		novaotel.WithTracerProvider(otel.GetTracerProvider()),
+//	)
+//
+// It is a separate module so that the SDK itself does not depend on OpenTelemetry.
+package novaotel
+
+import (
+	"context"
+	"net/http"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+
+	go_nova "github.com/stremovskyy/go-nova"
+)
+
+// TracerName is the instrumentation scope name of the SDK spans.
+const TracerName = "github.com/stremovskyy/go-nova"
+
+// Tracer implements go_nova.Tracer with an OpenTelemetry tracer.
+//
+// Call spans are internal spans, attempt spans client spans carrying the request
+// method, host and path. The trace context is injected into the request headers
+// with the global propagator (otel.SetTextMapPropagator).
+type Tracer struct {
+	tracer trace.Tracer
+}
+
+var _ go_nova.Tracer = (*Tracer)(nil)
+
+// New returns a Tracer that creates spans with tp.
+func New(tp trace.TracerProvider) *Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return &Tracer{tracer: tp.Tracer(TracerName)}
+}
+
+// WithTracerProvider is go_nova.WithTracer(New(tp)).
+func WithTracerProvider(tp trace.TracerProvider) go_nova.Option {
+	return go_nova.WithTracer(New(tp))
+}
+
+// StartCall implements go_nova.Tracer.
+func (t *Tracer) StartCall(ctx context.Context, name string) (context.Context, go_nova.Span) {
+	ctx, span := t.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindInternal))
+	return ctx, otelSpan{span}
+}
+
+// StartAttempt implements go_nova.Tracer.
+func (t *Tracer) StartAttempt(ctx context.Context, req *http.Request, _ int) (context.Context, go_nova.Span) {
+	ctx, span := t.tracer.Start(ctx, "HTTP "+req.Method,
+		trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(
+			attribute.String("http.request.method", req.Method),
+			attribute.String("server.address", req.URL.Hostname()),
+			attribute.String("url.path", req.URL.Path),
+		))
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(req.Header))
+	return ctx, otelSpan{span}
+}
+
+type otelSpan struct {
+	span trace.Span
+}
+
+func (s otelSpan) SetString(key, value string) {
+	s.span.SetAttributes(attribute.String(key, value))
+}
+
+func (s otelSpan) SetInt(key string, value int) {
+	s.span.SetAttributes(attribute.Int(key, value))
+}
+
+func (s otelSpan) End(class string, err error) {
+	if class != "" {
+		s.span.SetAttributes(attribute.String(go_nova.AttrErrorClass, class))
+		msg := class
+		if err != nil {
+			s.span.RecordError(err)
+			msg = err.Error()
+		}
+		s.span.SetStatus(codes.Error, msg)
+	}
+	s.span.End()
+}
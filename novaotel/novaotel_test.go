@@ -0,0 +1,109 @@
+package novaotel_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	go_nova "github.com/stremovskyy/go-nova"
+	"github.com/stremovskyy/go-nova/acquiring"
+	"github.com/stremovskyy/go-nova/novaotel"
+)
+
+func spanAttr(s tracetest.SpanStub, key string) (attribute.Value, bool) {
+	for _, kv := range s.Attributes {
+		if string(kv.Key) == key {
+			return kv.Value, true
+		}
+	}
+	return attribute.Value{}, false
+}
+
+func TestTracer(t *testing.T) {
+	prev := otel.GetTextMapPropagator()
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer otel.SetTextMapPropagator(prev)
+
+	var (
+		mu          sync.Mutex
+		calls       int
+		traceparent []string
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		n := calls
+		traceparent = append(traceparent, r.Header.Get("traceparent"))
+		mu.Unlock()
+		if n == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"s-1","status":"paid"}`))
+	}))
+	defer ts.Close()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	exporter := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	client, err := go_nova.NewClient(
+		go_nova.WithPrivateKey(key),
+		go_nova.WithAcquiringBaseURL(ts.URL),
+		go_nova.WithRetry(2, time.Millisecond),
+		go_nova.WithLogger(nil),
+		novaotel.WithTracerProvider(tp),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	req := &acquiring.SessionRequest{MerchantID: "m-1", SessionID: "s-1"}
+	if _, err := client.Acquiring().GetStatus(context.Background(), req); err != nil {
+		t.Fatalf("get status: %v", err)
+	}
+	spans := exporter.GetSpans()
+	if len(spans) != 3 {
+		t.Fatalf("want 2 attempt spans and 1 call span, got %d", len(spans))
+	}
+	call := spans[2]
+	if call.Name != "AcquiringService.GetStatus" {
+		t.Fatalf("call span name = %q", call.Name)
+	}
+	for key, want := range map[string]attribute.Value{
+		go_nova.AttrMerchantID:   attribute.StringValue("m-1"),
+		go_nova.AttrSessionID:    attribute.StringValue("s-1"),
+		go_nova.AttrRetryAttempt: attribute.IntValue(2),
+		go_nova.AttrStatusCode:   attribute.IntValue(http.StatusOK),
+	} {
+		if got, ok := spanAttr(call, key); !ok || got != want {
+			t.Fatalf("call span %s = %v, want %v", key, got.Emit(), want.Emit())
+		}
+	}
+	for i, attempt := range spans[:2] {
+		if attempt.Parent.SpanID() != call.SpanContext.SpanID() {
+			t.Fatalf("attempt %d is not a child of the call span", i+1)
+		}
+		if !strings.Contains(traceparent[i], call.SpanContext.TraceID().String()) {
+			t.Fatalf("attempt %d traceparent %q does not carry the call trace", i+1, traceparent[i])
+		}
+	}
+	if got, _ := spanAttr(spans[0], go_nova.AttrErrorClass); got.AsString() != "503" || spans[0].Status.Code != codes.Error {
+		t.Fatalf("first attempt: error class %q, status %v", got.AsString(), spans[0].Status)
+	}
+}
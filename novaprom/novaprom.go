@@ -0,0 +1,171 @@
+// Package novaprom exports go-nova metrics to Prometheus.
+//
+//	m := novaprom.New()
+//	prometheus.MustRegister(m)
+//	client, err := go_nova.NewClient(go_nova.WithMetrics(m), ...)
+//
+// Metrics (with the default "novapay" namespace):
+//
+//	novapay_requests_total{method,endpoint,code}           HTTP attempts; code is "error" without a response
+//	novapay_request_duration_seconds{method,endpoint}      latency of HTTP attempts
+//	novapay_retries_total{method,endpoint}                 repeated attempts
+//	novapay_dry_runs_total{method,endpoint}                requests skipped by DryRun
+//	novapay_signature_verifications_total{api,result}      x-sign checks; result is "ok" or "failed"
+//	novapay_signature_verification_duration_seconds{api}   latency of x-sign checks
+package novaprom
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+
+	go_nova "github.com/stremovskyy/go-nova"
+)
+
+// DefaultNamespace prefixes every metric name.
+const DefaultNamespace = "novapay"
+
+// Option configures New.
+type Option func(*options)
+
+type options struct {
+	namespace     string
+	buckets       []float64
+	verifyBuckets []float64
+	constLabels   prometheus.Labels
+}
+
+// WithNamespace replaces the "novapay" prefix of the metric names.
+func WithNamespace(ns string) Option {
+	return func(o *options) {
+		o.namespace = ns
+	}
+}
+
+// WithBuckets sets the histogram buckets of request latency, in seconds.
+func WithBuckets(b []float64) Option {
+	return func(o *options) {
+		if len(b) > 0 {
+			o.buckets = b
+		}
+	}
+}
+
+// WithVerifyBuckets sets the histogram buckets of x-sign verification latency, in seconds.
+func WithVerifyBuckets(b []float64) Option {
+	return func(o *options) {
+		if len(b) > 0 {
+			o.verifyBuckets = b
+		}
+	}
+}
+
+// WithConstLabels adds labels to every metric, e.g. the service or environment name.
+func WithConstLabels(l prometheus.Labels) Option {
+	return func(o *options) {
+		o.constLabels = l
+	}
+}
+
+// Metrics implements go_nova.Metrics and prometheus.Collector.
+type Metrics struct {
+	requests       *prometheus.CounterVec
+	latency        *prometheus.HistogramVec
+	retries        *prometheus.CounterVec
+	dryRuns        *prometheus.CounterVec
+	verifications  *prometheus.CounterVec
+	verifyDuration *prometheus.HistogramVec
+}
+
+var _ go_nova.Metrics = (*Metrics)(nil)
+var _ prometheus.Collector = (*Metrics)(nil)
+
+// New creates the collectors. Register the result with a prometheus.Registerer.
+func New(opts ...Option) *Metrics {
+	o := &options{
+		namespace:     DefaultNamespace,
+		buckets:       prometheus.DefBuckets,
+		verifyBuckets: prometheus.ExponentialBuckets(0.0001, 4, 8),
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	endpoint := []string{"method", "endpoint"}
+	return &Metrics{
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: o.namespace, Name: "requests_total", ConstLabels: o.constLabels,
+			Help: "NovaPay HTTP attempts by endpoint and status code.",
+		}, []string{"method", "endpoint", "code"}),
+		latency: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: o.namespace, Name: "request_duration_seconds", ConstLabels: o.constLabels,
+			Help: "Latency of NovaPay HTTP attempts.", Buckets: o.buckets,
+		}, endpoint),
+		retries: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: o.namespace, Name: "retries_total", ConstLabels: o.constLabels,
+			Help: "NovaPay HTTP attempts repeated after a failure.",
+		}, endpoint),
+		dryRuns: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: o.namespace, Name: "dry_runs_total", ConstLabels: o.constLabels,
+			Help: "NovaPay requests skipped by DryRun.",
+		}, endpoint),
+		verifications: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: o.namespace, Name: "signature_verifications_total", ConstLabels: o.constLabels,
+			Help: "x-sign verifications by API and result.",
+		}, []string{"api", "result"}),
+		verifyDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: o.namespace, Name: "signature_verification_duration_seconds", ConstLabels: o.constLabels,
+			Help: "Latency of x-sign verifications.", Buckets: o.verifyBuckets,
+		}, []string{"api"}),
+	}
+}
+
+func (m *Metrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{m.requests, m.latency, m.retries, m.dryRuns, m.verifications, m.verifyDuration}
+}
+
+// Describe implements prometheus.Collector.
+func (m *Metrics) Describe(ch chan<- *prometheus.Desc) {
+	for _, c := range m.collectors() {
+		c.Describe(ch)
+	}
+}
+
+// Collect implements prometheus.Collector.
+func (m *Metrics) Collect(ch chan<- prometheus.Metric) {
+	for _, c := range m.collectors() {
+		c.Collect(ch)
+	}
+}
+
+// ObserveRequest implements go_nova.Metrics.
+func (m *Metrics) ObserveRequest(method, endpoint string, status int, d time.Duration) {
+	code := "error"
+	if status > 0 {
+		code = strconv.Itoa(status)
+	}
+	m.requests.WithLabelValues(method, endpoint, code).Inc()
+	m.latency.WithLabelValues(method, endpoint).Observe(d.Seconds())
+}
+
+// ObserveRetry implements go_nova.Metrics.
+func (m *Metrics) ObserveRetry(method, endpoint string) {
+	m.retries.WithLabelValues(method, endpoint).Inc()
+}
+
+// ObserveDryRun implements go_nova.Metrics.
+func (m *Metrics) ObserveDryRun(method, endpoint string) {
+	m.dryRuns.WithLabelValues(method, endpoint).Inc()
+}
+
+// ObserveVerification implements go_nova.Metrics.
+func (m *Metrics) ObserveVerification(api string, err error, d time.Duration) {
+	result := "ok"
+	if err != nil {
+		result = "failed"
+	}
+	m.verifications.WithLabelValues(api, result).Inc()
+	m.verifyDuration.WithLabelValues(api).Observe(d.Seconds())
+}
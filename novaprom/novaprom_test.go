@@ -0,0 +1,109 @@
+package novaprom_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+
+	go_nova "github.com/stremovskyy/go-nova"
+	"github.com/stremovskyy/go-nova/acquiring"
+	"github.com/stremovskyy/go-nova/novaprom"
+)
+
+func TestMetrics(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1) == 1 {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"s-1","status":"paid"}`))
+	}))
+	defer ts.Close()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	m := novaprom.New()
+	reg := prometheus.NewPedanticRegistry()
+	reg.MustRegister(m)
+
+	client, err := go_nova.NewClient(
+		go_nova.WithPrivateKey(key),
+		go_nova.WithPublicKey(&key.PublicKey),
+		go_nova.WithAcquiringBaseURL(ts.URL),
+		go_nova.WithRetry(2, time.Millisecond),
+		go_nova.WithLogger(nil),
+		go_nova.WithMetrics(m),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	req := &acquiring.SessionRequest{MerchantID: "1", SessionID: "s-1"}
+	if _, err := client.Acquiring().GetStatus(context.Background(), req); err != nil {
+		t.Fatalf("get status: %v", err)
+	}
+	if _, err := client.Acquiring().GetStatus(context.Background(), req, go_nova.DryRun(func(string, string, any) {})); err != nil {
+		t.Fatalf("dry run: %v", err)
+	}
+	body := []byte(`{"id":"s-1"}`)
+	sig, err := client.Sign(body)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	_ = client.Verify(body, sig)
+	_ = client.Verify(body, "bad")
+
+	expected := `
+# HELP novapay_requests_total NovaPay HTTP attempts by endpoint and status code.
+# TYPE novapay_requests_total counter
+novapay_requests_total{code="200",endpoint="/v1/get-status",method="POST"} 1
+novapay_requests_total{code="502",endpoint="/v1/get-status",method="POST"} 1
+# HELP novapay_retries_total NovaPay HTTP attempts repeated after a failure.
+# TYPE novapay_retries_total counter
+novapay_retries_total{endpoint="/v1/get-status",method="POST"} 1
+# HELP novapay_dry_runs_total NovaPay requests skipped by DryRun.
+# TYPE novapay_dry_runs_total counter
+novapay_dry_runs_total{endpoint="/v1/get-status",method="POST"} 1
+# HELP novapay_signature_verifications_total x-sign verifications by API and result.
+# TYPE novapay_signature_verifications_total counter
+novapay_signature_verifications_total{api="external",result="failed"} 1
+novapay_signature_verifications_total{api="external",result="ok"} 1
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected),
+		"novapay_requests_total", "novapay_retries_total", "novapay_dry_runs_total", "novapay_signature_verifications_total"); err != nil {
+		t.Fatal(err)
+	}
+	if n := testutil.CollectAndCount(m, "novapay_request_duration_seconds"); n != 1 {
+		t.Fatalf("want 1 latency series, got %d", n)
+	}
+}
+
+func TestWithVerifyBuckets(t *testing.T) {
+	m := novaprom.New(novaprom.WithVerifyBuckets([]float64{0.001, 0.01}))
+	m.ObserveVerification("comfort", nil, 5*time.Millisecond)
+
+	expected := `
+# HELP novapay_signature_verification_duration_seconds Latency of x-sign verifications.
+# TYPE novapay_signature_verification_duration_seconds histogram
+novapay_signature_verification_duration_seconds_bucket{api="comfort",le="0.001"} 0
+novapay_signature_verification_duration_seconds_bucket{api="comfort",le="0.01"} 1
+novapay_signature_verification_duration_seconds_bucket{api="comfort",le="+Inf"} 1
+novapay_signature_verification_duration_seconds_sum{api="comfort"} 0.005
+novapay_signature_verification_duration_seconds_count{api="comfort"} 1
+`
+	if err := testutil.CollectAndCompare(m, strings.NewReader(expected), "novapay_signature_verification_duration_seconds"); err != nil {
+		t.Fatal(err)
+	}
+}
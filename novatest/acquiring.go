@@ -0,0 +1,442 @@
+package novatest
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/stremovskyy/go-nova/acquiring"
+	"github.com/stremovskyy/go-nova/checkout"
+	"github.com/stremovskyy/go-nova/consts"
+	"github.com/stremovskyy/go-nova/money"
+)
+
+// Session is a snapshot of an emulated payment session.
+type Session struct {
+	ID          string
+	MerchantID  string
+	Checkout    bool
+	Status      consts.SessionStatus
+	CreatedAt   time.Time
+	ClientPhone string
+	CallbackURL string
+	Metadata    json.RawMessage
+
+	ExternalID *string
+	Amount     money.Amount
+	UseHold    bool
+	Delivery   *acquiring.Delivery
+	Products   []acquiring.Product
+
+	// HeldAmount is the amount confirmed by CompleteHold.
+	HeldAmount     money.Amount
+	ExpressWaybill string
+}
+
+// Session returns a snapshot of the session with the given id.
+func (s *Server) Session(id string) (Session, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	sess, ok := s.sessions[id]
+	if !ok {
+		return Session{}, false
+	}
+	return *sess, true
+}
+
+// Pay emulates the customer paying the session on the payment page.
+//
+// The session moves through "processing" to "holded" when the payment uses hold,
+// otherwise to "paid". A postback is sent for every step.
+func (s *Server) Pay(sessionID string) error {
+	return s.customerAction(sessionID, func(sess *Session) []consts.SessionStatus {
+		if sess.UseHold {
+			return []consts.SessionStatus{consts.SessionStatusProcessing, consts.SessionStatusHolded}
+		}
+		return []consts.SessionStatus{consts.SessionStatusProcessing, consts.SessionStatusPaid}
+	})
+}
+
+// Fail emulates a declined payment: the session moves through "processing" to "failed".
+func (s *Server) Fail(sessionID string) error {
+	return s.customerAction(sessionID, func(*Session) []consts.SessionStatus {
+		return []consts.SessionStatus{consts.SessionStatusProcessing, consts.SessionStatusFailed}
+	})
+}
+
+// SetStatus forces the session into status and sends a postback, bypassing transition checks.
+func (s *Server) SetStatus(sessionID string, status consts.SessionStatus) error {
+	req := &request{}
+	s.mu.Lock()
+	sess, ok := s.sessions[sessionID]
+	if ok {
+		sess.Status = status
+		req.deliveries = append(req.deliveries, s.postbackFor(sess))
+	}
+	s.mu.Unlock()
+	if !ok {
+		return fmt.Errorf("novatest: session %q not found", sessionID)
+	}
+	return deliveryErrors(s.deliver(req.deliveries))
+}
+
+func (s *Server) customerAction(sessionID string, steps func(*Session) []consts.SessionStatus) error {
+	req := &request{}
+	s.mu.Lock()
+	sess, ok := s.sessions[sessionID]
+	var apiErr *apiError
+	switch {
+	case !ok:
+		apiErr = errSessionNotFound(sessionID)
+	case sess.Status != consts.SessionStatusCreated || sess.Amount <= 0:
+		apiErr = errInvalidStatus(sessionID, sess.Status, "pay")
+	default:
+		apiErr = s.advance(req, sess, steps(sess)...)
+	}
+	s.mu.Unlock()
+	if apiErr != nil {
+		return fmt.Errorf("novatest: %s", apiErr.Message)
+	}
+	return deliveryErrors(s.deliver(req.deliveries))
+}
+
+// advance moves sess through steps, queueing a postback per step. Callers hold s.mu.
+func (s *Server) advance(req *request, sess *Session, steps ...consts.SessionStatus) *apiError {
+	for _, next := range steps {
+		if !sess.Status.CanTransitionTo(next) {
+			return errInvalidStatus(sess.ID, sess.Status, fmt.Sprintf("move to %q", next))
+		}
+		sess.Status = next
+		req.deliveries = append(req.deliveries, s.postbackFor(sess))
+	}
+	return nil
+}
+
+func (s *Server) lookupSession(merchantID, sessionID string) (*Session, *apiError) {
+	if ae := required(required(nil, "merchant_id", merchantID), "session_id", sessionID); ae != nil {
+		return nil, ae
+	}
+	sess, ok := s.sessions[sessionID]
+	if !ok || sess.MerchantID != merchantID {
+		return nil, errSessionNotFound(sessionID)
+	}
+	return sess, nil
+}
+
+func (s *Server) createSession(r *request) (any, *apiError) {
+	var in acquiring.CreateSessionRequest
+	if ae := r.decode(&in); ae != nil {
+		return nil, ae
+	}
+	if ae := required(required(nil, "merchant_id", in.MerchantID), "client_phone", in.ClientPhone); ae != nil {
+		return nil, ae
+	}
+
+	sess := s.newSession(in.MerchantID, false)
+	sess.ClientPhone = in.ClientPhone
+	sess.Metadata = in.Metadata
+	if in.CallbackURL != nil {
+		sess.CallbackURL = *in.CallbackURL
+	}
+	return acquiring.CreateSessionResponse{ID: sess.ID, Metadata: sess.Metadata}, nil
+}
+
+func (s *Server) newSession(merchantID string, isCheckout bool) *Session {
+	sess := &Session{
+		ID:         s.nextID("session"),
+		MerchantID: merchantID,
+		Checkout:   isCheckout,
+		Status:     consts.SessionStatusCreated,
+		CreatedAt:  s.now().UTC(),
+	}
+	s.sessions[sess.ID] = sess
+	return sess
+}
+
+func (s *Server) addPayment(r *request) (any, *apiError) {
+	var in acquiring.AddPaymentRequest
+	if ae := r.decode(&in); ae != nil {
+		return nil, ae
+	}
+	sess, ae := s.lookupSession(in.MerchantID, in.SessionID)
+	if ae != nil {
+		return nil, ae
+	}
+	if sess.Status != consts.SessionStatusCreated || sess.Amount > 0 {
+		return nil, errInvalidStatus(sess.ID, sess.Status, "add payment to")
+	}
+	if in.Amount <= 0 {
+		return nil, errInvalidRequest("amount must be > 0")
+	}
+
+	sess.Amount = in.Amount
+	sess.ExternalID = in.ExternalID
+	sess.UseHold = in.UseHold != nil && *in.UseHold
+	sess.Delivery = in.Delivery
+	sess.Products = in.Products
+
+	out := acquiring.AddPaymentResponse{ID: sess.ID, URL: s.paymentURL(sess)}
+	if sess.Delivery != nil {
+		price := deliveryPrice(sess.Delivery.Weight)
+		out.DeliveryPrice = &price
+	}
+	return out, nil
+}
+
+func (s *Server) paymentURL(sess *Session) string {
+	return s.URL + "/pay/" + sess.ID
+}
+
+func (s *Server) voidSession(r *request) (any, *apiError) {
+	var in acquiring.SessionRequest
+	if ae := r.decode(&in); ae != nil {
+		return nil, ae
+	}
+	sess, ae := s.lookupSession(in.MerchantID, in.SessionID)
+	if ae != nil {
+		return nil, ae
+	}
+	if sess.Status == consts.SessionStatusVoided {
+		return nil, &apiError{status: http.StatusBadRequest, Code: CodeAlreadyVoided, Message: fmt.Sprintf("session %q is already voided", sess.ID)}
+	}
+	if !sess.Status.CanVoid() {
+		return nil, errInvalidStatus(sess.ID, sess.Status, "void")
+	}
+	if ae := s.advance(r, sess, consts.SessionStatusProcessingVoid, consts.SessionStatusVoided); ae != nil {
+		return nil, ae
+	}
+	return struct{}{}, nil
+}
+
+func (s *Server) completeHold(r *request) (any, *apiError) {
+	var in acquiring.CompleteHoldRequest
+	if ae := r.decode(&in); ae != nil {
+		return nil, ae
+	}
+	sess, ae := s.lookupSession(in.MerchantID, in.SessionID)
+	if ae != nil {
+		return nil, ae
+	}
+	if !sess.Status.CanCompleteHold() {
+		return nil, errInvalidStatus(sess.ID, sess.Status, "complete hold of")
+	}
+
+	amount := sess.Amount
+	if in.Amount != nil {
+		amount = *in.Amount
+	}
+	if amount <= 0 || amount > sess.Amount {
+		return nil, errInvalidRequest("amount must be > 0 and not exceed the held amount %s", sess.Amount)
+	}
+	sess.HeldAmount = amount
+
+	if ae := s.advance(r, sess, consts.SessionStatusProcessingHoldCompletion, consts.SessionStatusHoldConfirmed); ae != nil {
+		return nil, ae
+	}
+	return struct{}{}, nil
+}
+
+func (s *Server) expireSession(r *request) (any, *apiError) {
+	var in acquiring.SessionRequest
+	if ae := r.decode(&in); ae != nil {
+		return nil, ae
+	}
+	sess, ae := s.lookupSession(in.MerchantID, in.SessionID)
+	if ae != nil {
+		return nil, ae
+	}
+	if ae := s.advance(r, sess, consts.SessionStatusExpired); ae != nil {
+		return nil, ae
+	}
+	return struct{}{}, nil
+}
+
+func (s *Server) confirmDeliveryHold(r *request) (any, *apiError) {
+	var in acquiring.SessionRequest
+	if ae := r.decode(&in); ae != nil {
+		return nil, ae
+	}
+	sess, ae := s.lookupSession(in.MerchantID, in.SessionID)
+	if ae != nil {
+		return nil, ae
+	}
+	if sess.Delivery == nil || !sess.Status.CanCompleteHold() {
+		return nil, errInvalidStatus(sess.ID, sess.Status, "confirm delivery hold of")
+	}
+	sess.HeldAmount = sess.Amount
+	if sess.ExpressWaybill == "" {
+		sess.ExpressWaybill = fmt.Sprintf("2045%010d", s.seq)
+	}
+	if ae := s.advance(r, sess, consts.SessionStatusHoldConfirmed); ae != nil {
+		return nil, ae
+	}
+	return acquiring.ConfirmDeliveryHoldResponse{
+		ID:             sess.ID,
+		ExpressWaybill: sess.ExpressWaybill,
+		RefID:          "ref-" + sess.ID,
+		Metadata:       sess.Metadata,
+	}, nil
+}
+
+func (s *Server) printExpressWaybill(r *request) (any, *apiError) {
+	var in acquiring.SessionRequest
+	if ae := r.decode(&in); ae != nil {
+		return nil, ae
+	}
+	sess, ae := s.lookupSession(in.MerchantID, in.SessionID)
+	if ae != nil {
+		return nil, ae
+	}
+	if sess.ExpressWaybill == "" {
+		return nil, errInvalidStatus(sess.ID, sess.Status, "print express waybill of")
+	}
+	return rawResponse{
+		contentType: "application/pdf",
+		body:        []byte("%PDF-1.4\n% novatest express waybill " + sess.ExpressWaybill + "\n%%EOF\n"),
+	}, nil
+}
+
+func (s *Server) getStatus(r *request) (any, *apiError) {
+	var in acquiring.SessionRequest
+	if ae := r.decode(&in); ae != nil {
+		return nil, ae
+	}
+	sess, ae := s.lookupSession(in.MerchantID, in.SessionID)
+	if ae != nil {
+		return nil, ae
+	}
+	if sess.Checkout {
+		return checkoutStatus(sess), nil
+	}
+
+	out := acquiring.GetStatusResponse{
+		ID:        sess.ID,
+		Metadata:  sess.Metadata,
+		Paytype:   paytype(sess),
+		Status:    sess.Status,
+		CreatedAt: sess.CreatedAt.Format(time.RFC3339),
+	}
+	if sess.ClientPhone != "" {
+		phone := sess.ClientPhone
+		out.ClientPhone = &phone
+	}
+	if sess.Amount > 0 {
+		out.Operations = []acquiring.OperationInfo{{ExternalID: sess.ExternalID, Amount: sess.Amount}}
+	}
+	return out, nil
+}
+
+func checkoutStatus(sess *Session) checkout.StatusResponse {
+	out := checkout.StatusResponse{
+		ID:        sess.ID,
+		Status:    sess.Status,
+		Paytype:   paytype(sess),
+		CreatedAt: sess.CreatedAt.Format(time.RFC3339),
+		Metadata:  sess.Metadata,
+	}
+	if sess.ClientPhone != "" {
+		phone := sess.ClientPhone
+		out.ClientPhone = &phone
+	}
+	if sess.Amount > 0 {
+		op := checkout.Operation{ExternalID: sess.ExternalID, Amount: sess.Amount}
+		for _, p := range sess.Products {
+			description := p.Description
+			op.Products = append(op.Products, checkout.Product{Description: &description, Count: p.Count, Price: p.Price})
+		}
+		out.Operations = []checkout.Operation{op}
+	}
+	if sess.Delivery != nil {
+		price := deliveryPrice(sess.Delivery.Weight)
+		out.Delivery = &checkout.Delivery{
+			VolumeWeight:       sess.Delivery.VolumeWeight,
+			Weight:             sess.Delivery.Weight,
+			RecipientCity:      sess.Delivery.RecipientCity,
+			RecipientWarehouse: sess.Delivery.RecipientWarehouse,
+			Price:              &price,
+		}
+	}
+	if sess.ExpressWaybill != "" {
+		waybill := sess.ExpressWaybill
+		out.ExpressWaybill = &waybill
+	}
+	return out
+}
+
+func (s *Server) deliveryPrice(r *request) (any, *apiError) {
+	var in acquiring.DeliveryPriceRequest
+	if ae := r.decode(&in); ae != nil {
+		return nil, ae
+	}
+	ae := required(required(required(nil, "merchant_id", in.MerchantID), "recipient_city", in.RecipientCity), "recipient_warehouse", in.RecipientWarehouse)
+	if ae != nil {
+		return nil, ae
+	}
+	return acquiring.DeliveryPriceResponse{"delivery_price": deliveryPrice(in.Weight)}, nil
+}
+
+func (s *Server) createCheckoutSession(r *request) (any, *apiError) {
+	var in checkout.CreateSessionRequest
+	if ae := r.decode(&in); ae != nil {
+		return nil, ae
+	}
+	if ae := required(required(nil, "merchant_id", in.MerchantID), "callback_url", in.CallbackURL); ae != nil {
+		return nil, ae
+	}
+
+	sess := s.newSession(in.MerchantID, true)
+	sess.CallbackURL = in.CallbackURL
+	if in.ClientPhone != nil {
+		sess.ClientPhone = *in.ClientPhone
+	}
+	if in.Delivery != nil {
+		sess.Delivery = &acquiring.Delivery{VolumeWeight: in.Delivery.VolumeWeight, Weight: in.Delivery.Weight}
+	}
+	return checkout.SessionResponse{ID: sess.ID, URL: s.paymentURL(sess)}, nil
+}
+
+func (s *Server) addCheckoutPayment(r *request) (any, *apiError) {
+	var in checkout.AddPaymentRequest
+	if ae := r.decode(&in); ae != nil {
+		return nil, ae
+	}
+	sess, ae := s.lookupSession(in.MerchantID, in.SessionID)
+	if ae != nil {
+		return nil, ae
+	}
+	if !sess.Checkout || sess.Status != consts.SessionStatusCreated || sess.Amount > 0 {
+		return nil, errInvalidStatus(sess.ID, sess.Status, "add checkout payment to")
+	}
+	if in.Amount <= 0 {
+		return nil, errInvalidRequest("amount must be > 0")
+	}
+
+	sess.Amount = in.Amount
+	sess.ExternalID = in.ExternalID
+	sess.UseHold = in.UseHold != nil && *in.UseHold
+	for _, p := range in.Products {
+		var description string
+		if p.Description != nil {
+			description = *p.Description
+		}
+		sess.Products = append(sess.Products, acquiring.Product{Description: description, Count: p.Count, Price: p.Price})
+	}
+	return checkout.PaymentResponse{ID: sess.ID, URL: s.paymentURL(sess)}, nil
+}
+
+func paytype(sess *Session) string {
+	if sess.UseHold {
+		return "hold"
+	}
+	return "direct"
+}
+
+// deliveryPrice is a flat tariff: 60 UAH plus 5 UAH per started kilogram above 2 kg.
+func deliveryPrice(weight float64) money.Amount {
+	price := money.FromHryvnias(60)
+	for w := 2.0; w < weight; w++ {
+		price = price.Add(money.FromHryvnias(5))
+	}
+	return price
+}
@@ -0,0 +1,220 @@
+package novatest
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/stremovskyy/go-nova/comfort"
+	"github.com/stremovskyy/go-nova/consts"
+	"github.com/stremovskyy/go-nova/internal/signature"
+	"github.com/stremovskyy/go-nova/money"
+)
+
+// Comfort operation statuses used by the emulator.
+const (
+	OperationStatusCreated  = consts.OperationStatusCreated
+	OperationStatusSuccess  = consts.OperationStatusSuccess
+	OperationStatusFailed   = consts.OperationStatusFailed
+	OperationStatusRefunded = consts.OperationStatusRefunded
+)
+
+// Operation is a snapshot of an emulated Comfort payout.
+type Operation struct {
+	GUID      string
+	PublicID  string
+	Status    consts.OperationStatus
+	Amount    money.Amount
+	Purpose   *string
+	PayoutPAN *string
+	Recipient *comfort.Recipient
+}
+
+// Operation returns a snapshot of the payout with the given GUID.
+func (s *Server) Operation(guid string) (Operation, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	op, ok := s.operations[guid]
+	if !ok {
+		return Operation{}, false
+	}
+	return *op, true
+}
+
+// SetOperationStatus forces the payout into status, e.g. to emulate a completed or failed transfer.
+//
+// When WithComfortCallbackURL is set, a SHA-1 signed comfort.Postback is sent to it.
+func (s *Server) SetOperationStatus(guid string, status consts.OperationStatus) error {
+	s.mu.Lock()
+	op, ok := s.operations[guid]
+	if !ok {
+		s.mu.Unlock()
+		return fmt.Errorf("novatest: operation %q not found", guid)
+	}
+	op.Status = status
+	pending := pendingDelivery{
+		callbackURL:     s.comfortCallbackURL,
+		operationGUID:   op.GUID,
+		operationStatus: op.Status,
+		hash:            signature.HashSHA1,
+		payload: comfort.Postback{
+			GUID:      op.GUID,
+			PublicID:  op.PublicID,
+			Status:    op.Status,
+			Amount:    op.Amount.Quoted(),
+			Purpose:   op.Purpose,
+			PayoutPAN: op.PayoutPAN,
+			Recipient: op.Recipient,
+			UpdatedAt: s.now().Format(time.RFC3339),
+		},
+	}
+	s.mu.Unlock()
+	return deliveryErrors(s.deliver([]pendingDelivery{pending}))
+}
+
+// Balance returns the current Comfort balance.
+func (s *Server) Balance() money.Amount {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.balance
+}
+
+func (s *Server) createOperations(r *request) (any, *apiError) {
+	var in comfort.CreateOperationsRequest
+	if ae := r.decode(&in); ae != nil {
+		return nil, ae
+	}
+	if len(in.RawBody) == 0 {
+		return nil, errInvalidRequest("RAW_BODY must contain at least one operation")
+	}
+
+	var total money.Amount
+	for i, item := range in.RawBody {
+		if !item.Amount.IsPositive() {
+			return nil, errInvalidRequest("RAW_BODY[%d].amount must be > 0", i)
+		}
+		if item.GUID != nil {
+			if _, exists := s.operations[*item.GUID]; exists {
+				return nil, errInvalidRequest("RAW_BODY[%d].guid %q already exists", i, *item.GUID)
+			}
+		}
+		total = total.Add(item.Amount.Amount)
+	}
+	if total.Cmp(s.balance) > 0 {
+		return nil, &apiError{status: http.StatusBadRequest, Code: CodeInsufficientFunds, Message: "insufficient funds on balance"}
+	}
+
+	out := make([]comfort.CreateOperationsResponseItem, 0, len(in.RawBody))
+	for _, item := range in.RawBody {
+		guid := uuid.NewString()
+		if item.GUID != nil && *item.GUID != "" {
+			guid = *item.GUID
+		}
+		op := &Operation{
+			GUID:      guid,
+			PublicID:  s.nextID("op"),
+			Status:    OperationStatusCreated,
+			Amount:    item.Amount.Amount,
+			Purpose:   item.Purpose,
+			PayoutPAN: item.PayoutPAN,
+			Recipient: item.Recipient,
+		}
+		s.operations[guid] = op
+		out = append(out, comfort.CreateOperationsResponseItem{GUID: op.GUID, PublicID: op.PublicID})
+	}
+	s.balance = s.balance.Sub(total)
+	return out, nil
+}
+
+func (s *Server) refundOperations(r *request) (any, *apiError) {
+	var in comfort.RefundOperationsRequest
+	if ae := r.decode(&in); ae != nil {
+		return nil, ae
+	}
+	if len(in.RawBody) == 0 {
+		return nil, errInvalidRequest("RAW_BODY must contain at least one operation id")
+	}
+
+	refunded := make([]string, 0, len(in.RawBody))
+	for _, publicID := range in.RawBody {
+		op := s.operationByPublicID(publicID)
+		if op == nil {
+			return nil, &apiError{status: http.StatusNotFound, Code: CodeOperationNotFound, Message: fmt.Sprintf("operation %q not found", publicID)}
+		}
+		if op.Status == OperationStatusRefunded {
+			continue
+		}
+		op.Status = OperationStatusRefunded
+		s.balance = s.balance.Add(op.Amount)
+		refunded = append(refunded, publicID)
+	}
+	return refunded, nil
+}
+
+func (s *Server) operationByPublicID(publicID string) *Operation {
+	for _, op := range s.operations {
+		if op.PublicID == publicID {
+			return op
+		}
+	}
+	return nil
+}
+
+func (s *Server) operationsStatus(r *request) (any, *apiError) {
+	var in comfort.OperationsStatusRequest
+	if ae := r.decode(&in); ae != nil {
+		return nil, ae
+	}
+	if in.GUID == nil || *in.GUID == "" {
+		return nil, errInvalidRequest("guid is required")
+	}
+	op, ok := s.operations[*in.GUID]
+	if !ok {
+		return nil, &apiError{status: http.StatusNotFound, Code: CodeOperationNotFound, Message: fmt.Sprintf("operation %q not found", *in.GUID)}
+	}
+	return comfort.OperationsStatusResponse{Status: op.Status, PublicID: op.PublicID}, nil
+}
+
+func (s *Server) changeRecipientData(r *request) (any, *apiError) {
+	var in comfort.ChangeRecipientDataRequest
+	if ae := r.decode(&in); ae != nil {
+		return nil, ae
+	}
+	op, ok := s.operations[in.GUID]
+	if !ok {
+		return nil, &apiError{status: http.StatusNotFound, Code: CodeOperationNotFound, Message: fmt.Sprintf("operation %q not found", in.GUID)}
+	}
+	if op.Recipient == nil {
+		op.Recipient = &comfort.Recipient{}
+	}
+	op.Recipient.LastName = in.Recipient.LastName
+	op.Recipient.FirstName = in.Recipient.FirstName
+	op.Recipient.Patronymic = in.Recipient.Patronymic
+	op.Recipient.DocumentType = in.Recipient.DocumentType
+	op.Recipient.DocumentNumber = in.Recipient.DocumentNumber
+	op.Recipient.DocumentSeries = in.Recipient.DocumentSeries
+	op.Recipient.DocumentIssuedCountry = in.Recipient.DocumentIssuedCountry
+	return struct{}{}, nil
+}
+
+func (s *Server) comfortBalance(*request) (any, *apiError) {
+	return comfort.BalanceResponse{Balance: s.balance.Quoted()}, nil
+}
+
+func (s *Server) exportOperations(r *request) (any, *apiError) {
+	var in comfort.ExportOperationsRequest
+	if ae := r.decode(&in); ae != nil {
+		return nil, ae
+	}
+	ae := required(required(required(nil, "from_date", in.FromDate), "to_date", in.ToDate), "recepient_email", in.RecepientEmail)
+	if ae != nil {
+		return nil, ae
+	}
+	return comfort.ExportOperationsResponse{
+		ExportID:    s.nextID("export"),
+		Status:      "requested",
+		RequestedAt: s.now().UTC().Format("2006-01-02T15:04:05Z07:00"),
+	}, nil
+}
@@ -0,0 +1,184 @@
+package novatest
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/stremovskyy/go-nova/acquiring"
+	"github.com/stremovskyy/go-nova/checkout"
+	"github.com/stremovskyy/go-nova/consts"
+	"github.com/stremovskyy/go-nova/internal/jsonutil"
+	"github.com/stremovskyy/go-nova/internal/signature"
+)
+
+// Delivery records one postback sent by the emulator.
+//
+// Comfort payout postbacks set OperationGUID and OperationStatus instead of SessionID and Status.
+type Delivery struct {
+	SessionID       string
+	Status          consts.SessionStatus
+	OperationGUID   string
+	OperationStatus consts.OperationStatus
+	CallbackURL     string
+	Body            []byte
+	XSign           string
+	// StatusCode is the callback response code, 0 when the request failed.
+	StatusCode int
+	Err        error
+}
+
+// pendingDelivery is a postback built under the state lock and sent after it is released.
+type pendingDelivery struct {
+	sessionID   string
+	status      consts.SessionStatus
+	callbackURL string
+	// payload is an acquiring.Postback, a checkout.Postback for checkout sessions
+	// or a comfort.Postback for payouts.
+	payload any
+
+	operationGUID   string
+	operationStatus consts.OperationStatus
+	// hash is the x-sign hash, SHA-256 unless set (Comfort uses SHA-1).
+	hash signature.HashAlgorithm
+}
+
+// Postbacks returns every postback sent so far, in order.
+func (s *Server) Postbacks() []Delivery {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	out := make([]Delivery, len(s.deliveries))
+	copy(out, s.deliveries)
+	return out
+}
+
+// postbackFor snapshots the current session state as a postback. Callers hold s.mu.
+func (s *Server) postbackFor(sess *Session) pendingDelivery {
+	pb := acquiring.Postback{
+		ID:               sess.ID,
+		Status:           sess.Status,
+		Paytype:          paytype(sess),
+		TerminalName:     "novatest",
+		CreatedAt:        sess.CreatedAt.Format(time.RFC3339),
+		Metadata:         sess.Metadata,
+		ClientPhone:      sess.ClientPhone,
+		ProcessingResult: processingResult(sess.Status),
+	}
+	if sess.Amount > 0 {
+		pb.Payments = []acquiring.PostbackPayment{{ExternalID: sess.ExternalID, Amount: sess.Amount, Products: sess.Products}}
+	}
+	pending := pendingDelivery{sessionID: sess.ID, status: sess.Status, callbackURL: sess.CallbackURL, payload: pb}
+	if sess.Checkout {
+		pending.payload = checkoutPostback(sess, pb)
+	}
+	return pending
+}
+
+// checkoutPostback extends an acquiring postback with the checkout delivery and waybill data.
+func checkoutPostback(sess *Session, pb acquiring.Postback) checkout.Postback {
+	status := checkoutStatus(sess)
+	out := checkout.Postback{
+		ID:               pb.ID,
+		Status:           pb.Status,
+		Paytype:          pb.Paytype,
+		TerminalName:     pb.TerminalName,
+		CreatedAt:        pb.CreatedAt,
+		Metadata:         pb.Metadata,
+		ClientPhone:      pb.ClientPhone,
+		ProcessingResult: pb.ProcessingResult,
+		Delivery:         status.Delivery,
+		ExpressWaybill:   status.ExpressWaybill,
+	}
+	for _, op := range status.Operations {
+		out.Payments = append(out.Payments, checkout.PostbackPayment{
+			PostbackPayment: acquiring.PostbackPayment{ExternalID: op.ExternalID, Amount: op.Amount},
+			Products:        op.Products,
+		})
+	}
+	return out
+}
+
+func processingResult(status consts.SessionStatus) string {
+	if status == consts.SessionStatusFailed {
+		return "Declined"
+	}
+	return "Successful"
+}
+
+// deliver sends signed postbacks in order and records the outcome.
+// Sessions without callback_url are skipped.
+func (s *Server) deliver(pending []pendingDelivery) []Delivery {
+	out := make([]Delivery, 0, len(pending))
+	for _, p := range pending {
+		if p.callbackURL == "" {
+			continue
+		}
+		d := s.send(p)
+		s.mu.Lock()
+		s.deliveries = append(s.deliveries, d)
+		s.mu.Unlock()
+		out = append(out, d)
+	}
+	return out
+}
+
+func (s *Server) send(p pendingDelivery) Delivery {
+	d := Delivery{SessionID: p.sessionID, Status: p.status, OperationGUID: p.operationGUID, OperationStatus: p.operationStatus, CallbackURL: p.callbackURL}
+
+	body, err := jsonutil.Marshal(p.payload)
+	if err != nil {
+		d.Err = fmt.Errorf("marshal postback: %w", err)
+		return d
+	}
+	d.Body = body
+
+	hash := p.hash
+	if hash == "" {
+		hash = signature.HashSHA256
+	}
+	sig, err := (&signature.RSASigner{PrivateKey: s.postbackKey, Hash: hash}).Sign(body)
+	if err != nil {
+		d.Err = err
+		return d
+	}
+	d.XSign = sig
+
+	req, err := http.NewRequest(http.MethodPost, p.callbackURL, bytes.NewReader(body))
+	if err != nil {
+		d.Err = err
+		return d
+	}
+	req.Header.Set(consts.HeaderContentType, consts.ContentTypeJSON)
+	req.Header.Set(consts.HeaderXSign, sig)
+
+	resp, err := s.httpClient.Do(req)
+	if err != nil {
+		d.Err = err
+		return d
+	}
+	_, _ = io.Copy(io.Discard, resp.Body)
+	_ = resp.Body.Close()
+	d.StatusCode = resp.StatusCode
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		d.Err = fmt.Errorf("callback responded with status %d", resp.StatusCode)
+	}
+	return d
+}
+
+func deliveryErrors(deliveries []Delivery) error {
+	var errs []error
+	for _, d := range deliveries {
+		if d.Err == nil {
+			continue
+		}
+		if d.OperationGUID != "" {
+			errs = append(errs, fmt.Errorf("novatest: comfort postback %s (%s): %w", d.OperationGUID, d.OperationStatus, d.Err))
+			continue
+		}
+		errs = append(errs, fmt.Errorf("novatest: postback %s (%s): %w", d.SessionID, d.Status, d.Err))
+	}
+	return errors.Join(errs...)
+}
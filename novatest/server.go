@@ -0,0 +1,333 @@
+// Package novatest provides an in-memory NovaPay emulator for tests.
+//
+// Server implements the Acquiring, Checkout and Comfort endpoints from consts,
+// verifies the incoming x-sign, keeps session and payout state in memory and
+// sends signed postbacks to the session callback_url:
+//
+//	key, _ := rsa.GenerateKey(rand.Reader, 2048)
+//	srv := novatest.NewServer(novatest.WithClientPublicKey(&key.PublicKey))
+//	defer srv.Close()
+//
+//	client, _ := go_nova.NewClient(
+//		go_nova.WithPrivateKey(key),
+//		go_nova.WithPublicKeyPEM(srv.PostbackPublicKeyPEM()),
+//		go_nova.WithAcquiringBaseURL(srv.URL),
+//		go_nova.WithCheckoutBaseURL(srv.URL),
+//		go_nova.WithComfortBaseURL(srv.URL),
+//	)
+package novatest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stremovskyy/go-nova/consts"
+	"github.com/stremovskyy/go-nova/internal/jsonutil"
+	"github.com/stremovskyy/go-nova/internal/signature"
+	"github.com/stremovskyy/go-nova/money"
+)
+
+// DefaultComfortMerchantID is the x-merchant-id accepted by the Comfort endpoints
+// unless WithComfortMerchantID is used.
+const DefaultComfortMerchantID = "42"
+
+// DefaultComfortBalance is the initial Comfort balance, 100 000.00 UAH.
+const DefaultComfortBalance = money.Amount(10_000_000)
+
+// Error codes returned in the emulator error envelope.
+const (
+	CodeInvalidRequest    = "invalid_request"
+	CodeInvalidSignature  = "invalid_signature"
+	CodeSessionNotFound   = "session_not_found"
+	CodeInvalidStatus     = "invalid_session_status"
+	CodeAlreadyVoided     = "session_already_voided"
+	CodeOperationNotFound = "operation_not_found"
+	CodeInsufficientFunds = "insufficient_funds"
+	CodeMerchantMismatch  = "merchant_mismatch"
+)
+
+// Server is an in-memory NovaPay emulator backed by httptest.Server.
+type Server struct {
+	// URL is the base URL for Acquiring, Checkout and Comfort clients.
+	URL string
+
+	ts *httptest.Server
+
+	clientKey          *rsa.PublicKey
+	postbackKey        *rsa.PrivateKey
+	comfortMerchantID  string
+	comfortCallbackURL string
+	httpClient         *http.Client
+	now                func() time.Time
+
+	mu         sync.Mutex
+	seq        int
+	sessions   map[string]*Session
+	operations map[string]*Operation
+	balance    money.Amount
+	deliveries []Delivery
+}
+
+// Option configures Server.
+type Option func(*Server)
+
+// WithClientPublicKey sets the key used to verify x-sign on incoming requests.
+//
+// Without it the emulator does not check signatures.
+func WithClientPublicKey(key *rsa.PublicKey) Option {
+	return func(s *Server) {
+		s.clientKey = key
+	}
+}
+
+// WithPostbackKey sets the key used to sign postbacks.
+//
+// Without it a fresh key is generated; see PostbackPublicKeyPEM.
+func WithPostbackKey(key *rsa.PrivateKey) Option {
+	return func(s *Server) {
+		s.postbackKey = key
+	}
+}
+
+// WithComfortMerchantID sets the x-merchant-id accepted by the Comfort endpoints.
+func WithComfortMerchantID(merchantID string) Option {
+	return func(s *Server) {
+		s.comfortMerchantID = merchantID
+	}
+}
+
+// WithComfortCallbackURL sets where Comfort payout postbacks are sent by SetOperationStatus.
+func WithComfortCallbackURL(url string) Option {
+	return func(s *Server) {
+		s.comfortCallbackURL = url
+	}
+}
+
+// WithComfortBalance sets the initial Comfort balance.
+func WithComfortBalance(balance money.Amount) Option {
+	return func(s *Server) {
+		s.balance = balance
+	}
+}
+
+// WithHTTPClient sets the client used to deliver postbacks.
+func WithHTTPClient(client *http.Client) Option {
+	return func(s *Server) {
+		if client != nil {
+			s.httpClient = client
+		}
+	}
+}
+
+// WithClock overrides the time source used for created_at fields.
+func WithClock(now func() time.Time) Option {
+	return func(s *Server) {
+		if now != nil {
+			s.now = now
+		}
+	}
+}
+
+// NewServer starts an emulator. Call Close when done.
+func NewServer(opts ...Option) *Server {
+	s := &Server{
+		comfortMerchantID: DefaultComfortMerchantID,
+		balance:           DefaultComfortBalance,
+		httpClient:        &http.Client{Timeout: 10 * time.Second},
+		now:               time.Now,
+		sessions:          map[string]*Session{},
+		operations:        map[string]*Operation{},
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(s)
+		}
+	}
+	if s.postbackKey == nil {
+		key, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			panic(fmt.Sprintf("novatest: generate postback key: %v", err))
+		}
+		s.postbackKey = key
+	}
+
+	s.ts = httptest.NewServer(s.routes())
+	s.URL = s.ts.URL
+	return s
+}
+
+// Close shuts the emulator down.
+func (s *Server) Close() {
+	s.ts.Close()
+}
+
+// PostbackPublicKey returns the key postbacks are signed with.
+func (s *Server) PostbackPublicKey() *rsa.PublicKey {
+	return &s.postbackKey.PublicKey
+}
+
+// PostbackPublicKeyPEM returns PostbackPublicKey as a PKIX PEM block, ready for go_nova.WithPublicKeyPEM.
+func (s *Server) PostbackPublicKeyPEM() []byte {
+	der, err := x509.MarshalPKIXPublicKey(&s.postbackKey.PublicKey)
+	if err != nil {
+		panic(fmt.Sprintf("novatest: marshal postback key: %v", err))
+	}
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+func (s *Server) routes() http.Handler {
+	mux := http.NewServeMux()
+
+	// Checkout void/get-status/expire share paths with Acquiring.
+	external := map[string]func(*request) (any, *apiError){
+		consts.AcquiringCreateSessionPath:       s.createSession,
+		consts.AcquiringAddPaymentPath:          s.addPayment,
+		consts.AcquiringVoidSessionPath:         s.voidSession,
+		consts.AcquiringCompleteHoldPath:        s.completeHold,
+		consts.AcquiringExpireSessionPath:       s.expireSession,
+		consts.AcquiringConfirmDeliveryPath:     s.confirmDeliveryHold,
+		consts.AcquiringPrintExpressWaybillPath: s.printExpressWaybill,
+		consts.AcquiringGetStatusPath:           s.getStatus,
+		consts.AcquiringDeliveryPricePath:       s.deliveryPrice,
+		consts.CheckoutCreateSessionPath:        s.createCheckoutSession,
+		consts.CheckoutAddPaymentPath:           s.addCheckoutPayment,
+	}
+	for p, fn := range external {
+		mux.Handle("POST "+p, s.endpoint(signature.HashSHA256, false, fn))
+	}
+
+	comfort := map[string]func(*request) (any, *apiError){
+		consts.ComfortCreateOperationsPath:    s.createOperations,
+		consts.ComfortRefundOperationsPath:    s.refundOperations,
+		consts.ComfortOperationsStatusPath:    s.operationsStatus,
+		consts.ComfortChangeRecipientDataPath: s.changeRecipientData,
+		consts.ComfortExportOperationsPath:    s.exportOperations,
+	}
+	for p, fn := range comfort {
+		mux.Handle("POST "+p, s.endpoint(signature.HashSHA1, true, fn))
+	}
+	mux.Handle("GET "+consts.ComfortBalancePath, s.endpoint(signature.HashSHA1, true, s.comfortBalance))
+
+	return mux
+}
+
+// request is an incoming API call that passed signature checks.
+type request struct {
+	body []byte
+	// deliveries are postbacks to send once the state lock is released.
+	deliveries []pendingDelivery
+}
+
+func (r *request) decode(v any) *apiError {
+	if err := json.Unmarshal(r.body, v); err != nil {
+		return errInvalidRequest("invalid json: %v", err)
+	}
+	return nil
+}
+
+// rawResponse is written as is instead of being encoded as JSON.
+type rawResponse struct {
+	contentType string
+	body        []byte
+}
+
+func (s *Server) endpoint(hash signature.HashAlgorithm, comfort bool, fn func(*request) (any, *apiError)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, err := io.ReadAll(r.Body)
+		_ = r.Body.Close()
+		if err != nil {
+			writeError(w, errInvalidRequest("cannot read body: %v", err))
+			return
+		}
+
+		if comfort && r.Header.Get(consts.HeaderXMerchantID) != s.comfortMerchantID {
+			writeError(w, &apiError{status: http.StatusForbidden, Code: CodeMerchantMismatch, Message: "unknown x-merchant-id"})
+			return
+		}
+		if s.clientKey != nil {
+			v := &signature.RSASigner{PublicKey: s.clientKey, Hash: hash}
+			if err := v.Verify(body, r.Header.Get(consts.HeaderXSign)); err != nil {
+				writeError(w, &apiError{status: http.StatusUnauthorized, Code: CodeInvalidSignature, Message: err.Error()})
+				return
+			}
+		}
+
+		req := &request{body: body}
+		s.mu.Lock()
+		out, apiErr := fn(req)
+		s.mu.Unlock()
+
+		s.deliver(req.deliveries)
+
+		if apiErr != nil {
+			writeError(w, apiErr)
+			return
+		}
+		if raw, ok := out.(rawResponse); ok {
+			w.Header().Set(consts.HeaderContentType, raw.contentType)
+			_, _ = w.Write(raw.body)
+			return
+		}
+		writeJSON(w, http.StatusOK, out)
+	})
+}
+
+// apiError is the emulator error envelope.
+type apiError struct {
+	status  int
+	Code    string `json:"code"`
+	Message string `json:"message"`
+}
+
+func errInvalidRequest(format string, args ...any) *apiError {
+	return &apiError{status: http.StatusBadRequest, Code: CodeInvalidRequest, Message: fmt.Sprintf(format, args...)}
+}
+
+func errSessionNotFound(id string) *apiError {
+	return &apiError{status: http.StatusNotFound, Code: CodeSessionNotFound, Message: fmt.Sprintf("session %q not found", id)}
+}
+
+func errInvalidStatus(id string, status consts.SessionStatus, op string) *apiError {
+	return &apiError{status: http.StatusBadRequest, Code: CodeInvalidStatus, Message: fmt.Sprintf("cannot %s session %q in status %q", op, id, status)}
+}
+
+func writeError(w http.ResponseWriter, e *apiError) {
+	writeJSON(w, e.status, e)
+}
+
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	b, err := jsonutil.Marshal(v)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set(consts.HeaderContentType, consts.ContentTypeJSON)
+	w.WriteHeader(status)
+	_, _ = w.Write(b)
+}
+
+// nextID returns a unique, readable identifier with the given prefix. Callers hold s.mu.
+func (s *Server) nextID(prefix string) string {
+	s.seq++
+	return fmt.Sprintf("%s-%06d", prefix, s.seq)
+}
+
+func required(ae *apiError, field string, value string) *apiError {
+	if ae != nil {
+		return ae
+	}
+	if strings.TrimSpace(value) == "" {
+		return errInvalidRequest("%s is required", field)
+	}
+	return nil
+}
@@ -0,0 +1,305 @@
+package novatest_test
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	go_nova "github.com/stremovskyy/go-nova"
+	"github.com/stremovskyy/go-nova/acquiring"
+	"github.com/stremovskyy/go-nova/checkout"
+	"github.com/stremovskyy/go-nova/comfort"
+	"github.com/stremovskyy/go-nova/consts"
+	"github.com/stremovskyy/go-nova/internal/utils"
+	"github.com/stremovskyy/go-nova/money"
+	"github.com/stremovskyy/go-nova/novatest"
+	"github.com/stremovskyy/go-nova/postback"
+)
+
+func newClient(t *testing.T, opts ...novatest.Option) (go_nova.Nova, *novatest.Server) {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	srv := novatest.NewServer(append([]novatest.Option{novatest.WithClientPublicKey(&key.PublicKey)}, opts...)...)
+	t.Cleanup(srv.Close)
+
+	client, err := go_nova.NewClient(
+		go_nova.WithPrivateKey(key),
+		go_nova.WithPublicKeyPEM(srv.PostbackPublicKeyPEM()),
+		go_nova.WithAcquiringBaseURL(srv.URL),
+		go_nova.WithCheckoutBaseURL(srv.URL),
+		go_nova.WithComfortBaseURL(srv.URL),
+		go_nova.WithComfortMerchantID(novatest.DefaultComfortMerchantID),
+		go_nova.WithLogger(nil),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	return client, srv
+}
+
+func TestHoldFlowWithPostbacks(t *testing.T) {
+	client, srv := newClient(t)
+	ctx := context.Background()
+
+	var (
+		mu       sync.Mutex
+		received []string
+	)
+	record := func(_ context.Context, pb *acquiring.Postback) error {
+		mu.Lock()
+		defer mu.Unlock()
+		received = append(received, string(pb.Status))
+		return nil
+	}
+	callback := httptest.NewServer(postback.NewHandler(client,
+		postback.OnProcessing(record),
+		postback.OnHolded(record),
+		postback.OnProcessingHoldCompletion(record),
+		postback.OnHoldConfirmed(record),
+		postback.OnProcessingVoid(record),
+		postback.OnVoided(record),
+	))
+	defer callback.Close()
+
+	session, err := client.Acquiring().CreateSession(ctx, &acquiring.CreateSessionRequest{
+		MerchantID:  "1",
+		ClientPhone: "+380982850620",
+		CallbackURL: utils.Ref(callback.URL),
+	})
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	payment, err := client.Acquiring().AddPayment(ctx, &acquiring.AddPaymentRequest{
+		MerchantID: "1",
+		SessionID:  session.ID,
+		Amount:     money.MustParse("150.25"),
+		UseHold:    utils.Ref(true),
+	})
+	if err != nil {
+		t.Fatalf("add payment: %v", err)
+	}
+	if payment.URL == "" {
+		t.Fatalf("expected payment url")
+	}
+
+	if err := srv.Pay(session.ID); err != nil {
+		t.Fatalf("pay: %v", err)
+	}
+	sessionReq := &acquiring.SessionRequest{MerchantID: "1", SessionID: session.ID}
+	if err := client.Acquiring().CompleteHold(ctx, &acquiring.CompleteHoldRequest{MerchantID: "1", SessionID: session.ID}); err != nil {
+		t.Fatalf("complete hold: %v", err)
+	}
+	if err := client.Acquiring().VoidSession(ctx, sessionReq); err != nil {
+		t.Fatalf("void: %v", err)
+	}
+
+	status, err := client.Acquiring().GetStatus(ctx, sessionReq)
+	if err != nil {
+		t.Fatalf("get status: %v", err)
+	}
+	if status.Status != consts.SessionStatusVoided {
+		t.Fatalf("unexpected status: %q", status.Status)
+	}
+	if len(status.Operations) != 1 || status.Operations[0].Amount != money.MustParse("150.25") {
+		t.Fatalf("unexpected operations: %+v", status.Operations)
+	}
+
+	want := []string{"processing", "holded", "processing_hold_completion", "hold_confirmed", "processing_void", "voided"}
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != len(want) {
+		t.Fatalf("postbacks: got %v want %v", received, want)
+	}
+	for i := range want {
+		if received[i] != want[i] {
+			t.Fatalf("postbacks: got %v want %v", received, want)
+		}
+	}
+	for _, d := range srv.Postbacks() {
+		if d.Err != nil {
+			t.Fatalf("postback delivery failed: %v", d.Err)
+		}
+	}
+}
+
+func TestCheckoutTypedResponses(t *testing.T) {
+	client, srv := newClient(t)
+	ctx := context.Background()
+	var (
+		mu       sync.Mutex
+		received []*checkout.Postback
+	)
+	callback := httptest.NewServer(postback.NewCheckoutHandler(client,
+		postback.OnCheckoutStatus(consts.SessionStatusPaid, func(_ context.Context, pb *checkout.Postback) error {
+			mu.Lock()
+			defer mu.Unlock()
+			received = append(received, pb)
+			return nil
+		}),
+	))
+	defer callback.Close()
+
+	session, err := client.Checkout().CreateSession(ctx, &checkout.CreateSessionRequest{
+		MerchantID:           "1",
+		CallbackURL:          callback.URL,
+		CreateExpressWaybill: utils.Ref(true),
+		Delivery:             &checkout.SessionDelivery{VolumeWeight: 1, Weight: 3},
+	})
+	if err != nil {
+		t.Fatalf("create checkout session: %v", err)
+	}
+	if session.ID == "" || session.URL == "" {
+		t.Fatalf("unexpected checkout session: %+v", session)
+	}
+	payment, err := client.Checkout().AddPayment(ctx, &checkout.AddPaymentRequest{
+		MerchantID: "1",
+		SessionID:  session.ID,
+		Amount:     money.MustParse("250.00"),
+		Products:   []checkout.Product{{Description: utils.Ref("Book"), Count: 1, Price: money.MustParse("250.00")}},
+	})
+	if err != nil {
+		t.Fatalf("add checkout payment: %v", err)
+	}
+	if payment.URL == "" {
+		t.Fatalf("unexpected checkout payment: %+v", payment)
+	}
+	if err := srv.Pay(session.ID); err != nil {
+		t.Fatalf("pay: %v", err)
+	}
+
+	status, err := client.Checkout().GetStatus(ctx, &checkout.SessionRequest{MerchantID: "1", SessionID: session.ID})
+	if err != nil {
+		t.Fatalf("checkout get status: %v", err)
+	}
+	if status.Status != consts.SessionStatusPaid || len(status.Operations) != 1 || status.Operations[0].Amount != money.MustParse("250.00") {
+		t.Fatalf("unexpected checkout status: %+v", status)
+	}
+	if status.Delivery == nil || status.Delivery.Price == nil || *status.Delivery.Price != money.FromHryvnias(65) {
+		t.Fatalf("unexpected checkout delivery: %+v", status.Delivery)
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(received) != 1 || received[0].Delivery == nil || received[0].Delivery.Weight != 3 || len(received[0].Payments) != 1 {
+		t.Fatalf("unexpected checkout postbacks: %+v", received)
+	}
+}
+
+func TestRejectsInvalidTransitionsAndSignatures(t *testing.T) {
+	client, _ := newClient(t)
+	ctx := context.Background()
+
+	session, err := client.Acquiring().CreateSession(ctx, &acquiring.CreateSessionRequest{MerchantID: "1", ClientPhone: "+380982850620"})
+	if err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	err = client.Acquiring().CompleteHold(ctx, &acquiring.CompleteHoldRequest{MerchantID: "1", SessionID: session.ID})
+	var apiErr *go_nova.APIError
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 400 || !errors.Is(err, go_nova.ErrInvalidSessionStatus) {
+		t.Fatalf("expected 400 ErrInvalidSessionStatus for complete hold of created session, got %v", err)
+	}
+
+	_, err = client.Acquiring().GetStatus(ctx, &acquiring.SessionRequest{MerchantID: "1", SessionID: "missing"})
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 404 || !errors.Is(err, go_nova.ErrSessionNotFound) {
+		t.Fatalf("expected 404 APIError for unknown session, got %v", err)
+	}
+
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	_, srv := newClient(t)
+	stranger, err := go_nova.NewClient(go_nova.WithPrivateKey(otherKey), go_nova.WithAcquiringBaseURL(srv.URL), go_nova.WithLogger(nil))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	_, err = stranger.Acquiring().CreateSession(ctx, &acquiring.CreateSessionRequest{MerchantID: "1", ClientPhone: "+380982850620"})
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != 401 || !errors.Is(err, go_nova.ErrInvalidSignature) {
+		t.Fatalf("expected 401 APIError for foreign signature, got %v", err)
+	}
+}
+
+func TestComfortPayouts(t *testing.T) {
+	var (
+		handler  http.Handler
+		mu       sync.Mutex
+		statuses []string
+	)
+	callback := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		handler.ServeHTTP(w, r)
+	}))
+	defer callback.Close()
+
+	client, srv := newClient(t, novatest.WithComfortCallbackURL(callback.URL))
+	ctx := context.Background()
+	record := func(_ context.Context, pb *comfort.Postback) error {
+		mu.Lock()
+		defer mu.Unlock()
+		statuses = append(statuses, pb.GUID+":"+string(pb.Status)+":"+pb.Amount.String())
+		return nil
+	}
+	var opts []postback.ComfortOption
+	for _, st := range []consts.OperationStatus{
+		consts.OperationStatusCreated,
+		consts.OperationStatusProcessing,
+		consts.OperationStatusSuccess,
+		consts.OperationStatusFailed,
+		consts.OperationStatusRefunded,
+	} {
+		opts = append(opts, postback.OnComfortStatus(st, record))
+	}
+	handler = postback.NewComfortHandler(client, opts...)
+
+	ops, err := client.Comfort().CreateOperations(ctx, comfort.CreateOperationsRequest{
+		RawBody: []comfort.CreateOperationItem{
+			{GUID: utils.Ref("guid-1"), Amount: money.MustParse("100.00").Quoted()},
+			{Amount: money.MustParse("0.50").Quoted()},
+		},
+	})
+	if err != nil {
+		t.Fatalf("create operations: %v", err)
+	}
+	if len(ops) != 2 || ops[0].GUID != "guid-1" || ops[1].GUID == "" {
+		t.Fatalf("unexpected operations: %+v", ops)
+	}
+
+	balance, err := client.Comfort().Balance(ctx)
+	if err != nil {
+		t.Fatalf("balance: %v", err)
+	}
+	if balance.Balance.String() != "99899.50" {
+		t.Fatalf("unexpected balance: %q", balance.Balance)
+	}
+
+	if err := srv.SetOperationStatus("guid-1", novatest.OperationStatusSuccess); err != nil {
+		t.Fatalf("set operation status: %v", err)
+	}
+	st, err := client.Comfort().OperationsStatus(ctx, &comfort.OperationsStatusRequest{GUID: utils.Ref("guid-1")})
+	if err != nil {
+		t.Fatalf("operations status: %v", err)
+	}
+	if st.Status != novatest.OperationStatusSuccess || st.PublicID != ops[0].PublicID {
+		t.Fatalf("unexpected status: %+v", st)
+	}
+	mu.Lock()
+	if len(statuses) != 1 || statuses[0] != "guid-1:success:100.00" {
+		t.Fatalf("unexpected comfort postbacks: %v", statuses)
+	}
+	mu.Unlock()
+
+	refunded, err := client.Comfort().RefundOperations(ctx, &comfort.RefundOperationsRequest{RawBody: []string{ops[1].PublicID}})
+	if err != nil {
+		t.Fatalf("refund: %v", err)
+	}
+	if len(refunded) != 1 || srv.Balance().String() != "99900.00" {
+		t.Fatalf("unexpected refund result %v, balance %s", refunded, srv.Balance())
+	}
+}
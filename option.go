@@ -3,6 +3,7 @@ package go_nova
 import (
 	"crypto/rsa"
 	"errors"
+	"fmt"
 	"net/http"
 	"os"
 	"strings"
@@ -26,12 +27,24 @@ type config struct {
 	logger     log.Logger
 	logBodies  bool
 
-	retryAttempts int
-	retryWait     time.Duration
-	recorder      recorder.Recorder
+	retryPolicy RetryPolicy
+	recorder    recorder.Recorder
+	middleware  []Middleware
+	tracer      Tracer
+	metrics     Metrics
 
 	externalSigner *signature.RSASigner
 	comfortSigner  *signature.RSASigner
+
+	// externalRequestSigner and comfortRequestSigner override request signing; see WithSigner.
+	externalRequestSigner Signer
+	comfortRequestSigner  Signer
+
+	// inheritedKeys marks verification keyrings copied from the base client of a
+	// merchant; the merchant's first public key option replaces them.
+	inheritedKeys keyScope
+
+	merchants map[string]Merchant
 }
 
 func defaultConfig() config {
@@ -41,8 +54,7 @@ func defaultConfig() config {
 		comfortBaseURL:   consts.DefaultComfortBaseURL,
 		httpClient:       &http.Client{Timeout: 30 * time.Second},
 		logger:           log.NewDefault(),
-		retryAttempts:    1,
-		retryWait:        300 * time.Millisecond,
+		retryPolicy:      BackoffPolicy{MaxAttempts: 1},
 		// External API docs use SHA-256.
 		externalSigner: &signature.RSASigner{Hash: signature.HashSHA256},
 		// Comfort API docs use SHA-1.
@@ -50,6 +62,30 @@ func defaultConfig() config {
 	}
 }
 
+// clone returns a copy of cfg whose signers and http client can be changed
+// without affecting cfg. Registered merchants are not copied.
+func (cfg config) clone() config {
+	out := cfg
+	if cfg.httpClient != nil {
+		hc := *cfg.httpClient
+		out.httpClient = &hc
+	}
+	out.externalSigner = cloneRSASigner(cfg.externalSigner)
+	out.comfortSigner = cloneRSASigner(cfg.comfortSigner)
+	out.middleware = append([]Middleware(nil), cfg.middleware...)
+	out.merchants = nil
+	return out
+}
+
+func cloneRSASigner(s *signature.RSASigner) *signature.RSASigner {
+	if s == nil {
+		return nil
+	}
+	out := *s
+	out.Keys = append([]signature.VerificationKey(nil), s.Keys...)
+	return &out
+}
+
 // WithHTTPClient sets a custom *http.Client.
 func WithHTTPClient(client *http.Client) Option {
 	return func(cfg *config) error {
@@ -106,6 +142,9 @@ func WithRecorder(r recorder.Recorder) Option {
 	}
 }
 
+// WithRetry retries transient failures (429, 5xx, transport errors) with a
+// BackoffPolicy: up to attempts attempts in total, the first wait bounded by wait.
+// By default nothing is retried; see WithRetryPolicy for finer control.
 func WithRetry(attempts int, wait time.Duration) Option {
 	return func(cfg *config) error {
 		if attempts <= 0 {
@@ -114,8 +153,7 @@ func WithRetry(attempts int, wait time.Duration) Option {
 		if wait <= 0 {
 			return errors.New("retry wait must be > 0")
 		}
-		cfg.retryAttempts = attempts
-		cfg.retryWait = wait
+		cfg.retryPolicy = BackoffPolicy{MaxAttempts: attempts, BaseWait: wait}
 		return nil
 	}
 }
@@ -189,74 +227,198 @@ func WithComfortSignatureHash(hash signature.HashAlgorithm) Option {
 	}
 }
 
-// WithPrivateKeyPEM configures the RSA private key used to sign requests.
+// keyScope selects which API signers a key option applies to.
+type keyScope int
+
+const (
+	scopeExternal keyScope = 1 << iota
+	scopeComfort
+
+	scopeAll = scopeExternal | scopeComfort
+)
+
+func (cfg *config) rsaSigners(scope keyScope) []*signature.RSASigner {
+	var out []*signature.RSASigner
+	if scope&scopeExternal != 0 {
+		out = append(out, cfg.externalSigner)
+	}
+	if scope&scopeComfort != 0 {
+		out = append(out, cfg.comfortSigner)
+	}
+	return out
+}
+
+// WithPrivateKeyPEM configures the RSA private key used to sign requests to all APIs.
 func WithPrivateKeyPEM(pemBytes []byte) Option {
+	return privateKeyPEM(scopeAll, pemBytes)
+}
+
+// WithPrivateKeyFile reads a PEM file and sets it as the signing key for all APIs.
+func WithPrivateKeyFile(path string) Option {
+	return privateKeyFile(scopeAll, path)
+}
+
+// WithPrivateKey allows setting already parsed RSA private key.
+func WithPrivateKey(key *rsa.PrivateKey) Option {
+	return privateKey(scopeAll, key)
+}
+
+// WithExternalPrivateKeyPEM configures the private key used to sign Acquiring/Checkout requests.
+func WithExternalPrivateKeyPEM(pemBytes []byte) Option {
+	return privateKeyPEM(scopeExternal, pemBytes)
+}
+
+// WithExternalPrivateKeyFile reads the Acquiring/Checkout signing key from a PEM file.
+func WithExternalPrivateKeyFile(path string) Option {
+	return privateKeyFile(scopeExternal, path)
+}
+
+// WithExternalPrivateKey sets an already parsed Acquiring/Checkout signing key.
+func WithExternalPrivateKey(key *rsa.PrivateKey) Option {
+	return privateKey(scopeExternal, key)
+}
+
+// WithComfortPrivateKeyPEM configures the private key used to sign Comfort requests.
+func WithComfortPrivateKeyPEM(pemBytes []byte) Option {
+	return privateKeyPEM(scopeComfort, pemBytes)
+}
+
+// WithComfortPrivateKeyFile reads the Comfort signing key from a PEM file.
+func WithComfortPrivateKeyFile(path string) Option {
+	return privateKeyFile(scopeComfort, path)
+}
+
+// WithComfortPrivateKey sets an already parsed Comfort signing key.
+func WithComfortPrivateKey(key *rsa.PrivateKey) Option {
+	return privateKey(scopeComfort, key)
+}
+
+func privateKeyPEM(scope keyScope, pemBytes []byte) Option {
 	return func(cfg *config) error {
 		k, err := signature.ParseRSAPrivateKeyPEM(pemBytes)
 		if err != nil {
 			return err
 		}
-		cfg.externalSigner.PrivateKey = k
-		cfg.comfortSigner.PrivateKey = k
-		return nil
+		return privateKey(scope, k)(cfg)
 	}
 }
 
-// WithPrivateKeyFile reads a PEM file and sets it as the signing key.
-func WithPrivateKeyFile(path string) Option {
+func privateKeyFile(scope keyScope, path string) Option {
 	return func(cfg *config) error {
 		b, err := os.ReadFile(path)
 		if err != nil {
 			return err
 		}
-		k, err := signature.ParseRSAPrivateKeyPEM(b)
-		if err != nil {
-			return err
+		return privateKeyPEM(scope, b)(cfg)
+	}
+}
+
+func privateKey(scope keyScope, key *rsa.PrivateKey) Option {
+	return func(cfg *config) error {
+		if key == nil {
+			return errors.New("private key is nil")
+		}
+		for _, s := range cfg.rsaSigners(scope) {
+			s.PrivateKey = key
+			s.Signer = nil
 		}
-		cfg.externalSigner.PrivateKey = k
-		cfg.comfortSigner.PrivateKey = k
+		cfg.clearRequestSigners(scope)
 		return nil
 	}
 }
 
-// WithPublicKeyPEM configures the RSA public key used to verify incoming signatures.
-func WithPublicKeyPEM(pemBytes []byte) Option {
+// WithPublicKeyPEM adds the RSA public key(s) used to verify incoming signatures.
+//
+// pemBytes may hold several PEM blocks; each becomes a keyring entry, tried in order.
+// Calling it again adds more keys instead of replacing them, so old and new keys can
+// be trusted side by side while NovaPay rotates its key. In Merchant options the
+// first call replaces the keys inherited from the client instead.
+func WithPublicKeyPEM(pemBytes []byte, opts ...KeyOption) Option {
+	return publicKeyPEM(scopeAll, pemBytes, opts)
+}
+
+// WithPublicKeyFile reads a PEM file and adds its key(s) to the verification keyring.
+//
+// See WithPublicKeyPEM.
+func WithPublicKeyFile(path string, opts ...KeyOption) Option {
+	return publicKeyFile(scopeAll, path, opts)
+}
+
+// WithPublicKey adds an already parsed RSA public key to the verification keyring.
+func WithPublicKey(key *rsa.PublicKey, opts ...KeyOption) Option {
+	return publicKey(scopeAll, key, opts)
+}
+
+// WithExternalPublicKeyPEM adds key(s) used only by Verify/VerifyKey.
+func WithExternalPublicKeyPEM(pemBytes []byte, opts ...KeyOption) Option {
+	return publicKeyPEM(scopeExternal, pemBytes, opts)
+}
+
+// WithExternalPublicKeyFile reads key(s) used only by Verify/VerifyKey from a PEM file.
+func WithExternalPublicKeyFile(path string, opts ...KeyOption) Option {
+	return publicKeyFile(scopeExternal, path, opts)
+}
+
+// WithExternalPublicKey adds an already parsed key used only by Verify/VerifyKey.
+func WithExternalPublicKey(key *rsa.PublicKey, opts ...KeyOption) Option {
+	return publicKey(scopeExternal, key, opts)
+}
+
+// WithComfortPublicKeyPEM adds key(s) used only by VerifyComfort/VerifyComfortKey.
+func WithComfortPublicKeyPEM(pemBytes []byte, opts ...KeyOption) Option {
+	return publicKeyPEM(scopeComfort, pemBytes, opts)
+}
+
+// WithComfortPublicKeyFile reads key(s) used only by VerifyComfort/VerifyComfortKey from a PEM file.
+func WithComfortPublicKeyFile(path string, opts ...KeyOption) Option {
+	return publicKeyFile(scopeComfort, path, opts)
+}
+
+// WithComfortPublicKey adds an already parsed key used only by VerifyComfort/VerifyComfortKey.
+func WithComfortPublicKey(key *rsa.PublicKey, opts ...KeyOption) Option {
+	return publicKey(scopeComfort, key, opts)
+}
+
+func publicKeyPEM(scope keyScope, pemBytes []byte, opts []KeyOption) Option {
 	return func(cfg *config) error {
-		k, err := signature.ParseRSAPublicKeyPEM(pemBytes)
+		keys, err := signature.ParseRSAPublicKeysPEM(pemBytes)
 		if err != nil {
 			return err
 		}
-		cfg.externalSigner.PublicKey = k
-		cfg.comfortSigner.PublicKey = k
+		addVerificationKeys(cfg.keyrings(scope), keys, opts)
 		return nil
 	}
 }
 
-// WithPublicKeyFile reads a PEM file and sets it as the verification key.
-func WithPublicKeyFile(path string) Option {
+func publicKeyFile(scope keyScope, path string, opts []KeyOption) Option {
 	return func(cfg *config) error {
 		b, err := os.ReadFile(path)
 		if err != nil {
 			return err
 		}
-		k, err := signature.ParseRSAPublicKeyPEM(b)
-		if err != nil {
-			return err
+		if err := publicKeyPEM(scope, b, opts)(cfg); err != nil {
+			return fmt.Errorf("%s: %w", path, err)
 		}
-		cfg.externalSigner.PublicKey = k
-		cfg.comfortSigner.PublicKey = k
 		return nil
 	}
 }
 
-// WithPrivateKey allows setting already parsed RSA private key.
-func WithPrivateKey(key *rsa.PrivateKey) Option {
+func publicKey(scope keyScope, key *rsa.PublicKey, opts []KeyOption) Option {
 	return func(cfg *config) error {
 		if key == nil {
-			return errors.New("private key is nil")
+			return errors.New("public key is nil")
 		}
-		cfg.externalSigner.PrivateKey = key
-		cfg.comfortSigner.PrivateKey = key
+		addVerificationKeys(cfg.keyrings(scope), []*rsa.PublicKey{key}, opts)
+		return nil
+	}
+}
+
+// WithDeprecatedKeyHook registers fn to be called whenever a key marked with
+// KeyDeprecated still verifies a signature.
+func WithDeprecatedKeyHook(fn func(VerificationKey)) Option {
+	return func(cfg *config) error {
+		cfg.externalSigner.OnDeprecatedKey = fn
+		cfg.comfortSigner.OnDeprecatedKey = fn
 		return nil
 	}
 }
@@ -0,0 +1,177 @@
+package go_nova
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+
+	"github.com/stremovskyy/go-nova/comfort"
+	"github.com/stremovskyy/go-nova/consts"
+	"github.com/stremovskyy/go-nova/internal/httpclient"
+	"github.com/stremovskyy/go-nova/internal/utils"
+)
+
+// payoutGUIDNamespace is the UUIDv5 namespace of GUIDs derived from idempotency keys.
+var payoutGUIDNamespace = uuid.MustParse("64187551-bdf2-47b7-9968-8469fa0ebe5f")
+
+// PayoutGUID returns the GUID CreateOperations assigns to item index of a request made
+// with WithIdempotencyKey(key). The same key and index always give the same GUID.
+func PayoutGUID(idempotencyKey string, index int) string {
+	return uuid.NewSHA1(payoutGUIDNamespace, []byte(idempotencyKey+"/"+strconv.Itoa(index))).String()
+}
+
+// WithIdempotencyKey derives the GUIDs of CreateOperations items without one from key
+// (see PayoutGUID), so repeating the call with the same key cannot create new payouts.
+//
+// Without it, items without GUID get a random UUID.
+func WithIdempotencyKey(key string) RunOption {
+	return func(o *runOptions) {
+		o.idempotencyKey = key
+	}
+}
+
+// WithPayoutReconcile makes CreateOperations recover from a failed create.
+//
+// When the outcome of the create is unclear (transport error, 5xx, 429 or an
+// unreadable response), the GUIDs are looked up with OperationsStatus first:
+//   - all found: the payouts were created and are returned without error;
+//   - none found: the create is sent again, up to the client retry attempts;
+//   - otherwise a *PayoutReconcileError describes what is known.
+//
+// Without it a failed create is returned as is and never retried.
+func WithPayoutReconcile() RunOption {
+	return func(o *runOptions) {
+		o.reconcilePayouts = true
+	}
+}
+
+// PayoutReconcileError is returned by CreateOperations with WithPayoutReconcile when
+// a failed create can neither be confirmed nor safely retried.
+type PayoutReconcileError struct {
+	// Created lists the operations found by OperationsStatus.
+	Created []comfort.CreateOperationsResponseItem
+	// Missing lists GUIDs NovaPay reported as not found.
+	Missing []string
+	// Unknown lists GUIDs whose status could not be read, including answers without
+	// a public ID or with an unknown status.
+	Unknown []string
+	// Err is the error of the create call.
+	Err error
+}
+
+func (e *PayoutReconcileError) Error() string {
+	if e == nil {
+		return "payout reconcile error"
+	}
+	return fmt.Sprintf("create operations: %v (reconciled: %d created, %d missing, %d unknown)", e.Err, len(e.Created), len(e.Missing), len(e.Unknown))
+}
+
+func (e *PayoutReconcileError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Err
+}
+
+// withPayoutGUIDs returns a copy of req where every item has a GUID.
+func withPayoutGUIDs(req comfort.CreateOperationsRequest, idempotencyKey string) comfort.CreateOperationsRequest {
+	items := make([]comfort.CreateOperationItem, len(req.RawBody))
+	copy(items, req.RawBody)
+	for i := range items {
+		if items[i].GUID != nil && *items[i].GUID != "" {
+			continue
+		}
+		guid := uuid.NewString()
+		if idempotencyKey != "" {
+			guid = PayoutGUID(idempotencyKey, i)
+		}
+		items[i].GUID = utils.Ref(guid)
+	}
+	req.RawBody = items
+	return req
+}
+
+// createOperations sends a create request once, or reconciles and retries it; see WithPayoutReconcile.
+func (s *ComfortService) createOperations(ctx context.Context, url string, req comfort.CreateOperationsRequest, opts *runOptions) ([]comfort.CreateOperationsResponseItem, error) {
+	var resp *http.Response
+	send := func() ([]comfort.CreateOperationsResponseItem, error) {
+		var out []comfort.CreateOperationsResponseItem
+		var err error
+		resp, _, err = s.c.comfortHTTP.DoJSON(ctx, "POST", url, req, &out, append(s.c.requestOptions("POST", consts.ComfortCreateOperationsPath, nil), httpclient.NoRetry())...)
+		return out, wrapAPIError(err)
+	}
+	if !opts.reconcilePayouts {
+		return send()
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	policy := s.c.callRetryPolicy(opts)
+	for attempt := 1; ; attempt++ {
+		out, err := send()
+		if err == nil || !payoutOutcomeUnclear(err) {
+			return out, err
+		}
+
+		rec := s.reconcileOperations(ctx, req, err)
+		switch {
+		case len(rec.Unknown) == 0 && len(rec.Missing) == 0:
+			return rec.Created, nil
+		case len(rec.Unknown) > 0 || len(rec.Created) > 0:
+			return nil, rec
+		}
+		a := RetryAttempt{Attempt: attempt, Method: "POST", URL: url, Err: err}
+		if resp != nil {
+			a.StatusCode, a.Header = resp.StatusCode, resp.Header
+		}
+		wait, retry := policy.Next(a)
+		if !retry {
+			return nil, rec
+		}
+
+		s.c.cfg.logger.Warnf("[NovaPay] create operations failed and no payout was created, retrying: attempt=%d wait=%s err=%v", attempt, wait, err)
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, err
+		case <-timer.C:
+		}
+	}
+}
+
+// payoutOutcomeUnclear reports whether a failed create may still have created payouts.
+func payoutOutcomeUnclear(err error) bool {
+	var ve *ValidationError
+	if errors.As(err, &ve) {
+		return false
+	}
+	var ae *APIError
+	if errors.As(err, &ae) {
+		return ae.StatusCode >= http.StatusInternalServerError || ae.StatusCode == http.StatusTooManyRequests
+	}
+	return true
+}
+
+func (s *ComfortService) reconcileOperations(ctx context.Context, req comfort.CreateOperationsRequest, createErr error) *PayoutReconcileError {
+	rec := &PayoutReconcileError{Err: createErr}
+	for _, item := range req.RawBody {
+		guid := *item.GUID
+		st, err := s.OperationsStatus(ctx, &comfort.OperationsStatusRequest{GUID: utils.Ref(guid)})
+		switch {
+		case err == nil && st != nil && st.PublicID != "" && st.Status.IsKnown():
+			rec.Created = append(rec.Created, comfort.CreateOperationsResponseItem{GUID: guid, PublicID: st.PublicID})
+		case errors.Is(err, ErrOperationNotFound):
+			rec.Missing = append(rec.Missing, guid)
+		default:
+			rec.Unknown = append(rec.Unknown, guid)
+		}
+	}
+	return rec
+}
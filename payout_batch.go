@@ -0,0 +1,225 @@
+package go_nova
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+
+	"github.com/stremovskyy/go-nova/comfort"
+	"github.com/stremovskyy/go-nova/consts"
+)
+
+// Defaults used by CreateOperationsBatch.
+const (
+	DefaultBatchChunkSize   = 100
+	DefaultBatchConcurrency = 4
+)
+
+// WithBatchChunkSize sets how many items CreateOperationsBatch sends per request.
+func WithBatchChunkSize(n int) RunOption {
+	return func(o *runOptions) {
+		o.batchChunkSize = n
+	}
+}
+
+// WithBatchConcurrency sets how many CreateOperationsBatch requests run at once.
+func WithBatchConcurrency(n int) RunOption {
+	return func(o *runOptions) {
+		o.batchConcurrency = n
+	}
+}
+
+// PayoutResultStatus is the outcome of one payout item of a batch.
+type PayoutResultStatus string
+
+const (
+	// PayoutAccepted means NovaPay created the operation.
+	PayoutAccepted PayoutResultStatus = "accepted"
+	// PayoutRejected means the operation was definitely not created and may be sent again.
+	PayoutRejected PayoutResultStatus = "rejected"
+	// PayoutUnknown means the outcome is unclear; check OperationsStatus before resending.
+	PayoutUnknown PayoutResultStatus = "unknown"
+)
+
+// PayoutResult is the outcome of one item of CreateOperationsBatch.
+type PayoutResult struct {
+	GUID     string
+	PublicID string
+	Status   PayoutResultStatus
+	// Err is the error of the chunk the item was sent in, nil for accepted items.
+	Err error
+}
+
+// PayoutBatchReport lists the outcome of every item of CreateOperationsBatch, in request order.
+type PayoutBatchReport struct {
+	// Operations are the created operations, in request order.
+	Operations []comfort.CreateOperationsResponseItem
+	Results    []PayoutResult
+}
+
+// Count returns the number of items with the given status.
+func (r *PayoutBatchReport) Count(status PayoutResultStatus) int {
+	if r == nil {
+		return 0
+	}
+	n := 0
+	for _, res := range r.Results {
+		if res.Status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// PayoutBatchError is returned by CreateOperationsBatch when some items were not accepted.
+type PayoutBatchError struct {
+	Report *PayoutBatchReport
+}
+
+func (e *PayoutBatchError) Error() string {
+	if e == nil || e.Report == nil {
+		return "create operations batch failed"
+	}
+	return fmt.Sprintf("create operations batch: %d accepted, %d rejected, %d unknown",
+		e.Report.Count(PayoutAccepted), e.Report.Count(PayoutRejected), e.Report.Count(PayoutUnknown))
+}
+
+// CreateOperationsBatch sends payouts in chunks (WithBatchChunkSize) with bounded
+// concurrency (WithBatchConcurrency).
+//
+// GUIDs are assigned to the whole request first, exactly like CreateOperations, and
+// every chunk is sent like CreateOperations (WithPayoutReconcile applies per chunk).
+// The report is always returned; a *PayoutBatchError is returned as well when some
+// items were rejected or their outcome is unknown.
+func (s *ComfortService) CreateOperationsBatch(ctx context.Context, req comfort.CreateOperationsRequest, runOpts ...RunOption) (_ *PayoutBatchReport, err error) {
+	if s == nil || s.c == nil {
+		return nil, errors.New("client is nil")
+	}
+	ctx, span := s.c.startSpan(ctx, "ComfortService.CreateOperationsBatch")
+	defer func() { endSpan(span, err) }()
+	setSessionAttrs(span, s.c.cfg.comfortMerchantID, "")
+	if err := ensureComfortReady(s.c); err != nil {
+		return nil, err
+	}
+	if err := validateComfortCreateOperations(req); err != nil {
+		return nil, err
+	}
+	opts := collectRunOptions(runOpts)
+	if opts == nil {
+		opts = &runOptions{}
+	}
+	req = withPayoutGUIDs(req, opts.idempotencyKey)
+
+	full, err := joinURL(s.c.cfg.comfortBaseURL, consts.ComfortCreateOperationsPath)
+	if err != nil {
+		return nil, err
+	}
+	if s.c.shouldDryRun(runOpts, "POST", full, req) {
+		return nil, nil
+	}
+	size := opts.batchChunkSize
+	if size <= 0 {
+		size = DefaultBatchChunkSize
+	}
+	workers := opts.batchConcurrency
+	if workers <= 0 {
+		workers = DefaultBatchConcurrency
+	}
+
+	var chunks [][]comfort.CreateOperationItem
+	for start := 0; start < len(req.RawBody); start += size {
+		end := min(start+size, len(req.RawBody))
+		chunks = append(chunks, req.RawBody[start:end])
+	}
+
+	results := make([][]PayoutResult, len(chunks))
+	sem := make(chan struct{}, workers)
+	var wg sync.WaitGroup
+	for i, chunk := range chunks {
+		// Chunks are started in order; the ones not started when ctx ends were never sent.
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if err := ctx.Err(); err != nil {
+			results[i] = notSentResults(chunk, err)
+			continue
+		}
+		wg.Add(1)
+		go func(i int, chunk []comfort.CreateOperationItem) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			out, err := s.createOperations(ctx, full, comfort.CreateOperationsRequest{RawBody: chunk}, opts)
+			results[i] = chunkResults(chunk, out, err)
+		}(i, chunk)
+	}
+	wg.Wait()
+
+	report := &PayoutBatchReport{}
+	for _, rs := range results {
+		for _, r := range rs {
+			report.Results = append(report.Results, r)
+			if r.Status == PayoutAccepted {
+				report.Operations = append(report.Operations, comfort.CreateOperationsResponseItem{GUID: r.GUID, PublicID: r.PublicID})
+			}
+		}
+	}
+	if report.Count(PayoutAccepted) != len(report.Results) {
+		return report, &PayoutBatchError{Report: report}
+	}
+	return report, nil
+}
+
+func notSentResults(chunk []comfort.CreateOperationItem, err error) []PayoutResult {
+	results := make([]PayoutResult, 0, len(chunk))
+	for _, item := range chunk {
+		results = append(results, PayoutResult{GUID: *item.GUID, Status: PayoutRejected, Err: err})
+	}
+	return results
+}
+
+// chunkResults classifies the items of one chunk from the create outcome.
+func chunkResults(chunk []comfort.CreateOperationItem, out []comfort.CreateOperationsResponseItem, err error) []PayoutResult {
+	created := map[string]string{}
+	missing := map[string]bool{}
+	unclear := err != nil && payoutOutcomeUnclear(err)
+
+	var re *PayoutReconcileError
+	switch {
+	case err == nil:
+		for _, item := range out {
+			created[item.GUID] = item.PublicID
+		}
+	case errors.As(err, &re):
+		for _, item := range re.Created {
+			created[item.GUID] = item.PublicID
+		}
+		for _, guid := range re.Missing {
+			missing[guid] = true
+		}
+	}
+
+	results := make([]PayoutResult, 0, len(chunk))
+	for _, item := range chunk {
+		guid := *item.GUID
+		r := PayoutResult{GUID: guid}
+		if id, ok := created[guid]; ok {
+			r.Status, r.PublicID = PayoutAccepted, id
+			results = append(results, r)
+			continue
+		}
+		r.Err = err
+		switch {
+		case err == nil:
+			// A 2xx response that does not mention the GUID.
+			r.Status = PayoutUnknown
+		case missing[guid] || !unclear:
+			r.Status = PayoutRejected
+		default:
+			r.Status = PayoutUnknown
+		}
+		results = append(results, r)
+	}
+	return results
+}
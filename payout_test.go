@@ -0,0 +1,248 @@
+package go_nova
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stremovskyy/go-nova/comfort"
+	"github.com/stremovskyy/go-nova/internal/utils"
+	"github.com/stremovskyy/go-nova/money"
+)
+
+// flakyComfort is a Comfort API whose create endpoint fails according to failCreate.
+type flakyComfort struct {
+	mu    sync.Mutex
+	ops   map[string]string
+	calls int
+	// failCreate returns whether call n (1-based) fails and whether the payouts are stored anyway.
+	failCreate func(n int) (fail bool, store bool)
+	// emptyStatus answers status lookups with an empty 200 body.
+	emptyStatus bool
+}
+
+func (f *flakyComfort) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	switch r.URL.Path {
+	case "/v1/operations/create":
+		f.calls++
+		var req comfort.CreateOperationsRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		fail, store := f.failCreate(f.calls)
+		var out []comfort.CreateOperationsResponseItem
+		if !fail || store {
+			for _, item := range req.RawBody {
+				f.ops[*item.GUID] = "op-" + *item.GUID
+				out = append(out, comfort.CreateOperationsResponseItem{GUID: *item.GUID, PublicID: f.ops[*item.GUID]})
+			}
+		}
+		if fail {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		_ = json.NewEncoder(w).Encode(out)
+	case "/v1/operations/status":
+		if f.emptyStatus {
+			_, _ = w.Write([]byte(`{}`))
+			return
+		}
+		var req comfort.OperationsStatusRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		id, ok := f.ops[*req.GUID]
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"code":"operation_not_found"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(comfort.OperationsStatusResponse{Status: "created", PublicID: id})
+	default:
+		http.NotFound(w, r)
+	}
+}
+
+func newPayoutClient(t *testing.T, f *flakyComfort) Nova {
+	t.Helper()
+	f.ops = map[string]string{}
+	ts := httptest.NewServer(f)
+	t.Cleanup(ts.Close)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	client, err := NewClient(
+		WithPrivateKey(key),
+		WithComfortBaseURL(ts.URL),
+		WithComfortMerchantID("42"),
+		WithRetry(3, time.Millisecond),
+		WithLogger(nil),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	return client
+}
+
+func payoutRequest() comfort.CreateOperationsRequest {
+	return comfort.CreateOperationsRequest{RawBody: []comfort.CreateOperationItem{
+		{Amount: money.MustParse("10.00").Quoted()},
+		{GUID: utils.Ref("own-guid"), Amount: money.MustParse("20.00").Quoted()},
+	}}
+}
+
+func TestCreateOperationsAssignsGUIDs(t *testing.T) {
+	var bodies []comfort.CreateOperationsRequest
+	client := newPayoutClient(t, &flakyComfort{failCreate: func(int) (bool, bool) { return false, false }})
+
+	req := payoutRequest()
+	for i := 0; i < 2; i++ {
+		_, err := client.Comfort().CreateOperations(context.Background(), req, WithIdempotencyKey("order-7"), DryRun(func(_ string, _ string, payload any) {
+			bodies = append(bodies, payload.(comfort.CreateOperationsRequest))
+		}))
+		if err != nil {
+			t.Fatalf("create operations: %v", err)
+		}
+	}
+	if req.RawBody[0].GUID != nil {
+		t.Fatalf("caller's request must not be modified")
+	}
+	first, second := bodies[0].RawBody, bodies[1].RawBody
+	if *first[0].GUID != PayoutGUID("order-7", 0) || *first[0].GUID != *second[0].GUID {
+		t.Fatalf("GUID must be derived from the idempotency key: %s, %s", *first[0].GUID, *second[0].GUID)
+	}
+	if *first[1].GUID != "own-guid" {
+		t.Fatalf("explicit GUID must be kept, got %s", *first[1].GUID)
+	}
+
+	out, err := client.Comfort().CreateOperations(context.Background(), req)
+	if err != nil {
+		t.Fatalf("create operations: %v", err)
+	}
+	if len(out) != 2 || out[0].GUID == "" || out[0].GUID == *first[0].GUID {
+		t.Fatalf("expected a random GUID without idempotency key, got %+v", out)
+	}
+}
+
+func TestCreateOperationsIsNotRetriedBlindly(t *testing.T) {
+	f := &flakyComfort{failCreate: func(int) (bool, bool) { return true, true }}
+	client := newPayoutClient(t, f)
+
+	if _, err := client.Comfort().CreateOperations(context.Background(), payoutRequest()); err == nil {
+		t.Fatalf("expected error")
+	}
+	if f.calls != 1 {
+		t.Fatalf("create must be sent once, got %d calls", f.calls)
+	}
+}
+
+func TestCreateOperationsReconcile(t *testing.T) {
+	t.Run("response lost", func(t *testing.T) {
+		f := &flakyComfort{failCreate: func(n int) (bool, bool) { return true, true }}
+		client := newPayoutClient(t, f)
+
+		out, err := client.Comfort().CreateOperations(context.Background(), payoutRequest(), WithPayoutReconcile())
+		if err != nil {
+			t.Fatalf("reconcile: %v", err)
+		}
+		if f.calls != 1 || len(out) != 2 || out[1].PublicID != "op-own-guid" {
+			t.Fatalf("expected reconciled payouts after 1 call, got %d calls and %+v", f.calls, out)
+		}
+	})
+
+	t.Run("not created", func(t *testing.T) {
+		f := &flakyComfort{failCreate: func(n int) (bool, bool) { return n == 1, false }}
+		client := newPayoutClient(t, f)
+
+		out, err := client.Comfort().CreateOperations(context.Background(), payoutRequest(), WithPayoutReconcile())
+		if err != nil {
+			t.Fatalf("reconcile: %v", err)
+		}
+		if f.calls != 2 || len(out) != 2 || len(f.ops) != 2 {
+			t.Fatalf("expected one safe retry, got %d calls, %d payouts", f.calls, len(f.ops))
+		}
+	})
+
+	t.Run("empty status", func(t *testing.T) {
+		f := &flakyComfort{failCreate: func(n int) (bool, bool) { return true, false }, emptyStatus: true}
+		client := newPayoutClient(t, f)
+
+		_, err := client.Comfort().CreateOperations(context.Background(), payoutRequest(), WithPayoutReconcile())
+		var rec *PayoutReconcileError
+		if !errors.As(err, &rec) || len(rec.Created) != 0 || len(rec.Unknown) != 2 {
+			t.Fatalf("an empty status must not confirm payouts, got %v", err)
+		}
+		if f.calls != 1 {
+			t.Fatalf("create must not be re-sent, got %d calls", f.calls)
+		}
+	})
+}
+
+func batchRequest(n int) comfort.CreateOperationsRequest {
+	var req comfort.CreateOperationsRequest
+	for i := 0; i < n; i++ {
+		req.RawBody = append(req.RawBody, comfort.CreateOperationItem{Amount: money.MustParse("1.00").Quoted()})
+	}
+	return req
+}
+
+func TestCreateOperationsBatch(t *testing.T) {
+	f := &flakyComfort{failCreate: func(int) (bool, bool) { return false, false }}
+	client := newPayoutClient(t, f)
+
+	report, err := client.Comfort().CreateOperationsBatch(context.Background(), batchRequest(5),
+		WithIdempotencyKey("batch-1"), WithBatchChunkSize(2), WithBatchConcurrency(2))
+	if err != nil {
+		t.Fatalf("batch: %v", err)
+	}
+	if f.calls != 3 || len(report.Operations) != 5 {
+		t.Fatalf("expected 5 payouts in 3 calls, got %d calls and %+v", f.calls, report)
+	}
+	for i, r := range report.Results {
+		if r.GUID != PayoutGUID("batch-1", i) || r.Status != PayoutAccepted || report.Operations[i].GUID != r.GUID {
+			t.Fatalf("result %d: %+v", i, r)
+		}
+	}
+}
+
+func TestCreateOperationsBatchPartialFailure(t *testing.T) {
+	tests := []struct {
+		name      string
+		reconcile bool
+		want      PayoutResultStatus
+	}{
+		{name: "outcome unknown", want: PayoutUnknown},
+		{name: "reconciled as missing", reconcile: true, want: PayoutRejected},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// Every create after the first fails without storing anything.
+			f := &flakyComfort{failCreate: func(n int) (bool, bool) { return n > 1, false }}
+			client := newPayoutClient(t, f)
+
+			opts := []RunOption{WithBatchChunkSize(2), WithBatchConcurrency(1)}
+			if tt.reconcile {
+				opts = append(opts, WithPayoutReconcile())
+			}
+			report, err := client.Comfort().CreateOperationsBatch(context.Background(), batchRequest(3), opts...)
+
+			var be *PayoutBatchError
+			if !errors.As(err, &be) || be.Report != report {
+				t.Fatalf("expected PayoutBatchError, got %v", err)
+			}
+			if report.Count(PayoutAccepted) != 2 || len(report.Operations) != 2 {
+				t.Fatalf("expected the first chunk accepted, got %+v", report.Results)
+			}
+			if r := report.Results[2]; r.Status != tt.want || r.Err == nil {
+				t.Fatalf("expected last item %s, got %+v", tt.want, r)
+			}
+		})
+	}
+}
@@ -0,0 +1,92 @@
+package postback
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/stremovskyy/go-nova/checkout"
+	"github.com/stremovskyy/go-nova/consts"
+)
+
+// CheckoutHandlerFunc processes a verified checkout postback.
+//
+// Returning an error makes the handler answer 500, so NovaPay delivers the callback again.
+type CheckoutHandlerFunc func(ctx context.Context, pb *checkout.Postback) error
+
+// CheckoutHandler is an http.Handler for the postbacks sent to a checkout session's callback_url.
+type CheckoutHandler struct {
+	verifier      Verifier
+	handlers      map[consts.SessionStatus]CheckoutHandlerFunc
+	onUnknown     CheckoutHandlerFunc
+	onVerifyError VerifyErrorFunc
+	maxBodyBytes  int64
+}
+
+// CheckoutOption configures CheckoutHandler.
+type CheckoutOption func(*CheckoutHandler)
+
+// NewCheckoutHandler creates a checkout postback handler.
+//
+// It answers like Handler; postbacks are rejected with 401 when verifier is nil.
+func NewCheckoutHandler(verifier Verifier, opts ...CheckoutOption) *CheckoutHandler {
+	h := &CheckoutHandler{
+		verifier:     verifier,
+		handlers:     map[consts.SessionStatus]CheckoutHandlerFunc{},
+		maxBodyBytes: DefaultMaxBodyBytes,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(h)
+		}
+	}
+	return h
+}
+
+// OnCheckoutStatus registers fn for checkout postbacks with the given status.
+func OnCheckoutStatus(status consts.SessionStatus, fn CheckoutHandlerFunc) CheckoutOption {
+	return func(h *CheckoutHandler) {
+		if fn == nil {
+			delete(h.handlers, status)
+			return
+		}
+		h.handlers[status] = fn
+	}
+}
+
+// OnCheckoutUnknownStatus registers fn for checkout postbacks whose status is not a
+// documented consts.SessionStatus.
+func OnCheckoutUnknownStatus(fn CheckoutHandlerFunc) CheckoutOption {
+	return func(h *CheckoutHandler) {
+		h.onUnknown = fn
+	}
+}
+
+// OnCheckoutVerifyError registers fn to observe rejected signatures.
+func OnCheckoutVerifyError(fn VerifyErrorFunc) CheckoutOption {
+	return func(h *CheckoutHandler) {
+		h.onVerifyError = fn
+	}
+}
+
+// WithCheckoutMaxBodyBytes limits the accepted postback body size.
+func WithCheckoutMaxBodyBytes(n int64) CheckoutOption {
+	return func(h *CheckoutHandler) {
+		if n > 0 {
+			h.maxBodyBytes = n
+		}
+	}
+}
+
+// ServeHTTP verifies, decodes and dispatches a checkout postback. See Handler.ServeHTTP.
+func (h *CheckoutHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, ok := readVerified(w, r, h.maxBodyBytes, h.verifier, h.onVerifyError)
+	if !ok {
+		return
+	}
+	dispatch(w, r, body, h.handlers, h.onUnknown, func(pb *checkout.Postback) consts.SessionStatus { return pb.Status })
+}
+
+// ParseCheckout verifies body against xSign and decodes it into a checkout postback.
+func ParseCheckout(verifier Verifier, body []byte, xSign string) (*checkout.Postback, error) {
+	return parse[checkout.Postback](verifier, body, xSign)
+}
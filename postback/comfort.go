@@ -0,0 +1,116 @@
+package postback
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/stremovskyy/go-nova/comfort"
+	"github.com/stremovskyy/go-nova/consts"
+)
+
+// ComfortVerifier checks the x-sign of a Comfort callback body (SHA-1 by default).
+//
+// go_nova.Nova satisfies this interface.
+type ComfortVerifier interface {
+	VerifyComfort(body []byte, xSign string) error
+}
+
+// comfortVerifier adapts ComfortVerifier to Verifier.
+type comfortVerifier struct{ v ComfortVerifier }
+
+func (c comfortVerifier) Verify(body []byte, xSign string) error {
+	return c.v.VerifyComfort(body, xSign)
+}
+
+func asVerifier(v ComfortVerifier) Verifier {
+	if v == nil {
+		return nil
+	}
+	return comfortVerifier{v: v}
+}
+
+// ComfortHandlerFunc processes a verified Comfort payout postback.
+//
+// Returning an error makes the handler answer 500, so NovaPay delivers the callback again.
+type ComfortHandlerFunc func(ctx context.Context, pb *comfort.Postback) error
+
+// ComfortHandler is an http.Handler for Comfort payout status callbacks.
+type ComfortHandler struct {
+	verifier      Verifier
+	handlers      map[consts.OperationStatus]ComfortHandlerFunc
+	onUnknown     ComfortHandlerFunc
+	onVerifyError VerifyErrorFunc
+	maxBodyBytes  int64
+}
+
+// ComfortOption configures ComfortHandler.
+type ComfortOption func(*ComfortHandler)
+
+// NewComfortHandler creates a Comfort postback handler that verifies x-sign with
+// verifier.VerifyComfort.
+//
+// It answers like Handler; postbacks are rejected with 401 when verifier is nil.
+func NewComfortHandler(verifier ComfortVerifier, opts ...ComfortOption) *ComfortHandler {
+	h := &ComfortHandler{
+		verifier:     asVerifier(verifier),
+		handlers:     map[consts.OperationStatus]ComfortHandlerFunc{},
+		maxBodyBytes: DefaultMaxBodyBytes,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(h)
+		}
+	}
+	return h
+}
+
+// OnComfortStatus registers fn for payout postbacks with the given operation status.
+func OnComfortStatus(status consts.OperationStatus, fn ComfortHandlerFunc) ComfortOption {
+	return func(h *ComfortHandler) {
+		if fn == nil {
+			delete(h.handlers, status)
+			return
+		}
+		h.handlers[status] = fn
+	}
+}
+
+// OnComfortUnknownStatus registers fn for payout postbacks whose status is not a
+// documented consts.OperationStatus.
+//
+// Without it such postbacks are acknowledged with 200 and dropped.
+func OnComfortUnknownStatus(fn ComfortHandlerFunc) ComfortOption {
+	return func(h *ComfortHandler) {
+		h.onUnknown = fn
+	}
+}
+
+// OnComfortVerifyError registers fn to observe rejected signatures.
+func OnComfortVerifyError(fn VerifyErrorFunc) ComfortOption {
+	return func(h *ComfortHandler) {
+		h.onVerifyError = fn
+	}
+}
+
+// WithComfortMaxBodyBytes limits the accepted postback body size.
+func WithComfortMaxBodyBytes(n int64) ComfortOption {
+	return func(h *ComfortHandler) {
+		if n > 0 {
+			h.maxBodyBytes = n
+		}
+	}
+}
+
+// ServeHTTP verifies, decodes and dispatches a Comfort postback. See Handler.ServeHTTP.
+func (h *ComfortHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, ok := readVerified(w, r, h.maxBodyBytes, h.verifier, h.onVerifyError)
+	if !ok {
+		return
+	}
+	dispatch(w, r, body, h.handlers, h.onUnknown, func(pb *comfort.Postback) consts.OperationStatus { return pb.Status })
+}
+
+// ParseComfort verifies body with verifier.VerifyComfort and decodes it into a Comfort postback.
+func ParseComfort(verifier ComfortVerifier, body []byte, xSign string) (*comfort.Postback, error) {
+	return parse[comfort.Postback](asVerifier(verifier), body, xSign)
+}
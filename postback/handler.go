@@ -0,0 +1,173 @@
+// Package postback provides ready-made HTTP handlers for NovaPay callbacks.
+//
+// Handlers verify the x-sign header, decode the payload and dispatch it to
+// callbacks registered per session status (acquiring and checkout) or per payout
+// operation status (Comfort).
+package postback
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/stremovskyy/go-nova/acquiring"
+	"github.com/stremovskyy/go-nova/consts"
+)
+
+// DefaultMaxBodyBytes limits the size of a postback body read by handlers.
+const DefaultMaxBodyBytes int64 = 1 << 20
+
+// ErrMissingSignature is returned when a postback has no x-sign header.
+var ErrMissingSignature = errors.New("postback: missing x-sign header")
+
+// Verifier checks the x-sign of an incoming postback body.
+//
+// go_nova.Nova satisfies this interface.
+type Verifier interface {
+	Verify(body []byte, xSign string) error
+}
+
+// HandlerFunc processes a verified acquiring postback.
+//
+// Returning an error makes the handler answer 500, so NovaPay delivers the callback again.
+type HandlerFunc func(ctx context.Context, pb *acquiring.Postback) error
+
+// VerifyErrorFunc is called when a postback fails signature verification.
+type VerifyErrorFunc func(r *http.Request, err error)
+
+// Handler is an http.Handler for acquiring postbacks.
+type Handler struct {
+	verifier      Verifier
+	handlers      map[consts.SessionStatus]HandlerFunc
+	onUnknown     HandlerFunc
+	onVerifyError VerifyErrorFunc
+	maxBodyBytes  int64
+}
+
+// Option configures Handler.
+type Option func(*Handler)
+
+// NewHandler creates an acquiring postback handler.
+//
+// Postbacks are rejected with 401 when verifier is nil.
+func NewHandler(verifier Verifier, opts ...Option) *Handler {
+	h := &Handler{
+		verifier:     verifier,
+		handlers:     map[consts.SessionStatus]HandlerFunc{},
+		maxBodyBytes: DefaultMaxBodyBytes,
+	}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(h)
+		}
+	}
+	return h
+}
+
+// OnStatus registers fn for postbacks with the given status.
+func OnStatus(status consts.SessionStatus, fn HandlerFunc) Option {
+	return func(h *Handler) {
+		if fn == nil {
+			delete(h.handlers, status)
+			return
+		}
+		h.handlers[status] = fn
+	}
+}
+
+// OnCreated registers fn for postbacks with status "created".
+func OnCreated(fn HandlerFunc) Option {
+	return OnStatus(consts.SessionStatusCreated, fn)
+}
+
+// OnExpired registers fn for postbacks with status "expired".
+func OnExpired(fn HandlerFunc) Option {
+	return OnStatus(consts.SessionStatusExpired, fn)
+}
+
+// OnProcessing registers fn for postbacks with status "processing".
+func OnProcessing(fn HandlerFunc) Option {
+	return OnStatus(consts.SessionStatusProcessing, fn)
+}
+
+// OnHolded registers fn for postbacks with status "holded".
+func OnHolded(fn HandlerFunc) Option {
+	return OnStatus(consts.SessionStatusHolded, fn)
+}
+
+// OnHoldConfirmed registers fn for postbacks with status "hold_confirmed".
+func OnHoldConfirmed(fn HandlerFunc) Option {
+	return OnStatus(consts.SessionStatusHoldConfirmed, fn)
+}
+
+// OnProcessingHoldCompletion registers fn for postbacks with status "processing_hold_completion".
+func OnProcessingHoldCompletion(fn HandlerFunc) Option {
+	return OnStatus(consts.SessionStatusProcessingHoldCompletion, fn)
+}
+
+// OnPaid registers fn for postbacks with status "paid".
+func OnPaid(fn HandlerFunc) Option {
+	return OnStatus(consts.SessionStatusPaid, fn)
+}
+
+// OnFailed registers fn for postbacks with status "failed".
+func OnFailed(fn HandlerFunc) Option {
+	return OnStatus(consts.SessionStatusFailed, fn)
+}
+
+// OnProcessingVoid registers fn for postbacks with status "processing_void".
+func OnProcessingVoid(fn HandlerFunc) Option {
+	return OnStatus(consts.SessionStatusProcessingVoid, fn)
+}
+
+// OnVoided registers fn for postbacks with status "voided".
+func OnVoided(fn HandlerFunc) Option {
+	return OnStatus(consts.SessionStatusVoided, fn)
+}
+
+// OnUnknownStatus registers fn for postbacks whose status is not a documented consts.SessionStatus.
+//
+// Without it such postbacks are acknowledged with 200 and dropped.
+func OnUnknownStatus(fn HandlerFunc) Option {
+	return func(h *Handler) {
+		h.onUnknown = fn
+	}
+}
+
+// OnVerifyError registers fn to observe rejected signatures, e.g. for logging or alerting.
+func OnVerifyError(fn VerifyErrorFunc) Option {
+	return func(h *Handler) {
+		h.onVerifyError = fn
+	}
+}
+
+// WithMaxBodyBytes limits the accepted postback body size.
+func WithMaxBodyBytes(n int64) Option {
+	return func(h *Handler) {
+		if n > 0 {
+			h.maxBodyBytes = n
+		}
+	}
+}
+
+// ServeHTTP verifies, decodes and dispatches a postback.
+//
+// Responses:
+//   - 200: postback accepted (or no callback registered for its status)
+//   - 400: body cannot be read or decoded
+//   - 401: missing or invalid x-sign
+//   - 405: method is not POST
+//   - 500: callback returned an error
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	body, ok := readVerified(w, r, h.maxBodyBytes, h.verifier, h.onVerifyError)
+	if !ok {
+		return
+	}
+
+	dispatch(w, r, body, h.handlers, h.onUnknown, func(pb *acquiring.Postback) consts.SessionStatus { return pb.Status })
+}
+
+// Parse verifies body against xSign and decodes it into an acquiring postback.
+func Parse(verifier Verifier, body []byte, xSign string) (*acquiring.Postback, error) {
+	return parse[acquiring.Postback](verifier, body, xSign)
+}
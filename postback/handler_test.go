@@ -0,0 +1,248 @@
+package postback
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stremovskyy/go-nova/acquiring"
+	"github.com/stremovskyy/go-nova/checkout"
+	"github.com/stremovskyy/go-nova/comfort"
+	"github.com/stremovskyy/go-nova/consts"
+	"github.com/stremovskyy/go-nova/internal/signature"
+)
+
+func TestHandlerDispatchesByStatus(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	signer := &signature.RSASigner{PrivateKey: key, PublicKey: &key.PublicKey, Hash: signature.HashSHA256}
+
+	var (
+		paid        []string
+		unknown     []string
+		verifyFails int
+	)
+	h := NewHandler(signer,
+		OnPaid(func(_ context.Context, pb *acquiring.Postback) error {
+			paid = append(paid, pb.ID)
+			return nil
+		}),
+		OnFailed(func(context.Context, *acquiring.Postback) error {
+			return errors.New("database is down")
+		}),
+		OnUnknownStatus(func(_ context.Context, pb *acquiring.Postback) error {
+			unknown = append(unknown, string(pb.Status))
+			return nil
+		}),
+		OnVerifyError(func(*http.Request, error) {
+			verifyFails++
+		}),
+	)
+
+	send := func(body string, sign bool) int {
+		req := httptest.NewRequest(http.MethodPost, "/novapay/callback", strings.NewReader(body))
+		if sign {
+			sig, err := signer.Sign([]byte(body))
+			if err != nil {
+				t.Fatalf("sign: %v", err)
+			}
+			req.Header.Set("x-sign", sig)
+		}
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	tests := []struct {
+		name string
+		body string
+		sign bool
+		want int
+	}{
+		{name: "paid", body: `{"id":"s-1","status":"paid"}`, sign: true, want: http.StatusOK},
+		{name: "no callback", body: `{"id":"s-2","status":"holded"}`, sign: true, want: http.StatusOK},
+		{name: "callback error", body: `{"id":"s-3","status":"failed"}`, sign: true, want: http.StatusInternalServerError},
+		{name: "unknown status", body: `{"id":"s-4","status":"refunded"}`, sign: true, want: http.StatusOK},
+		{name: "invalid json", body: `{"id":`, sign: true, want: http.StatusBadRequest},
+		{name: "missing signature", body: `{"id":"s-5","status":"paid"}`, want: http.StatusUnauthorized},
+	}
+	for _, tc := range tests {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := send(tc.body, tc.sign); got != tc.want {
+				t.Fatalf("status code: got %d want %d", got, tc.want)
+			}
+		})
+	}
+
+	if len(paid) != 1 || paid[0] != "s-1" {
+		t.Fatalf("unexpected paid callbacks: %v", paid)
+	}
+	if len(unknown) != 1 || unknown[0] != "refunded" {
+		t.Fatalf("unexpected unknown-status callbacks: %v", unknown)
+	}
+	if verifyFails != 1 {
+		t.Fatalf("expected 1 verify failure, got %d", verifyFails)
+	}
+}
+
+func TestHandlerRejectsTamperedBody(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	signer := &signature.RSASigner{PrivateKey: key, PublicKey: &key.PublicKey, Hash: signature.HashSHA256}
+
+	called := false
+	h := NewHandler(signer, OnPaid(func(context.Context, *acquiring.Postback) error {
+		called = true
+		return nil
+	}))
+
+	sig, err := signer.Sign([]byte(`{"id":"s-1","status":"failed"}`))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/", strings.NewReader(`{"id":"s-1","status":"paid"}`))
+	req.Header.Set("x-sign", sig)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusUnauthorized {
+		t.Fatalf("expected 401, got %d", rec.Code)
+	}
+	if called {
+		t.Fatalf("callback must not run for a tampered body")
+	}
+}
+
+func TestParse(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	signer := &signature.RSASigner{PrivateKey: key, PublicKey: &key.PublicKey, Hash: signature.HashSHA256}
+
+	body := []byte(`{"id":"s-1","status":"holded","payments":[{"amount":10.5}]}`)
+	sig, err := signer.Sign(body)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+
+	pb, err := Parse(signer, body, sig)
+	if err != nil {
+		t.Fatalf("parse: %v", err)
+	}
+	if pb.ID != "s-1" || pb.Status != "holded" || len(pb.Payments) != 1 {
+		t.Fatalf("unexpected postback: %+v", pb)
+	}
+
+	if _, err := Parse(signer, body, ""); !errors.Is(err, ErrMissingSignature) {
+		t.Fatalf("expected ErrMissingSignature, got %v", err)
+	}
+}
+
+func TestCheckoutHandler(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	signer := &signature.RSASigner{PrivateKey: key, PublicKey: &key.PublicKey, Hash: signature.HashSHA256}
+
+	var got *checkout.Postback
+	h := NewCheckoutHandler(signer, OnCheckoutStatus(consts.SessionStatusHoldConfirmed, func(_ context.Context, pb *checkout.Postback) error {
+		got = pb
+		return nil
+	}))
+
+	body := `{"id":"s-1","status":"hold_confirmed","delivery":{"volume_weight":1,"weight":2,"recipient_city":"Kyiv","price":60},"express_waybill":"20450000000001","ref_id":"ref-1","payments":[{"amount":250}]}`
+	sig, err := signer.Sign([]byte(body))
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	req := httptest.NewRequest(http.MethodPost, "/checkout/callback", strings.NewReader(body))
+	req.Header.Set("x-sign", sig)
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	if rec.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rec.Code)
+	}
+	if got == nil || got.Delivery == nil || got.Delivery.RecipientCity != "Kyiv" || got.ExpressWaybill == nil || *got.ExpressWaybill != "20450000000001" {
+		t.Fatalf("unexpected checkout postback: %+v", got)
+	}
+
+	if _, err := ParseCheckout(signer, []byte(body), ""); !errors.Is(err, ErrMissingSignature) {
+		t.Fatalf("expected ErrMissingSignature, got %v", err)
+	}
+}
+
+type comfortVerifierStub struct{ signer *signature.RSASigner }
+
+func (v comfortVerifierStub) VerifyComfort(body []byte, xSign string) error {
+	return v.signer.Verify(body, xSign)
+}
+
+func TestComfortHandlerUsesComfortVerification(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	sha1Signer := &signature.RSASigner{PrivateKey: key, PublicKey: &key.PublicKey, Hash: signature.HashSHA1}
+	sha256Signer := &signature.RSASigner{PrivateKey: key, Hash: signature.HashSHA256}
+
+	var failed, unknown []string
+	h := NewComfortHandler(comfortVerifierStub{signer: sha1Signer},
+		OnComfortStatus("failed", func(_ context.Context, pb *comfort.Postback) error {
+			failed = append(failed, pb.GUID)
+			return nil
+		}),
+		OnComfortUnknownStatus(func(_ context.Context, pb *comfort.Postback) error {
+			unknown = append(unknown, string(pb.Status))
+			return nil
+		}),
+	)
+
+	body := `{"guid":"guid-1","public_id":"op-1","status":"failed","amount":"12.50","error_message":"card blocked"}`
+	send := func(signer *signature.RSASigner, body string) int {
+		sig, err := signer.Sign([]byte(body))
+		if err != nil {
+			t.Fatalf("sign: %v", err)
+		}
+		req := httptest.NewRequest(http.MethodPost, "/comfort/callback", strings.NewReader(body))
+		req.Header.Set("x-sign", sig)
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	if code := send(sha256Signer, body); code != http.StatusUnauthorized {
+		t.Fatalf("SHA-256 signature must be rejected, got %d", code)
+	}
+	if code := send(sha1Signer, body); code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", code)
+	}
+	if len(failed) != 1 || failed[0] != "guid-1" {
+		t.Fatalf("unexpected failed callbacks: %v", failed)
+	}
+	for _, b := range []string{`{"guid":"guid-2","status":"success"}`, `{"guid":"guid-3","status":"on_hold"}`} {
+		if code := send(sha1Signer, b); code != http.StatusOK {
+			t.Fatalf("expected 200, got %d", code)
+		}
+	}
+	if len(unknown) != 1 || unknown[0] != "on_hold" {
+		t.Fatalf("only undocumented statuses must reach OnComfortUnknownStatus, got %v", unknown)
+	}
+
+	sig, _ := sha1Signer.Sign([]byte(body))
+	pb, err := ParseComfort(comfortVerifierStub{signer: sha1Signer}, []byte(body), sig)
+	if err != nil || pb.Amount.String() != "12.50" || pb.ErrorMessage == nil {
+		t.Fatalf("parse comfort: %+v, %v", pb, err)
+	}
+}
@@ -0,0 +1,99 @@
+package postback
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/stremovskyy/go-nova/consts"
+)
+
+func verify(verifier Verifier, body []byte, xSign string) error {
+	if strings.TrimSpace(xSign) == "" {
+		return ErrMissingSignature
+	}
+	if verifier == nil {
+		return errors.New("postback: verifier is not configured")
+	}
+	if err := verifier.Verify(body, xSign); err != nil {
+		return fmt.Errorf("postback: verify signature: %w", err)
+	}
+	return nil
+}
+
+// readVerified reads the request body and checks its x-sign.
+//
+// On failure it writes the error response and returns false.
+func readVerified(w http.ResponseWriter, r *http.Request, maxBodyBytes int64, verifier Verifier, onVerifyError VerifyErrorFunc) ([]byte, bool) {
+	if r.Method != http.MethodPost {
+		w.Header().Set("Allow", http.MethodPost)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return nil, false
+	}
+
+	defer func() { _ = r.Body.Close() }()
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxBodyBytes))
+	if err != nil {
+		var mbe *http.MaxBytesError
+		if errors.As(err, &mbe) {
+			http.Error(w, "body too large", http.StatusRequestEntityTooLarge)
+			return nil, false
+		}
+		http.Error(w, "cannot read body", http.StatusBadRequest)
+		return nil, false
+	}
+
+	if err := verify(verifier, body, r.Header.Get(consts.HeaderXSign)); err != nil {
+		if onVerifyError != nil {
+			onVerifyError(r, err)
+		}
+		http.Error(w, "auth failed", http.StatusUnauthorized)
+		return nil, false
+	}
+	return body, true
+}
+
+// status is a postback status type: consts.SessionStatus or consts.OperationStatus.
+type status interface {
+	~string
+	IsKnown() bool
+}
+
+// dispatch decodes a verified body and calls the callback registered for its status.
+//
+// Statuses without a callback go to onUnknown only when they are undocumented.
+func dispatch[T any, S status, F ~func(context.Context, *T) error](w http.ResponseWriter, r *http.Request, body []byte, handlers map[S]F, onUnknown F, statusOf func(*T) S) {
+	var pb T
+	if err := json.Unmarshal(body, &pb); err != nil {
+		http.Error(w, "invalid json", http.StatusBadRequest)
+		return
+	}
+
+	status := statusOf(&pb)
+	fn := handlers[status]
+	if fn == nil && !status.IsKnown() {
+		fn = onUnknown
+	}
+	if fn != nil {
+		if err := fn(r.Context(), &pb); err != nil {
+			http.Error(w, "postback processing failed", http.StatusInternalServerError)
+			return
+		}
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+func parse[T any](verifier Verifier, body []byte, xSign string) (*T, error) {
+	if err := verify(verifier, body, xSign); err != nil {
+		return nil, err
+	}
+	var pb T
+	if err := json.Unmarshal(body, &pb); err != nil {
+		return nil, fmt.Errorf("postback: decode json: %w", err)
+	}
+	return &pb, nil
+}
@@ -0,0 +1,209 @@
+// Package reconcile compares local orders with NovaPay acquiring sessions.
+//
+// Each order names its session, expected amount and expected status; Run fetches
+// GetStatus for every order and reports what does not match:
+//
+//	report, err := reconcile.Run(ctx, client.Acquiring(), orders, reconcile.WithMerchantID("101"))
+//	if err != nil {
+//		return err
+//	}
+//	_ = report.WriteCSV(os.Stdout)
+package reconcile
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	go_nova "github.com/stremovskyy/go-nova"
+	"github.com/stremovskyy/go-nova/acquiring"
+	"github.com/stremovskyy/go-nova/consts"
+	"github.com/stremovskyy/go-nova/money"
+)
+
+// Order is a local record to check against NovaPay.
+type Order interface {
+	ExternalID() string
+	SessionID() string
+	ExpectedAmount() money.Amount
+	ExpectedStatus() consts.SessionStatus
+}
+
+// Record is a plain Order.
+type Record struct {
+	ExtID   string
+	Session string
+	Amount  money.Amount
+	Status  consts.SessionStatus
+}
+
+func (r Record) ExternalID() string                   { return r.ExtID }
+func (r Record) SessionID() string                    { return r.Session }
+func (r Record) ExpectedAmount() money.Amount         { return r.Amount }
+func (r Record) ExpectedStatus() consts.SessionStatus { return r.Status }
+
+// StatusGetter fetches a session status. *go_nova.AcquiringService implements it.
+type StatusGetter interface {
+	GetStatus(ctx context.Context, req *acquiring.SessionRequest, runOpts ...go_nova.RunOption) (*acquiring.GetStatusResponse, error)
+}
+
+// merchantScoped is implemented by getters that fill the merchant ID themselves,
+// such as *go_nova.AcquiringService.
+type merchantScoped interface {
+	MerchantID() string
+}
+
+// DefaultConcurrency is the number of GetStatus calls Run makes at once.
+const DefaultConcurrency = 4
+
+// Option configures Run.
+type Option func(*options)
+
+type options struct {
+	concurrency int
+	merchantID  string
+	now         func() time.Time
+}
+
+// WithConcurrency sets how many GetStatus calls run at once.
+func WithConcurrency(n int) Option {
+	return func(o *options) {
+		if n > 0 {
+			o.concurrency = n
+		}
+	}
+}
+
+// WithMerchantID sets the merchant ID sent with GetStatus. It is required unless the
+// getter is the Acquiring service of a merchant-scoped client (Client.ForMerchant).
+func WithMerchantID(id string) Option {
+	return func(o *options) {
+		o.merchantID = id
+	}
+}
+
+// Run checks every order against NovaPay.
+//
+// A session NovaPay does not know is reported as KindMissing and a failed fetch as
+// KindError; neither stops the run. Run returns an error only when ctx ends, together
+// with the report of the orders checked so far, or when no merchant ID is known.
+func Run[T Order](ctx context.Context, getter StatusGetter, orders []T, opts ...Option) (*Report, error) {
+	if getter == nil {
+		return nil, errors.New("reconcile: status getter is nil")
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	o := &options{concurrency: DefaultConcurrency, now: time.Now}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+	if ms, ok := getter.(merchantScoped); ok && o.merchantID == "" && ms.MerchantID() == "" {
+		return nil, errors.New("reconcile: no merchant ID: use WithMerchantID or a merchant-scoped client")
+	}
+
+	results := make([][]Discrepancy, len(orders))
+	checked := make([]bool, len(orders))
+	sem := make(chan struct{}, o.concurrency)
+	var wg sync.WaitGroup
+	for i, order := range orders {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+		}
+		if ctx.Err() != nil {
+			break
+		}
+		wg.Add(1)
+		go func(i int, order Order) {
+			defer wg.Done()
+			defer func() { <-sem }()
+			results[i] = check(ctx, getter, o.merchantID, order)
+			checked[i] = ctx.Err() == nil
+		}(i, order)
+	}
+	wg.Wait()
+
+	report := &Report{GeneratedAt: o.now(), Discrepancies: []Discrepancy{}}
+	for i := range orders {
+		if !checked[i] {
+			continue
+		}
+		report.Checked++
+		if len(results[i]) == 0 {
+			report.Matched++
+		}
+		report.Discrepancies = append(report.Discrepancies, results[i]...)
+	}
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+func check(ctx context.Context, getter StatusGetter, merchantID string, order Order) []Discrepancy {
+	base := Discrepancy{
+		ExternalID:     order.ExternalID(),
+		SessionID:      order.SessionID(),
+		ExpectedStatus: order.ExpectedStatus(),
+		ExpectedAmount: order.ExpectedAmount(),
+	}
+	if base.SessionID == "" {
+		base.Kind = KindMissing
+		base.Error = "order has no session id"
+		return []Discrepancy{base}
+	}
+
+	st, err := getter.GetStatus(ctx, &acquiring.SessionRequest{MerchantID: merchantID, SessionID: base.SessionID})
+	switch {
+	case errors.Is(err, go_nova.ErrSessionNotFound):
+		base.Kind = KindMissing
+		return []Discrepancy{base}
+	case err != nil:
+		base.Kind = KindError
+		base.Error = err.Error()
+		return []Discrepancy{base}
+	case st == nil:
+		base.Kind = KindError
+		base.Error = "get status returned no response"
+		return []Discrepancy{base}
+	}
+
+	base.ActualStatus = st.Status
+	base.ActualAmount = sessionAmount(st, base.ExternalID)
+
+	var out []Discrepancy
+	if base.ExpectedStatus != "" && base.ActualStatus != base.ExpectedStatus {
+		d := base
+		d.Kind = KindStatusMismatch
+		out = append(out, d)
+	}
+	if base.ActualAmount != base.ExpectedAmount {
+		d := base
+		d.Kind = KindAmountMismatch
+		d.Difference = base.ActualAmount.Sub(base.ExpectedAmount)
+		out = append(out, d)
+	}
+	return out
+}
+
+// sessionAmount sums the operations of the order's external ID, or all operations
+// when none carries it.
+func sessionAmount(st *acquiring.GetStatusResponse, externalID string) money.Amount {
+	var own, all money.Amount
+	found := false
+	for _, op := range st.Operations {
+		all = all.Add(op.Amount)
+		if externalID != "" && op.ExternalID != nil && *op.ExternalID == externalID {
+			own = own.Add(op.Amount)
+			found = true
+		}
+	}
+	if found {
+		return own
+	}
+	return all
+}
@@ -0,0 +1,113 @@
+package reconcile
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+
+	go_nova "github.com/stremovskyy/go-nova"
+	"github.com/stremovskyy/go-nova/acquiring"
+	"github.com/stremovskyy/go-nova/consts"
+	"github.com/stremovskyy/go-nova/money"
+)
+
+type fakeGetter map[string]*acquiring.GetStatusResponse
+
+func (f fakeGetter) GetStatus(_ context.Context, req *acquiring.SessionRequest, _ ...go_nova.RunOption) (*acquiring.GetStatusResponse, error) {
+	if req.SessionID == "broken" {
+		return nil, errors.New("connection reset")
+	}
+	st, ok := f[req.SessionID]
+	if !ok {
+		return nil, &go_nova.APIError{StatusCode: 404, Code: "session_not_found"}
+	}
+	return st, nil
+}
+
+func ref(s string) *string { return &s }
+
+func TestRun(t *testing.T) {
+	getter := fakeGetter{
+		"s-ok": {Status: consts.SessionStatusPaid, Operations: []acquiring.OperationInfo{{ExternalID: ref("o-1"), Amount: money.MustParse("100")}}},
+		"s-both": {Status: consts.SessionStatusHolded, Operations: []acquiring.OperationInfo{
+			{ExternalID: ref("other"), Amount: money.MustParse("1")},
+			{ExternalID: ref("o-2"), Amount: money.MustParse("90.50")},
+		}},
+	}
+	orders := []Record{
+		{ExtID: "o-1", Session: "s-ok", Amount: money.MustParse("100"), Status: consts.SessionStatusPaid},
+		{ExtID: "o-2", Session: "s-both", Amount: money.MustParse("100"), Status: consts.SessionStatusPaid},
+		{ExtID: "o-3", Session: "s-gone", Amount: money.MustParse("5"), Status: consts.SessionStatusPaid},
+		{ExtID: "o-4", Session: "broken", Amount: money.MustParse("5")},
+	}
+
+	report, err := Run(context.Background(), getter, orders, WithConcurrency(2))
+	if err != nil {
+		t.Fatalf("run: %v", err)
+	}
+	if report.Checked != 4 || report.Matched != 1 || report.OK() {
+		t.Fatalf("unexpected counts: %s", report)
+	}
+	s := report.Summary()
+	if s[KindMissing] != 1 || s[KindStatusMismatch] != 1 || s[KindAmountMismatch] != 1 || s[KindError] != 1 {
+		t.Fatalf("unexpected summary: %v", s)
+	}
+	amount := report.ByKind(KindAmountMismatch)[0]
+	if amount.ExternalID != "o-2" || amount.ActualAmount != money.MustParse("90.50") || amount.Difference != money.MustParse("-9.50") {
+		t.Fatalf("unexpected amount mismatch: %+v", amount)
+	}
+
+	var csvBuf bytes.Buffer
+	if err := report.WriteCSV(&csvBuf); err != nil {
+		t.Fatalf("write csv: %v", err)
+	}
+	rows, err := csv.NewReader(&csvBuf).ReadAll()
+	if err != nil || len(rows) != 5 || rows[0][0] != "kind" {
+		t.Fatalf("unexpected csv: %v, %v", rows, err)
+	}
+
+	var jsonBuf bytes.Buffer
+	if err := report.WriteJSON(&jsonBuf); err != nil {
+		t.Fatalf("write json: %v", err)
+	}
+	var decoded Report
+	if err := json.Unmarshal(jsonBuf.Bytes(), &decoded); err != nil || len(decoded.Discrepancies) != 4 {
+		t.Fatalf("unexpected json: %s, %v", jsonBuf.String(), err)
+	}
+}
+
+func TestRunRequiresMerchantID(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	client, err := go_nova.NewClient(go_nova.WithPrivateKey(key), go_nova.WithLogger(nil),
+		go_nova.WithMerchant("shop", go_nova.NewMerchant("101")))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	shop, err := client.ForMerchant("shop")
+	if err != nil {
+		t.Fatalf("for merchant: %v", err)
+	}
+	orders := []Record{{ExtID: "o-1", Session: "s-1"}}
+
+	_, err = Run(context.Background(), client.Acquiring(), orders)
+	if err == nil || !strings.Contains(err.Error(), "no merchant ID") {
+		t.Fatalf("unscoped client without WithMerchantID must fail, got %v", err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := Run(ctx, client.Acquiring(), orders, WithMerchantID("1")); !errors.Is(err, context.Canceled) {
+		t.Fatalf("WithMerchantID must satisfy the check, got %v", err)
+	}
+	if _, err := Run(ctx, shop.Acquiring(), orders); !errors.Is(err, context.Canceled) {
+		t.Fatalf("a merchant-scoped client must satisfy the check, got %v", err)
+	}
+}
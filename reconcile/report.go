@@ -0,0 +1,132 @@
+package reconcile
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/stremovskyy/go-nova/consts"
+	"github.com/stremovskyy/go-nova/money"
+)
+
+// Kind classifies a discrepancy.
+type Kind string
+
+const (
+	// KindMissing means NovaPay does not know the session.
+	KindMissing Kind = "missing_session"
+	// KindStatusMismatch means the session status differs from the expected one.
+	KindStatusMismatch Kind = "status_mismatch"
+	// KindAmountMismatch means the operations total differs from the expected amount.
+	KindAmountMismatch Kind = "amount_mismatch"
+	// KindError means the status could not be fetched; see Error.
+	KindError Kind = "error"
+)
+
+// Discrepancy is one mismatch between an order and its NovaPay session.
+//
+// An order with both a wrong status and a wrong amount yields two discrepancies.
+type Discrepancy struct {
+	Kind           Kind                 `json:"kind"`
+	ExternalID     string               `json:"external_id"`
+	SessionID      string               `json:"session_id"`
+	ExpectedStatus consts.SessionStatus `json:"expected_status,omitempty"`
+	ActualStatus   consts.SessionStatus `json:"actual_status,omitempty"`
+	ExpectedAmount money.Amount         `json:"expected_amount"`
+	ActualAmount   money.Amount         `json:"actual_amount"`
+	// Difference is ActualAmount - ExpectedAmount, set for KindAmountMismatch.
+	Difference money.Amount `json:"difference"`
+	Error      string       `json:"error,omitempty"`
+}
+
+// Report is the result of Run.
+type Report struct {
+	GeneratedAt   time.Time     `json:"generated_at"`
+	Checked       int           `json:"checked"`
+	Matched       int           `json:"matched"`
+	Discrepancies []Discrepancy `json:"discrepancies"`
+}
+
+// OK reports whether every checked order matched.
+func (r *Report) OK() bool {
+	return r != nil && len(r.Discrepancies) == 0
+}
+
+// ByKind returns the discrepancies of the given kind.
+func (r *Report) ByKind(kind Kind) []Discrepancy {
+	if r == nil {
+		return nil
+	}
+	var out []Discrepancy
+	for _, d := range r.Discrepancies {
+		if d.Kind == kind {
+			out = append(out, d)
+		}
+	}
+	return out
+}
+
+// WriteJSON writes the report as indented JSON.
+func (r *Report) WriteJSON(w io.Writer) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(r)
+}
+
+// csvHeader is the header row written by WriteCSV.
+var csvHeader = []string{"kind", "external_id", "session_id", "expected_status", "actual_status", "expected_amount", "actual_amount", "difference", "error"}
+
+// WriteCSV writes one row per discrepancy, amounts in hryvnias ("1250.50").
+func (r *Report) WriteCSV(w io.Writer) error {
+	cw := csv.NewWriter(w)
+	if err := cw.Write(csvHeader); err != nil {
+		return err
+	}
+	if r != nil {
+		for _, d := range r.Discrepancies {
+			row := []string{
+				string(d.Kind),
+				d.ExternalID,
+				d.SessionID,
+				string(d.ExpectedStatus),
+				string(d.ActualStatus),
+				d.ExpectedAmount.String(),
+				d.ActualAmount.String(),
+				d.Difference.String(),
+				d.Error,
+			}
+			if d.Kind == KindMissing || d.Kind == KindError {
+				row[6], row[7] = "", ""
+			}
+			if err := cw.Write(row); err != nil {
+				return err
+			}
+		}
+	}
+	cw.Flush()
+	return cw.Error()
+}
+
+// Summary returns the number of discrepancies per kind.
+func (r *Report) Summary() map[Kind]int {
+	out := map[Kind]int{}
+	if r == nil {
+		return out
+	}
+	for _, d := range r.Discrepancies {
+		out[d.Kind]++
+	}
+	return out
+}
+
+// String returns a one-line summary.
+func (r *Report) String() string {
+	if r == nil {
+		return "reconcile: no report"
+	}
+	s := r.Summary()
+	return fmt.Sprintf("reconcile: checked %d, matched %d, missing %d, status mismatches %d, amount mismatches %d, errors %d",
+		r.Checked, r.Matched, s[KindMissing], s[KindStatusMismatch], s[KindAmountMismatch], s[KindError])
+}
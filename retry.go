@@ -0,0 +1,153 @@
+package go_nova
+
+import (
+	"errors"
+	"math/rand/v2"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/stremovskyy/go-nova/consts"
+	"github.com/stremovskyy/go-nova/internal/httpclient"
+)
+
+// RetryAttempt describes a failed HTTP attempt to a RetryPolicy.
+type RetryAttempt = httpclient.RetryAttempt
+
+// RetryPolicy decides whether a failed HTTP attempt is repeated and how long to wait.
+//
+// It is asked about non-2xx responses and transport errors; cancelled calls, invalid
+// requests and undecodable responses are never retried, and neither are calls to
+// unsafe endpoints NovaPay may have executed (see ErrOutcomeUnknown). Policies are
+// shared by concurrent calls and must be safe for concurrent use.
+type RetryPolicy = httpclient.RetryPolicy
+
+// Defaults of BackoffPolicy.
+const (
+	DefaultRetryBaseWait = 300 * time.Millisecond
+	DefaultRetryMaxWait  = 10 * time.Second
+)
+
+// BackoffPolicy is exponential backoff with full jitter: the wait before retry n is
+// random between 0 and BaseWait*2^(n-1), capped at MaxWait. A Retry-After response
+// header replaces the random wait.
+type BackoffPolicy struct {
+	// MaxAttempts counts every attempt including the first; below 2 nothing is retried.
+	MaxAttempts int
+	// BaseWait bounds the first wait. Zero means DefaultRetryBaseWait.
+	BaseWait time.Duration
+	// MaxWait caps every wait. Zero means DefaultRetryMaxWait. A Retry-After longer
+	// than MaxWait stops retrying.
+	MaxWait time.Duration
+	// Statuses overrides the decision for single response codes: true retries the
+	// code, false never does. Other codes are retried when they are 429 or 5xx
+	// except 501. Transport errors are always retried.
+	Statuses map[int]bool
+	// IgnoreRetryAfter disables the Retry-After header.
+	IgnoreRetryAfter bool
+
+	// jitter returns a random duration in [0, d]; nil uses math/rand.
+	jitter func(d time.Duration) time.Duration
+}
+
+var _ RetryPolicy = BackoffPolicy{}
+
+// Next implements RetryPolicy.
+func (p BackoffPolicy) Next(a RetryAttempt) (time.Duration, bool) {
+	if a.Attempt >= p.MaxAttempts || !p.retries(a.StatusCode) {
+		return 0, false
+	}
+	maxWait := p.MaxWait
+	if maxWait <= 0 {
+		maxWait = DefaultRetryMaxWait
+	}
+	if !p.IgnoreRetryAfter {
+		if d, ok := retryAfter(a.Header, time.Now()); ok {
+			return d, d <= maxWait
+		}
+	}
+
+	wait := p.BaseWait
+	if wait <= 0 {
+		wait = DefaultRetryBaseWait
+	}
+	for i := 1; i < a.Attempt && wait < maxWait; i++ {
+		wait *= 2
+	}
+	wait = min(wait, maxWait)
+	if p.jitter != nil {
+		return p.jitter(wait), true
+	}
+	return time.Duration(rand.Int64N(int64(wait) + 1)), true
+}
+
+func (p BackoffPolicy) retries(status int) bool {
+	if status == 0 {
+		return true
+	}
+	if retry, ok := p.Statuses[status]; ok {
+		return retry
+	}
+	return httpclient.RetryableStatus(status)
+}
+
+// retryAfter parses a Retry-After header given in seconds or as an HTTP date.
+func retryAfter(h http.Header, now time.Time) (time.Duration, bool) {
+	v := h.Get("Retry-After")
+	if v == "" {
+		return 0, false
+	}
+	if secs, err := strconv.Atoi(v); err == nil {
+		if secs < 0 {
+			return 0, false
+		}
+		return time.Duration(secs) * time.Second, true
+	}
+	at, err := http.ParseTime(v)
+	if err != nil {
+		return 0, false
+	}
+	return max(at.Sub(now), 0), true
+}
+
+// WithRetryPolicy sets how failed requests are retried by default; see
+// WithCallRetryPolicy for a single call. It replaces WithRetry.
+func WithRetryPolicy(p RetryPolicy) Option {
+	return func(cfg *config) error {
+		if p == nil {
+			return errors.New("retry policy is nil")
+		}
+		cfg.retryPolicy = p
+		return nil
+	}
+}
+
+// WithCallRetryPolicy retries a single call with p instead of the client policy, e.g.
+// to poll GetStatus more persistently than money-moving calls:
+//
+//	client.Acquiring().GetStatus(ctx, req, go_nova.WithCallRetryPolicy(go_nova.BackoffPolicy{MaxAttempts: 6}))
+//
+// CreateOperations is never retried blindly and only uses p to time the retries of
+// WithPayoutReconcile.
+func WithCallRetryPolicy(p RetryPolicy) RunOption {
+	return func(o *runOptions) {
+		o.retryPolicy = p
+	}
+}
+
+// callRetryPolicy returns the retry policy of a call made with opts.
+func (c *Client) callRetryPolicy(opts *runOptions) RetryPolicy {
+	if opts != nil && opts.retryPolicy != nil {
+		return opts.retryPolicy
+	}
+	return c.cfg.retryPolicy
+}
+
+// requestOptions translates the options of a call to endpointPath for DoJSON.
+func (c *Client) requestOptions(method, endpointPath string, runOpts []RunOption) []httpclient.RequestOption {
+	ro := []httpclient.RequestOption{httpclient.WithRetrySafety(consts.EndpointSafety(method, endpointPath))}
+	if opts := collectRunOptions(runOpts); opts != nil && opts.retryPolicy != nil {
+		ro = append(ro, httpclient.WithRetryPolicy(opts.retryPolicy))
+	}
+	return ro
+}
@@ -0,0 +1,167 @@
+package go_nova
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stremovskyy/go-nova/acquiring"
+	"github.com/stremovskyy/go-nova/consts"
+)
+
+func TestBackoffPolicy(t *testing.T) {
+	full := func(d time.Duration) time.Duration { return d }
+	p := BackoffPolicy{MaxAttempts: 5, BaseWait: time.Second, MaxWait: 3 * time.Second, jitter: full}
+
+	for attempt, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 3 * time.Second, 4: 3 * time.Second} {
+		wait, retry := p.Next(RetryAttempt{Attempt: attempt, StatusCode: http.StatusServiceUnavailable})
+		if !retry || wait != want {
+			t.Fatalf("attempt %d: got %s %v, want %s", attempt, wait, retry, want)
+		}
+	}
+	if _, retry := p.Next(RetryAttempt{Attempt: 5, StatusCode: http.StatusServiceUnavailable}); retry {
+		t.Fatal("must stop after MaxAttempts")
+	}
+	if _, retry := p.Next(RetryAttempt{Attempt: 1, StatusCode: http.StatusBadRequest}); retry {
+		t.Fatal("400 must not be retried")
+	}
+	if _, retry := p.Next(RetryAttempt{Attempt: 1, Err: errors.New("connection reset")}); !retry {
+		t.Fatal("transport errors must be retried")
+	}
+
+	p.Statuses = map[int]bool{http.StatusConflict: true, http.StatusInternalServerError: false}
+	if _, retry := p.Next(RetryAttempt{Attempt: 1, StatusCode: http.StatusConflict}); !retry {
+		t.Fatal("409 must be retried by the status rule")
+	}
+	if _, retry := p.Next(RetryAttempt{Attempt: 1, StatusCode: http.StatusInternalServerError}); retry {
+		t.Fatal("500 must not be retried by the status rule")
+	}
+
+	h := http.Header{"Retry-After": []string{"2"}}
+	if wait, retry := p.Next(RetryAttempt{Attempt: 1, StatusCode: http.StatusTooManyRequests, Header: h}); !retry || wait != 2*time.Second {
+		t.Fatalf("Retry-After: got %s %v", wait, retry)
+	}
+	h.Set("Retry-After", "60")
+	if _, retry := p.Next(RetryAttempt{Attempt: 1, StatusCode: http.StatusTooManyRequests, Header: h}); retry {
+		t.Fatal("Retry-After above MaxWait must stop retrying")
+	}
+	h.Set("Retry-After", time.Now().Add(-time.Minute).UTC().Format(http.TimeFormat))
+	if wait, retry := p.Next(RetryAttempt{Attempt: 1, StatusCode: http.StatusTooManyRequests, Header: h}); !retry || wait != 0 {
+		t.Fatalf("past Retry-After date: got %s %v", wait, retry)
+	}
+
+	p = BackoffPolicy{MaxAttempts: 2, BaseWait: time.Second}
+	for i := 0; i < 100; i++ {
+		if wait, _ := p.Next(RetryAttempt{Attempt: 1}); wait < 0 || wait > time.Second {
+			t.Fatalf("jittered wait %s out of [0, 1s]", wait)
+		}
+	}
+}
+
+func TestCallRetryPolicy(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&calls, 1)%2 == 1 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+		_, _ = w.Write([]byte(`{"id":"s-1","status":"paid"}`))
+	}))
+	defer ts.Close()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	client, err := NewClient(WithPrivateKey(key), WithAcquiringBaseURL(ts.URL), WithLogger(nil))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	req := &acquiring.SessionRequest{MerchantID: "1", SessionID: "s-1"}
+
+	if _, err := client.Acquiring().GetStatus(context.Background(), req); err == nil {
+		t.Fatal("the client must not retry by default")
+	}
+	atomic.StoreInt32(&calls, 0)
+	if _, err := client.Acquiring().GetStatus(context.Background(), req, WithCallRetryPolicy(BackoffPolicy{MaxAttempts: 3})); err != nil {
+		t.Fatalf("get status with retry policy: %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 2 {
+		t.Fatalf("want 2 calls, got %d", n)
+	}
+}
+
+func TestUnsafeCallsAreNotRetriedAfterSending(t *testing.T) {
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&calls, 1)
+		if r.URL.Path == consts.AcquiringCompleteHoldPath || r.URL.Path == consts.ComfortCreateOperationsPath {
+			w.WriteHeader(http.StatusBadGateway)
+			return
+		}
+		// Drop the connection after reading the request: NovaPay may have executed it.
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err == nil {
+			_ = conn.Close()
+		}
+	}))
+	defer ts.Close()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	var attempts int32
+	client, err := NewClient(
+		WithPrivateKey(key),
+		WithAcquiringBaseURL(ts.URL),
+		WithComfortBaseURL(ts.URL),
+		WithComfortMerchantID("42"),
+		WithRetry(3, time.Millisecond),
+		WithLogger(nil),
+		WithMiddleware(func(next RoundTrip) RoundTrip {
+			return func(ctx context.Context, req *HTTPRequest) (*HTTPResponse, error) {
+				atomic.AddInt32(&attempts, 1)
+				return next(ctx, req)
+			}
+		}),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	err = client.Acquiring().CompleteHold(context.Background(), &acquiring.CompleteHoldRequest{MerchantID: "1", SessionID: "s-1"})
+	var ae *APIError
+	if !errors.Is(err, ErrOutcomeUnknown) || !errors.As(err, &ae) || ae.StatusCode != http.StatusBadGateway {
+		t.Fatalf("complete hold: want ErrOutcomeUnknown wrapping a 502 APIError, got %v", err)
+	}
+	_, err = client.Acquiring().AddPayment(context.Background(), &acquiring.AddPaymentRequest{MerchantID: "1", SessionID: "s-1", Amount: 100})
+	if !errors.Is(err, ErrOutcomeUnknown) {
+		t.Fatalf("add payment: want ErrOutcomeUnknown, got %v", err)
+	}
+	err = client.Comfort().Do(context.Background(), http.MethodPost, consts.ComfortCreateOperationsPath, []map[string]any{{"amount": 1}}, nil)
+	if !errors.Is(err, ErrOutcomeUnknown) {
+		t.Fatalf("comfort create operations: want ErrOutcomeUnknown, got %v", err)
+	}
+	if n := atomic.LoadInt32(&calls); n != 3 {
+		t.Fatalf("unsafe calls must be sent once each, server saw %d requests", n)
+	}
+
+	// A request that never left the client is retried.
+	ts.Close()
+	atomic.StoreInt32(&attempts, 0)
+	_, err = client.Acquiring().AddPayment(context.Background(), &acquiring.AddPaymentRequest{MerchantID: "1", SessionID: "s-1", Amount: 100})
+	if err == nil || errors.Is(err, ErrOutcomeUnknown) {
+		t.Fatalf("refused connection: want a plain transport error, got %v", err)
+	}
+	if n := atomic.LoadInt32(&attempts); n != 3 {
+		t.Fatalf("refused connection must be retried, got %d attempts", n)
+	}
+}
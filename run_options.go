@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"fmt"
 
+	"github.com/stremovskyy/go-nova/consts"
 	"github.com/stremovskyy/go-nova/log"
 )
 
@@ -16,6 +17,13 @@ type DryRunHandler func(method string, url string, payload any)
 type runOptions struct {
 	dryRun       bool
 	dryRunHandle DryRunHandler
+	knownStatus  consts.SessionStatus
+	retryPolicy  RetryPolicy
+
+	idempotencyKey   string
+	reconcilePayouts bool
+	batchChunkSize   int
+	batchConcurrency int
 }
 
 var dryRunLogger = log.NewDefault()
@@ -34,6 +42,16 @@ func DryRun(handler ...DryRunHandler) RunOption {
 	}
 }
 
+// WithKnownStatus passes the last known session status to the call.
+//
+// VoidSession, CompleteHold, ConfirmDeliveryHold and ExpireSession then return a
+// *SessionStatusError without calling NovaPay when the status does not allow the operation.
+func WithKnownStatus(status consts.SessionStatus) RunOption {
+	return func(o *runOptions) {
+		o.knownStatus = status
+	}
+}
+
 func collectRunOptions(opts []RunOption) *runOptions {
 	if len(opts) == 0 {
 		return nil
@@ -59,11 +77,12 @@ func (o *runOptions) handleDryRun(method string, url string, payload any) {
 	o.dryRunHandle(method, url, payload)
 }
 
-func shouldDryRun(runOpts []RunOption, method string, url string, payload any) bool {
+func (c *Client) shouldDryRun(runOpts []RunOption, method string, url string, payload any) bool {
 	opts := collectRunOptions(runOpts)
 	if !opts.isDryRun() {
 		return false
 	}
+	c.observeDryRun(method, url)
 	opts.handleDryRun(method, url, payload)
 	return true
 }
@@ -0,0 +1,161 @@
+package go_nova
+
+import (
+	"crypto"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+
+	"github.com/stremovskyy/go-nova/internal/signature"
+)
+
+// Signer produces the x-sign header value for a request body.
+//
+// The SDK signs the exact body bytes it sends. Implement it to sign outside the
+// process, e.g. through a signing-agent socket; see WithSigner.
+type Signer interface {
+	Sign(body []byte) (string, error)
+}
+
+// WithCryptoSigner signs requests with a crypto.Signer instead of an in-memory private key.
+//
+// The SDK still hashes the body (SHA-256 for Acquiring/Checkout, SHA-1 for Comfort by
+// default) and asks signer for an RSA PKCS#1 v1.5 signature of the digest, so any
+// KMS/HSM/Vault key exposed as crypto.Signer can be used. signer.Public() must be an
+// *rsa.PublicKey.
+func WithCryptoSigner(signer crypto.Signer) Option {
+	return cryptoSigner(scopeAll, signer)
+}
+
+// WithExternalCryptoSigner is WithCryptoSigner for Acquiring/Checkout requests only.
+func WithExternalCryptoSigner(signer crypto.Signer) Option {
+	return cryptoSigner(scopeExternal, signer)
+}
+
+// WithComfortCryptoSigner is WithCryptoSigner for Comfort requests only.
+func WithComfortCryptoSigner(signer crypto.Signer) Option {
+	return cryptoSigner(scopeComfort, signer)
+}
+
+// WithSigner replaces x-sign generation for all APIs with a custom Signer.
+//
+// Verification keys and hash options are not used for signing when it is set.
+// Like every signing option, it replaces the signing key or signer set by an earlier
+// option for the same API, e.g. in the client options of a Merchant.
+func WithSigner(signer Signer) Option {
+	return requestSigner(scopeAll, signer)
+}
+
+// WithExternalSigner is WithSigner for Acquiring/Checkout requests only.
+func WithExternalSigner(signer Signer) Option {
+	return requestSigner(scopeExternal, signer)
+}
+
+// WithComfortSigner is WithSigner for Comfort requests only.
+func WithComfortSigner(signer Signer) Option {
+	return requestSigner(scopeComfort, signer)
+}
+
+func cryptoSigner(scope keyScope, signer crypto.Signer) Option {
+	return func(cfg *config) error {
+		if err := checkCryptoSigner(signer); err != nil {
+			return err
+		}
+		for _, s := range cfg.rsaSigners(scope) {
+			s.Signer = signer
+		}
+		cfg.clearRequestSigners(scope)
+		return nil
+	}
+}
+
+func requestSigner(scope keyScope, signer Signer) Option {
+	return func(cfg *config) error {
+		if signer == nil {
+			return errors.New("signer is nil")
+		}
+		if scope&scopeExternal != 0 {
+			cfg.externalRequestSigner = signer
+		}
+		if scope&scopeComfort != 0 {
+			cfg.comfortRequestSigner = signer
+		}
+		return nil
+	}
+}
+
+func checkCryptoSigner(signer crypto.Signer) error {
+	if signer == nil {
+		return errors.New("crypto signer is nil")
+	}
+	if _, ok := signer.Public().(*rsa.PublicKey); !ok {
+		return fmt.Errorf("crypto signer key is not RSA (got %T)", signer.Public())
+	}
+	return nil
+}
+
+// clearRequestSigners drops the WithSigner signers of scope, so that a signing key
+// set later wins.
+func (cfg *config) clearRequestSigners(scope keyScope) {
+	if scope&scopeExternal != 0 {
+		cfg.externalRequestSigner = nil
+	}
+	if scope&scopeComfort != 0 {
+		cfg.comfortRequestSigner = nil
+	}
+}
+
+// signers returns the request signers for the External and Comfort APIs.
+func (cfg *config) signers() (external, comfort Signer) {
+	external, comfort = cfg.externalSigner, cfg.comfortSigner
+	if cfg.externalRequestSigner != nil {
+		external = cfg.externalRequestSigner
+	}
+	if cfg.comfortRequestSigner != nil {
+		comfort = cfg.comfortRequestSigner
+	}
+	return external, comfort
+}
+
+// canSign reports whether requests of the given API can be signed.
+func (cfg *config) canSign(scope keyScope) bool {
+	if scope == scopeComfort {
+		return cfg.comfortRequestSigner != nil || cfg.comfortSigner.PrivateKey != nil || cfg.comfortSigner.Signer != nil
+	}
+	return cfg.externalRequestSigner != nil || cfg.externalSigner.PrivateKey != nil || cfg.externalSigner.Signer != nil
+}
+
+// signingCheckBody is signed by checkSigningKeys with every configured key.
+var signingCheckBody = []byte(`{"check":"go-nova signing key"}`)
+
+// checkSigningKeys fails fast when an API the client is configured for has no signing
+// key, or when a configured key or crypto.Signer cannot sign.
+//
+// Comfort counts as configured once a merchant ID is set; Acquiring/Checkout once any
+// signing key at all is configured, unless only Comfort keys were given. Custom
+// Signers (WithSigner) are not called.
+func (cfg *config) checkSigningKeys() error {
+	external, comfort := cfg.canSign(scopeExternal), cfg.canSign(scopeComfort)
+	if cfg.comfortMerchantID != "" && !comfort {
+		return errors.New("comfort merchant id is set but no Comfort signing key is configured (use WithComfortPrivateKey* or WithPrivateKey*)")
+	}
+	if !external && comfort && cfg.comfortMerchantID == "" {
+		return errors.New("only a Comfort signing key is configured but no Comfort merchant id (use WithComfortMerchantID)")
+	}
+	for _, k := range []struct {
+		api    string
+		signer *signature.RSASigner
+		custom Signer
+	}{
+		{"Acquiring/Checkout", cfg.externalSigner, cfg.externalRequestSigner},
+		{"Comfort", cfg.comfortSigner, cfg.comfortRequestSigner},
+	} {
+		if k.custom != nil || (k.signer.PrivateKey == nil && k.signer.Signer == nil) {
+			continue
+		}
+		if _, err := k.signer.Sign(signingCheckBody); err != nil {
+			return fmt.Errorf("%s signing key cannot sign: %w", k.api, err)
+		}
+	}
+	return nil
+}
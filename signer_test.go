@@ -0,0 +1,198 @@
+package go_nova
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/stremovskyy/go-nova/acquiring"
+	"github.com/stremovskyy/go-nova/internal/signature"
+)
+
+// kmsStub stands in for a remote key: it only exposes Public and Sign.
+type kmsStub struct {
+	key    *rsa.PrivateKey
+	hashes []crypto.Hash
+}
+
+func (s *kmsStub) Public() crypto.PublicKey { return &s.key.PublicKey }
+
+func (s *kmsStub) Sign(rnd io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	s.hashes = append(s.hashes, opts.HashFunc())
+	return rsa.SignPKCS1v15(rnd, s.key, opts.HashFunc(), digest)
+}
+
+func TestWithCryptoSignerDelegatesSigning(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	stub := &kmsStub{key: key}
+
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		if err := (&signature.RSASigner{PublicKey: &key.PublicKey, Hash: signature.HashSHA256}).Verify(body, r.Header.Get("x-sign")); err != nil {
+			t.Errorf("request signature verify failed: %v", err)
+		}
+		_, _ = w.Write([]byte(`{"id":"session-id"}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(WithCryptoSigner(stub), WithAcquiringBaseURL(ts.URL), WithLogger(nil))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	// NewClient tries the signer once per API.
+	if len(stub.hashes) != 2 {
+		t.Fatalf("NewClient must check the signer for both APIs, got digests %v", stub.hashes)
+	}
+	stub.hashes = nil
+	if _, err := client.Acquiring().CreateSession(context.Background(), &acquiring.CreateSessionRequest{MerchantID: "1", ClientPhone: "+380982850620"}); err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+
+	sig, err := client.SignComfort([]byte(`{}`))
+	if err != nil {
+		t.Fatalf("sign comfort: %v", err)
+	}
+	if err := (&signature.RSASigner{PublicKey: &key.PublicKey, Hash: signature.HashSHA1}).Verify([]byte(`{}`), sig); err != nil {
+		t.Fatalf("comfort signature verify failed: %v", err)
+	}
+
+	if len(stub.hashes) != 2 || stub.hashes[0] != crypto.SHA256 || stub.hashes[1] != crypto.SHA1 {
+		t.Fatalf("unexpected digests passed to crypto signer: %v", stub.hashes)
+	}
+}
+
+type staticSigner string
+
+func (s staticSigner) Sign([]byte) (string, error) { return string(s), nil }
+
+func TestWithSignerOverridesRequestSigning(t *testing.T) {
+	var got string
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Get("x-sign")
+		_, _ = w.Write([]byte(`{"id":"session-id"}`))
+	}))
+	defer ts.Close()
+
+	client, err := NewClient(WithSigner(staticSigner("agent-signature")), WithAcquiringBaseURL(ts.URL), WithLogger(nil))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	if _, err := client.Acquiring().CreateSession(context.Background(), &acquiring.CreateSessionRequest{MerchantID: "1", ClientPhone: "+380982850620"}); err != nil {
+		t.Fatalf("create session: %v", err)
+	}
+	if got != "agent-signature" {
+		t.Fatalf("unexpected x-sign: %q", got)
+	}
+}
+
+func TestWithCryptoSignerRejectsNonRSAKey(t *testing.T) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	if _, err := NewClient(WithCryptoSigner(key)); err == nil {
+		t.Fatalf("expected error for ECDSA signer")
+	}
+}
+
+func TestPerAPISigningKeys(t *testing.T) {
+	externalKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	comfortKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	client, err := NewClient(
+		WithExternalPrivateKey(externalKey),
+		WithComfortPrivateKey(comfortKey),
+		WithExternalPublicKey(&externalKey.PublicKey),
+		WithComfortPublicKey(&comfortKey.PublicKey),
+		WithComfortMerchantID("42"),
+		WithLogger(nil),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	body := []byte(`{"id":"1"}`)
+	externalSig, err := client.Sign(body)
+	if err != nil {
+		t.Fatalf("sign: %v", err)
+	}
+	comfortSig, err := client.SignComfort(body)
+	if err != nil {
+		t.Fatalf("sign comfort: %v", err)
+	}
+	if err := client.Verify(body, externalSig); err != nil {
+		t.Fatalf("verify external: %v", err)
+	}
+	if err := client.VerifyComfort(body, comfortSig); err != nil {
+		t.Fatalf("verify comfort: %v", err)
+	}
+	if err := client.VerifyComfort(body, externalSig); err == nil {
+		t.Fatalf("comfort verification must not trust the external key")
+	}
+}
+
+// brokenKMS has an RSA public key but cannot sign, e.g. a revoked remote key.
+type brokenKMS struct{ kmsStub }
+
+func (s *brokenKMS) Sign(io.Reader, []byte, crypto.SignerOpts) ([]byte, error) {
+	return nil, errors.New("key is disabled")
+}
+
+func TestNewClientChecksEverySigningKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	broken := &brokenKMS{kmsStub{key: key}}
+
+	_, err = NewClient(WithExternalPrivateKey(key), WithComfortCryptoSigner(broken), WithComfortMerchantID("42"))
+	if err == nil || !strings.Contains(err.Error(), "Comfort signing key") {
+		t.Fatalf("expected the Comfort key to fail, got %v", err)
+	}
+	_, err = NewClient(WithExternalCryptoSigner(broken), WithComfortPrivateKey(key), WithComfortMerchantID("42"))
+	if err == nil || !strings.Contains(err.Error(), "Acquiring/Checkout signing key") {
+		t.Fatalf("expected the Acquiring/Checkout key to fail, got %v", err)
+	}
+	_, err = NewClient(WithPrivateKey(key), WithMerchant("shop", NewMerchant("101", WithComfortCryptoSigner(broken), WithComfortMerchantID("7"))))
+	if err == nil || !strings.Contains(err.Error(), `merchant "shop": Comfort signing key`) {
+		t.Fatalf("expected the merchant's Comfort key to fail, got %v", err)
+	}
+	if _, err := NewClient(WithExternalCryptoSigner(broken), WithExternalSigner(staticSigner("sig"))); err != nil {
+		t.Fatalf("a custom Signer replaces the broken key and is not called: %v", err)
+	}
+}
+
+func TestNewClientRequiresComfortSigningKey(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+
+	if _, err := NewClient(WithExternalPrivateKey(key), WithComfortMerchantID("42")); err == nil {
+		t.Fatalf("expected error for Comfort merchant without signing key")
+	}
+	if _, err := NewClient(WithComfortPrivateKey(key), WithComfortMerchantID("42")); err != nil {
+		t.Fatalf("comfort-only client: %v", err)
+	}
+	if _, err := NewClient(WithExternalPrivateKey(key)); err != nil {
+		t.Fatalf("external-only client: %v", err)
+	}
+}
@@ -0,0 +1,156 @@
+package go_nova
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/stremovskyy/go-nova/internal/httpclient"
+)
+
+// Span attributes set by the SDK.
+const (
+	AttrMerchantID   = "novapay.merchant_id"
+	AttrSessionID    = "novapay.session_id"
+	AttrRetryAttempt = "novapay.retry.attempt"
+	AttrStatusCode   = "http.response.status_code"
+	AttrErrorClass   = "error.type"
+)
+
+// Tracer creates the spans of SDK calls; see WithTracer.
+//
+// The novaotel module implements it with OpenTelemetry. Methods are called from
+// concurrent requests.
+type Tracer interface {
+	// StartCall starts the span of a service call, e.g. "AcquiringService.CreateSession".
+	StartCall(ctx context.Context, name string) (context.Context, Span)
+	// StartAttempt starts a client span for one HTTP attempt of the call in ctx and
+	// may add trace context headers to req.
+	StartAttempt(ctx context.Context, req *http.Request, attempt int) (context.Context, Span)
+}
+
+// Span is a span started by a Tracer. Keys are the Attr constants above.
+type Span interface {
+	SetString(key, value string)
+	SetInt(key string, value int)
+	// End finishes the span. A non-empty class (the AttrErrorClass value) marks it
+	// failed; err is the failure, nil when only the status code tells.
+	End(class string, err error)
+}
+
+// WithTracer enables tracing with t.
+//
+// Every service call gets a span named after it, e.g. "AcquiringService.CreateSession",
+// with the merchant and session IDs. Each HTTP attempt, retries included, is a child
+// span carrying the attempt number and status code; the last attempt's values are
+// copied to the call span. Failed spans get AttrErrorClass.
+//
+// Without it the SDK creates no spans.
+func WithTracer(t Tracer) Option {
+	return func(cfg *config) error {
+		if t == nil {
+			return errors.New("tracer is nil")
+		}
+		cfg.tracer = t
+		return nil
+	}
+}
+
+type noopSpan struct{}
+
+func (noopSpan) SetString(string, string) {}
+func (noopSpan) SetInt(string, int)       {}
+func (noopSpan) End(string, error)        {}
+
+// callSpanKey keys the span of the current service call in a context.
+type callSpanKey struct{}
+
+// startSpan starts the span of a service call. Finish it with endSpan.
+func (c *Client) startSpan(ctx context.Context, operation string) (context.Context, Span) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	if c == nil || c.cfg.tracer == nil {
+		return ctx, noopSpan{}
+	}
+	ctx, span := c.cfg.tracer.StartCall(ctx, operation)
+	return context.WithValue(ctx, callSpanKey{}, span), span
+}
+
+// setSessionAttrs records non-empty merchant and session IDs on span.
+func setSessionAttrs(span Span, merchantID, sessionID string) {
+	if merchantID != "" {
+		span.SetString(AttrMerchantID, merchantID)
+	}
+	if sessionID != "" {
+		span.SetString(AttrSessionID, sessionID)
+	}
+}
+
+// endSpan records err on span and ends it.
+func endSpan(span Span, err error) {
+	span.End(errorClass(err), err)
+}
+
+// errorClass names the kind of err for span attributes: a status code for API
+// errors, otherwise a short word such as "validation" or "transport".
+func errorClass(err error) string {
+	var (
+		ae *APIError
+		hs *httpclient.HTTPStatusError
+		ue *url.Error
+		ne net.Error
+	)
+	switch {
+	case err == nil:
+		return ""
+	case errors.Is(err, context.Canceled):
+		return "canceled"
+	case errors.Is(err, context.DeadlineExceeded):
+		return "timeout"
+	case IsValidationError(err):
+		return "validation"
+	case IsSessionStatusError(err):
+		return "session_status"
+	case errors.As(err, &ae):
+		return strconv.Itoa(ae.StatusCode)
+	case errors.As(err, &hs):
+		return strconv.Itoa(hs.StatusCode)
+	case errors.As(err, &ue), errors.As(err, &ne):
+		return "transport"
+	default:
+		return "other"
+	}
+}
+
+// tracingMiddleware wraps every HTTP attempt in a span of tracer.
+func tracingMiddleware(tracer Tracer) Middleware {
+	return func(next RoundTrip) RoundTrip {
+		return func(ctx context.Context, req *HTTPRequest) (*HTTPResponse, error) {
+			call, ok := ctx.Value(callSpanKey{}).(Span)
+			if !ok {
+				call = noopSpan{}
+			}
+			ctx, span := tracer.StartAttempt(ctx, req.HTTP, req.Attempt)
+			span.SetInt(AttrRetryAttempt, req.Attempt)
+			call.SetInt(AttrRetryAttempt, req.Attempt)
+			req.HTTP = req.HTTP.WithContext(ctx)
+
+			res, err := next(ctx, req)
+			class := errorClass(err)
+			if res != nil && res.HTTP != nil {
+				code := res.HTTP.StatusCode
+				span.SetInt(AttrStatusCode, code)
+				call.SetInt(AttrStatusCode, code)
+				if code >= 400 && class == "" {
+					class = strconv.Itoa(code)
+				}
+			}
+			span.End(class, err)
+			return res, err
+		}
+	}
+}
@@ -0,0 +1,153 @@
+package go_nova
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stremovskyy/go-nova/acquiring"
+)
+
+// recordingTracer keeps every span it starts.
+type recordingTracer struct {
+	mu    sync.Mutex
+	spans []*recordedSpan
+}
+
+type recordedSpan struct {
+	name   string
+	parent *recordedSpan
+	attrs  map[string]any
+	class  string
+	ended  bool
+}
+
+type spanKey struct{}
+
+func (t *recordingTracer) start(ctx context.Context, name string) (context.Context, Span) {
+	parent, _ := ctx.Value(spanKey{}).(*recordedSpan)
+	s := &recordedSpan{name: name, parent: parent, attrs: map[string]any{}}
+	t.mu.Lock()
+	t.spans = append(t.spans, s)
+	t.mu.Unlock()
+	return context.WithValue(ctx, spanKey{}, s), s
+}
+
+func (t *recordingTracer) StartCall(ctx context.Context, name string) (context.Context, Span) {
+	return t.start(ctx, name)
+}
+
+func (t *recordingTracer) StartAttempt(ctx context.Context, req *http.Request, _ int) (context.Context, Span) {
+	req.Header.Set("X-Trace", "1")
+	return t.start(ctx, "HTTP "+req.Method)
+}
+
+func (s *recordedSpan) SetString(key, value string)  { s.attrs[key] = value }
+func (s *recordedSpan) SetInt(key string, value int) { s.attrs[key] = value }
+func (s *recordedSpan) End(class string, _ error)    { s.class, s.ended = class, true }
+
+func TestTracingSpansPerCallAndAttempt(t *testing.T) {
+	var (
+		mu     sync.Mutex
+		calls  int
+		traced []string
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		calls++
+		n := calls
+		traced = append(traced, r.Header.Get("X-Trace"))
+		mu.Unlock()
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/expire"):
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"code":"session_not_found"}`))
+		case n == 1:
+			w.WriteHeader(http.StatusServiceUnavailable)
+		default:
+			_, _ = w.Write([]byte(`{"id":"s-1","status":"paid"}`))
+		}
+	}))
+	defer ts.Close()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	tracer := &recordingTracer{}
+	client, err := NewClient(
+		WithPrivateKey(key),
+		WithAcquiringBaseURL(ts.URL),
+		WithRetry(2, time.Millisecond),
+		WithLogger(nil),
+		WithTracer(tracer),
+	)
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	req := &acquiring.SessionRequest{MerchantID: "m-1", SessionID: "s-1"}
+	if _, err := client.Acquiring().GetStatus(context.Background(), req); err != nil {
+		t.Fatalf("get status: %v", err)
+	}
+	if len(tracer.spans) != 3 {
+		t.Fatalf("want 1 call span and 2 attempt spans, got %d", len(tracer.spans))
+	}
+	call := tracer.spans[0]
+	if call.name != "AcquiringService.GetStatus" || !call.ended || call.class != "" {
+		t.Fatalf("unexpected call span: %+v", call)
+	}
+	for key, want := range map[string]any{
+		AttrMerchantID:   "m-1",
+		AttrSessionID:    "s-1",
+		AttrRetryAttempt: 2,
+		AttrStatusCode:   http.StatusOK,
+	} {
+		if got := call.attrs[key]; got != want {
+			t.Fatalf("call span %s = %v, want %v", key, got, want)
+		}
+	}
+	for i, attempt := range tracer.spans[1:] {
+		if attempt.parent != call || attempt.attrs[AttrRetryAttempt] != i+1 {
+			t.Fatalf("attempt %d: unexpected span %+v", i+1, attempt)
+		}
+		if traced[i] != "1" {
+			t.Fatalf("attempt %d was sent without the tracer's headers", i+1)
+		}
+	}
+	if tracer.spans[1].class != "503" {
+		t.Fatalf("first attempt error class = %q", tracer.spans[1].class)
+	}
+
+	tracer.spans = nil
+	if err := client.Acquiring().ExpireSession(context.Background(), req); err == nil {
+		t.Fatal("expire must fail")
+	}
+	if call := tracer.spans[0]; call.name != "AcquiringService.ExpireSession" || call.class != "404" {
+		t.Fatalf("unexpected call span: %+v", call)
+	}
+}
+
+func TestErrorClass(t *testing.T) {
+	cases := map[string]error{
+		"validation": &ValidationError{},
+		"timeout":    context.DeadlineExceeded,
+		"500":        &APIError{StatusCode: 500},
+		"other":      errTest("boom"),
+	}
+	for want, err := range cases {
+		if got := errorClass(err); got != want {
+			t.Fatalf("errorClass(%v) = %q, want %q", err, got, want)
+		}
+	}
+}
+
+type errTest string
+
+func (e errTest) Error() string { return string(e) }
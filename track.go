@@ -0,0 +1,332 @@
+package go_nova
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/stremovskyy/go-nova/comfort"
+	"github.com/stremovskyy/go-nova/consts"
+	"github.com/stremovskyy/go-nova/internal/utils"
+)
+
+// Defaults used by TrackOperations.
+const (
+	DefaultTrackConcurrency = 4
+	DefaultTrackRateLimit   = 10
+)
+
+// DefaultTrackBackoff is the delay between polls of one operation unless TrackBackoff is given.
+var DefaultTrackBackoff Backoff = ExponentialBackoff{Initial: 2 * time.Second, Max: time.Minute, Multiplier: 2}
+
+// OperationUpdate is a status change reported by TrackOperations.
+type OperationUpdate struct {
+	GUID     string
+	PublicID string
+	Status   consts.OperationStatus
+	// Previous is the status seen before, empty on the first update.
+	Previous consts.OperationStatus
+	// Err is set when tracking of the operation stopped without a final status,
+	// e.g. because NovaPay does not know the GUID.
+	Err error
+}
+
+// TrackOption configures TrackOperations.
+type TrackOption func(*trackOptions)
+
+type trackOptions struct {
+	concurrency int
+	rateLimit   float64
+	backoff     Backoff
+}
+
+// TrackConcurrency sets how many workers poll operations, and so how many status
+// requests TrackOperations sends at once.
+func TrackConcurrency(n int) TrackOption {
+	return func(o *trackOptions) {
+		if n > 0 {
+			o.concurrency = n
+		}
+	}
+}
+
+// TrackRateLimit caps status requests per second across all tracked operations.
+// A value <= 0 disables the limit.
+func TrackRateLimit(perSecond float64) TrackOption {
+	return func(o *trackOptions) {
+		o.rateLimit = perSecond
+	}
+}
+
+// TrackBackoff sets the delay between polls of one operation. Delays below
+// MinPollDelay are raised to it.
+func TrackBackoff(b Backoff) TrackOption {
+	return func(o *trackOptions) {
+		if b != nil {
+			o.backoff = b
+		}
+	}
+}
+
+// TrackOperations polls OperationsStatus for every GUID until it reaches a final
+// status (see consts.OperationStatus.IsFinal).
+//
+// Every status change is sent on the returned channel, which is closed when all
+// operations are final or have failed, or when ctx ends. Transient errors are
+// retried on the next poll; an operation NovaPay rejects (e.g. ErrOperationNotFound)
+// is reported once with Err set and no longer polled. The caller must drain the channel.
+func (s *ComfortService) TrackOperations(ctx context.Context, guids []string, opts ...TrackOption) (<-chan OperationUpdate, error) {
+	if s == nil || s.c == nil {
+		return nil, errors.New("client is nil")
+	}
+	if err := ensureComfortReady(s.c); err != nil {
+		return nil, err
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	o := &trackOptions{concurrency: DefaultTrackConcurrency, rateLimit: DefaultTrackRateLimit, backoff: DefaultTrackBackoff}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+
+	t := &tracker{
+		s:       s,
+		opts:    o,
+		out:     make(chan OperationUpdate),
+		limiter: newRateLimiter(o.rateLimit),
+		wake:    make(chan struct{}, o.concurrency),
+		done:    make(chan struct{}),
+	}
+	seen := map[string]bool{}
+	now := time.Now()
+	for _, guid := range guids {
+		if guid == "" || seen[guid] {
+			continue
+		}
+		seen[guid] = true
+		heap.Push(&t.queue, &trackedOperation{guid: guid, due: now, attempt: 1})
+	}
+	t.remaining = len(t.queue)
+	if t.remaining == 0 {
+		close(t.done)
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < o.concurrency; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				op, ok := t.next(ctx)
+				if !ok {
+					return
+				}
+				t.track(ctx, op)
+			}
+		}()
+	}
+	go func() {
+		wg.Wait()
+		close(t.out)
+	}()
+	return t.out, nil
+}
+
+// tracker polls operations with a fixed pool of workers that take the operation
+// due first from a shared queue.
+type tracker struct {
+	s       *ComfortService
+	opts    *trackOptions
+	out     chan OperationUpdate
+	limiter *rateLimiter
+
+	mu        sync.Mutex
+	queue     trackQueue
+	remaining int           // operations not finished yet, queued or being polled
+	wake      chan struct{} // signals workers that the queue changed
+	done      chan struct{} // closed when remaining drops to zero
+}
+
+type trackedOperation struct {
+	guid    string
+	due     time.Time
+	attempt int
+	prev    consts.OperationStatus
+}
+
+// trackQueue is a min-heap of operations ordered by due time.
+type trackQueue []*trackedOperation
+
+func (q trackQueue) Len() int           { return len(q) }
+func (q trackQueue) Less(i, j int) bool { return q[i].due.Before(q[j].due) }
+func (q trackQueue) Swap(i, j int)      { q[i], q[j] = q[j], q[i] }
+func (q *trackQueue) Push(x any)        { *q = append(*q, x.(*trackedOperation)) }
+func (q *trackQueue) Pop() any {
+	old := *q
+	op := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return op
+}
+
+// next waits until an operation is due and takes it from the queue. It returns
+// false when every operation is finished or ctx ends.
+func (t *tracker) next(ctx context.Context) (*trackedOperation, bool) {
+	for {
+		t.mu.Lock()
+		var wait <-chan time.Time
+		var timer *time.Timer
+		if len(t.queue) > 0 {
+			d := time.Until(t.queue[0].due)
+			if d <= 0 {
+				op := heap.Pop(&t.queue).(*trackedOperation)
+				t.mu.Unlock()
+				return op, true
+			}
+			timer = time.NewTimer(d)
+			wait = timer.C
+		}
+		t.mu.Unlock()
+
+		select {
+		case <-wait:
+		case <-t.wake:
+		case <-t.done:
+		case <-ctx.Done():
+		}
+		if timer != nil {
+			timer.Stop()
+		}
+		select {
+		case <-t.done:
+			return nil, false
+		case <-ctx.Done():
+			return nil, false
+		default:
+		}
+	}
+}
+
+// requeue schedules the next poll of op, or finishes it when it is nil.
+func (t *tracker) requeue(op *trackedOperation) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if op == nil {
+		t.remaining--
+		if t.remaining == 0 {
+			close(t.done)
+		}
+		return
+	}
+	heap.Push(&t.queue, op)
+	select {
+	case t.wake <- struct{}{}:
+	default:
+	}
+}
+
+// track polls op once and requeues it unless it is finished.
+func (t *tracker) track(ctx context.Context, op *trackedOperation) {
+	st, err := t.poll(ctx, op.guid)
+	switch {
+	case ctx.Err() != nil:
+		return
+	case err != nil && trackingStopped(err):
+		t.send(ctx, OperationUpdate{GUID: op.guid, Previous: op.prev, Err: err})
+		t.requeue(nil)
+		return
+	case err != nil:
+		t.s.c.cfg.logger.Warnf("[NovaPay] operation status poll failed, retrying: guid=%s attempt=%d err=%v", op.guid, op.attempt, err)
+	case st.Status != op.prev:
+		if !t.send(ctx, OperationUpdate{GUID: op.guid, PublicID: st.PublicID, Status: st.Status, Previous: op.prev}) {
+			return
+		}
+		op.prev = st.Status
+	}
+	if op.prev.IsFinal() {
+		t.requeue(nil)
+		return
+	}
+	op.due = time.Now().Add(pollDelay(t.opts.backoff, op.attempt))
+	op.attempt++
+	t.requeue(op)
+}
+
+// poll sends one status request within the rate limit.
+func (t *tracker) poll(ctx context.Context, guid string) (*comfort.OperationsStatusResponse, error) {
+	if err := t.limiter.wait(ctx); err != nil {
+		return nil, err
+	}
+	st, err := t.s.OperationsStatus(ctx, &comfort.OperationsStatusRequest{GUID: utils.Ref(guid)})
+	if err == nil && st == nil {
+		err = errors.New("operations status returned no response")
+	}
+	return st, err
+}
+
+func (t *tracker) send(ctx context.Context, u OperationUpdate) bool {
+	select {
+	case t.out <- u:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// trackingStopped reports whether polling the operation again cannot succeed.
+func trackingStopped(err error) bool {
+	if IsValidationError(err) {
+		return true
+	}
+	var ae *APIError
+	if errors.As(err, &ae) {
+		return ae.StatusCode >= http.StatusBadRequest && ae.StatusCode < http.StatusInternalServerError && ae.StatusCode != http.StatusTooManyRequests
+	}
+	return false
+}
+
+// rateLimiter spaces calls evenly at a fixed rate. A nil limiter does not limit.
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	next     time.Time
+}
+
+func newRateLimiter(perSecond float64) *rateLimiter {
+	if perSecond <= 0 {
+		return nil
+	}
+	return &rateLimiter{interval: time.Duration(float64(time.Second) / perSecond)}
+}
+
+func (l *rateLimiter) wait(ctx context.Context) error {
+	if l == nil {
+		return nil
+	}
+	l.mu.Lock()
+	now := time.Now()
+	slot := l.next
+	if slot.Before(now) {
+		slot = now
+	}
+	l.next = slot.Add(l.interval)
+	l.mu.Unlock()
+
+	d := time.Until(slot)
+	if d <= 0 {
+		return nil
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,132 @@
+package go_nova
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stremovskyy/go-nova/comfort"
+	"github.com/stremovskyy/go-nova/consts"
+)
+
+func TestTrackOperations(t *testing.T) {
+	// Each GUID walks through its statuses, one per poll; "missing" is unknown to NovaPay.
+	statuses := map[string][]consts.OperationStatus{
+		"a": {consts.OperationStatusCreated, consts.OperationStatusProcessing, consts.OperationStatusProcessing, consts.OperationStatusSuccess},
+		"b": {consts.OperationStatusCreated, consts.OperationStatusFailed},
+	}
+	var (
+		mu       sync.Mutex
+		polls    = map[string]int{}
+		inFlight int
+		maxSeen  int
+	)
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req comfort.OperationsStatusRequest
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		mu.Lock()
+		inFlight++
+		maxSeen = max(maxSeen, inFlight)
+		seq, ok := statuses[*req.GUID]
+		n := polls[*req.GUID]
+		polls[*req.GUID]++
+		mu.Unlock()
+		time.Sleep(2 * time.Millisecond)
+		mu.Lock()
+		inFlight--
+		mu.Unlock()
+
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			_, _ = w.Write([]byte(`{"code":"operation_not_found"}`))
+			return
+		}
+		_ = json.NewEncoder(w).Encode(comfort.OperationsStatusResponse{Status: seq[min(n, len(seq)-1)], PublicID: "op-" + *req.GUID})
+	}))
+	defer ts.Close()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	client, err := NewClient(WithPrivateKey(key), WithComfortBaseURL(ts.URL), WithComfortMerchantID("42"), WithLogger(nil))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	updates, err := client.Comfort().TrackOperations(ctx, []string{"a", "b", "missing", "a"},
+		TrackConcurrency(1), TrackRateLimit(0), TrackBackoff(ConstantBackoff(time.Millisecond)))
+	if err != nil {
+		t.Fatalf("track: %v", err)
+	}
+
+	got := map[string][]consts.OperationStatus{}
+	var missingErr error
+	for u := range updates {
+		if u.Err != nil {
+			missingErr = u.Err
+			continue
+		}
+		got[u.GUID] = append(got[u.GUID], u.Status)
+	}
+
+	if len(got["a"]) != 3 || got["a"][2] != consts.OperationStatusSuccess {
+		t.Fatalf("expected created, processing, success for a; got %v", got["a"])
+	}
+	if len(got["b"]) != 2 || got["b"][1] != consts.OperationStatusFailed {
+		t.Fatalf("expected created, failed for b; got %v", got["b"])
+	}
+	if !errors.Is(missingErr, ErrOperationNotFound) {
+		t.Fatalf("expected ErrOperationNotFound for missing GUID, got %v", missingErr)
+	}
+	if polls["a"] != 4 || polls["missing"] != 1 || maxSeen != 1 {
+		t.Fatalf("unexpected polling: %v, max concurrency %d", polls, maxSeen)
+	}
+}
+
+func TestTrackOperationsUsesWorkerPool(t *testing.T) {
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_ = json.NewEncoder(w).Encode(comfort.OperationsStatusResponse{Status: consts.OperationStatusSuccess, PublicID: "op"})
+	}))
+	defer ts.Close()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	client, err := NewClient(WithPrivateKey(key), WithComfortBaseURL(ts.URL), WithComfortMerchantID("42"), WithLogger(nil))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+
+	guids := make([]string, 500)
+	for i := range guids {
+		guids[i] = fmt.Sprintf("g-%d", i)
+	}
+	before := runtime.NumGoroutine()
+	updates, err := client.Comfort().TrackOperations(context.Background(), guids, TrackConcurrency(2), TrackRateLimit(0))
+	if err != nil {
+		t.Fatalf("track: %v", err)
+	}
+	if n := runtime.NumGoroutine() - before; n > 10 {
+		t.Fatalf("tracking must not start a goroutine per operation, got %d new goroutines", n)
+	}
+	n := 0
+	for range updates {
+		n++
+	}
+	if n != len(guids) {
+		t.Fatalf("expected %d updates, got %d", len(guids), n)
+	}
+}
@@ -0,0 +1,243 @@
+package go_nova
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/stremovskyy/go-nova/acquiring"
+	"github.com/stremovskyy/go-nova/checkout"
+	"github.com/stremovskyy/go-nova/consts"
+)
+
+// Backoff returns the delay before status poll number attempt+1 (attempt starts at 1).
+type Backoff interface {
+	Delay(attempt int) time.Duration
+}
+
+// ConstantBackoff waits the same interval between polls.
+type ConstantBackoff time.Duration
+
+func (b ConstantBackoff) Delay(int) time.Duration { return time.Duration(b) }
+
+// ExponentialBackoff multiplies the delay after every poll, up to Max.
+type ExponentialBackoff struct {
+	Initial    time.Duration
+	Max        time.Duration
+	Multiplier float64
+}
+
+func (b ExponentialBackoff) Delay(attempt int) time.Duration {
+	d := float64(b.Initial)
+	m := b.Multiplier
+	if m < 1 {
+		m = 2
+	}
+	for i := 1; i < attempt; i++ {
+		d *= m
+		if b.Max > 0 && d >= float64(b.Max) {
+			return b.Max
+		}
+	}
+	return time.Duration(d)
+}
+
+// MinPollDelay is the shortest wait between two status polls. Shorter delays from a
+// Backoff, including zero and negative ones, are raised to it so that a
+// misconfigured policy cannot flood NovaPay with requests.
+const MinPollDelay = 100 * time.Millisecond
+
+// pollDelay returns the delay of b after poll number attempt, at least MinPollDelay.
+func pollDelay(b Backoff, attempt int) time.Duration {
+	return max(b.Delay(attempt), MinPollDelay)
+}
+
+// DefaultWaitBackoff is used by WaitForStatus unless WaitBackoff is given.
+var DefaultWaitBackoff Backoff = ExponentialBackoff{Initial: 500 * time.Millisecond, Max: 10 * time.Second, Multiplier: 2}
+
+// PollFunc observes every status poll. Returning an error stops waiting with that error.
+type PollFunc func(attempt int, status consts.SessionStatus) error
+
+// WaitOption configures WaitForStatus.
+type WaitOption func(*waitOptions)
+
+type waitOptions struct {
+	targets []consts.SessionStatus
+	backoff Backoff
+	onPoll  PollFunc
+}
+
+// WaitTarget sets the statuses WaitForStatus returns on.
+//
+// By default it returns on the first status that is neither "created" nor processing
+// (see consts.SessionStatus.IsProcessing).
+func WaitTarget(statuses ...consts.SessionStatus) WaitOption {
+	return func(o *waitOptions) {
+		o.targets = append(o.targets, statuses...)
+	}
+}
+
+// WaitBackoff sets the delay policy between polls. Delays below MinPollDelay are
+// raised to it.
+func WaitBackoff(b Backoff) WaitOption {
+	return func(o *waitOptions) {
+		if b != nil {
+			o.backoff = b
+		}
+	}
+}
+
+// WaitOnPoll registers fn to observe every poll.
+func WaitOnPoll(fn PollFunc) WaitOption {
+	return func(o *waitOptions) {
+		o.onPoll = fn
+	}
+}
+
+// WaitTimeoutError is returned when the context ends before the session reaches a target status.
+type WaitTimeoutError struct {
+	// LastStatus is the last status seen, empty if no poll succeeded.
+	LastStatus consts.SessionStatus
+	Attempts   int
+	Err        error
+}
+
+func (e *WaitTimeoutError) Error() string {
+	if e == nil {
+		return "wait for status: timeout"
+	}
+	return fmt.Sprintf("wait for status: %v after %d polls (last status %q)", e.Err, e.Attempts, e.LastStatus)
+}
+
+func (e *WaitTimeoutError) Unwrap() error {
+	if e == nil {
+		return nil
+	}
+	return e.Err
+}
+
+// TerminalStatusError is returned when the session reaches a terminal status (see
+// consts.SessionStatus.IsTerminal) that is not one of the WaitTarget statuses, so
+// waiting longer cannot help.
+type TerminalStatusError struct {
+	Status   consts.SessionStatus
+	Attempts int
+}
+
+func (e *TerminalStatusError) Error() string {
+	if e == nil {
+		return "wait for status: terminal status"
+	}
+	return fmt.Sprintf("wait for status: session ended in %q after %d polls", e.Status, e.Attempts)
+}
+
+func (o *waitOptions) reached(status consts.SessionStatus) bool {
+	if len(o.targets) == 0 {
+		return status != "" && status != consts.SessionStatusCreated && !status.IsProcessing()
+	}
+	for _, t := range o.targets {
+		if t == status {
+			return true
+		}
+	}
+	return false
+}
+
+// pollStatus calls get until it reports a target status, a terminal one, or ctx ends.
+func pollStatus[T any](ctx context.Context, opts []WaitOption, get func(context.Context) (T, consts.SessionStatus, error)) (T, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	o := &waitOptions{backoff: DefaultWaitBackoff}
+	for _, opt := range opts {
+		if opt != nil {
+			opt(o)
+		}
+	}
+
+	var (
+		last       T
+		lastStatus consts.SessionStatus
+	)
+	for attempt := 1; ; attempt++ {
+		out, status, err := get(ctx)
+		if err != nil {
+			if ctxErr := ctx.Err(); ctxErr != nil {
+				return last, &WaitTimeoutError{LastStatus: lastStatus, Attempts: attempt - 1, Err: ctxErr}
+			}
+			return last, err
+		}
+		last, lastStatus = out, status
+
+		if o.onPoll != nil {
+			if err := o.onPoll(attempt, status); err != nil {
+				return last, err
+			}
+		}
+		if o.reached(status) {
+			return last, nil
+		}
+		if status.IsTerminal() {
+			return last, &TerminalStatusError{Status: status, Attempts: attempt}
+		}
+
+		timer := time.NewTimer(pollDelay(o.backoff, attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return last, &WaitTimeoutError{LastStatus: lastStatus, Attempts: attempt, Err: ctx.Err()}
+		case <-timer.C:
+		}
+	}
+}
+
+// WaitForStatus polls GetStatus until the session reaches one of the target statuses.
+//
+// The context bounds the total wait; on expiry a *WaitTimeoutError carrying the last
+// seen status is returned together with the last response. A terminal status outside
+// the targets ends the wait early with a *TerminalStatusError.
+func (s *AcquiringService) WaitForStatus(ctx context.Context, req *acquiring.SessionRequest, opts ...WaitOption) (_ *acquiring.GetStatusResponse, err error) {
+	if s == nil || s.c == nil {
+		return nil, errors.New("client is nil")
+	}
+	ctx, span := s.c.startSpan(ctx, "AcquiringService.WaitForStatus")
+	defer func() { endSpan(span, err) }()
+	if req != nil {
+		setSessionAttrs(span, "", req.SessionID)
+	}
+	return pollStatus(ctx, opts, func(ctx context.Context) (*acquiring.GetStatusResponse, consts.SessionStatus, error) {
+		out, err := s.GetStatus(ctx, req)
+		if err != nil {
+			return nil, "", err
+		}
+		if out == nil {
+			return nil, "", errors.New("get status returned no response")
+		}
+		return out, out.Status, nil
+	})
+}
+
+// WaitForStatus polls GetStatus until the checkout session reaches one of the target statuses.
+//
+// See AcquiringService.WaitForStatus.
+func (s *CheckoutService) WaitForStatus(ctx context.Context, req *checkout.SessionRequest, opts ...WaitOption) (_ *checkout.StatusResponse, err error) {
+	if s == nil || s.c == nil {
+		return nil, errors.New("client is nil")
+	}
+	ctx, span := s.c.startSpan(ctx, "CheckoutService.WaitForStatus")
+	defer func() { endSpan(span, err) }()
+	if req != nil {
+		setSessionAttrs(span, "", req.SessionID)
+	}
+	return pollStatus(ctx, opts, func(ctx context.Context) (*checkout.StatusResponse, consts.SessionStatus, error) {
+		out, err := s.GetStatus(ctx, req)
+		if err != nil {
+			return nil, "", err
+		}
+		if out == nil {
+			return nil, "", errors.New("get status returned no response")
+		}
+		return out, out.Status, nil
+	})
+}
@@ -0,0 +1,138 @@
+package go_nova
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stremovskyy/go-nova/acquiring"
+	"github.com/stremovskyy/go-nova/consts"
+)
+
+func newStatusClient(t *testing.T, statuses ...string) (Nova, *int32) {
+	t.Helper()
+	var calls int32
+	ts := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := int(atomic.AddInt32(&calls, 1))
+		if n > len(statuses) {
+			n = len(statuses)
+		}
+		_, _ = w.Write([]byte(`{"id":"s-1","status":"` + statuses[n-1] + `"}`))
+	}))
+	t.Cleanup(ts.Close)
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("generate key: %v", err)
+	}
+	client, err := NewClient(WithPrivateKey(key), WithAcquiringBaseURL(ts.URL), WithLogger(nil))
+	if err != nil {
+		t.Fatalf("new client: %v", err)
+	}
+	return client, &calls
+}
+
+func TestWaitForStatusReturnsOnTarget(t *testing.T) {
+	client, calls := newStatusClient(t, "processing_hold_completion", "processing_hold_completion", "hold_confirmed")
+
+	var seen []consts.SessionStatus
+	out, err := client.Acquiring().WaitForStatus(context.Background(),
+		&acquiring.SessionRequest{MerchantID: "1", SessionID: "s-1"},
+		WaitBackoff(ConstantBackoff(time.Millisecond)),
+		WaitOnPoll(func(_ int, status consts.SessionStatus) error {
+			seen = append(seen, status)
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("wait for status: %v", err)
+	}
+	if out.Status != consts.SessionStatusHoldConfirmed {
+		t.Fatalf("unexpected final status: %q", out.Status)
+	}
+	if atomic.LoadInt32(calls) != 3 || len(seen) != 3 {
+		t.Fatalf("expected 3 polls, got %d calls and %v", atomic.LoadInt32(calls), seen)
+	}
+}
+
+func TestWaitForStatusTimeout(t *testing.T) {
+	client, _ := newStatusClient(t, "processing")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	_, err := client.Acquiring().WaitForStatus(ctx,
+		&acquiring.SessionRequest{MerchantID: "1", SessionID: "s-1"},
+		WaitTarget(consts.SessionStatusPaid),
+		WaitBackoff(ConstantBackoff(5*time.Millisecond)),
+	)
+
+	var te *WaitTimeoutError
+	if !errors.As(err, &te) {
+		t.Fatalf("expected WaitTimeoutError, got %v", err)
+	}
+	if te.LastStatus != consts.SessionStatusProcessing || te.Attempts == 0 {
+		t.Fatalf("unexpected timeout error: %+v", te)
+	}
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("timeout error must wrap context.DeadlineExceeded")
+	}
+}
+
+func TestWaitForStatusStopsOnOtherTerminalStatus(t *testing.T) {
+	client, calls := newStatusClient(t, "processing", "failed")
+
+	out, err := client.Acquiring().WaitForStatus(context.Background(),
+		&acquiring.SessionRequest{MerchantID: "1", SessionID: "s-1"},
+		WaitTarget(consts.SessionStatusPaid),
+		WaitBackoff(ConstantBackoff(time.Millisecond)),
+	)
+	var te *TerminalStatusError
+	if !errors.As(err, &te) || te.Status != consts.SessionStatusFailed || te.Attempts != 2 {
+		t.Fatalf("expected TerminalStatusError for failed, got %v", err)
+	}
+	if out == nil || out.Status != consts.SessionStatusFailed || atomic.LoadInt32(calls) != 2 {
+		t.Fatalf("expected the failed response after 2 polls, got %+v and %d calls", out, atomic.LoadInt32(calls))
+	}
+}
+
+func TestWaitForStatusClampsDelay(t *testing.T) {
+	for name, b := range map[string]Backoff{
+		"zero":     ConstantBackoff(0),
+		"negative": ConstantBackoff(-time.Second),
+		"empty":    ExponentialBackoff{},
+	} {
+		t.Run(name, func(t *testing.T) {
+			client, calls := newStatusClient(t, "processing")
+
+			ctx, cancel := context.WithTimeout(context.Background(), 250*time.Millisecond)
+			defer cancel()
+			_, err := client.Acquiring().WaitForStatus(ctx,
+				&acquiring.SessionRequest{MerchantID: "1", SessionID: "s-1"},
+				WaitTarget(consts.SessionStatusPaid),
+				WaitBackoff(b),
+			)
+			if !errors.Is(err, context.DeadlineExceeded) {
+				t.Fatalf("expected timeout, got %v", err)
+			}
+			if n := atomic.LoadInt32(calls); n > 3 {
+				t.Fatalf("delay must be at least %s, got %d polls in 250ms", MinPollDelay, n)
+			}
+		})
+	}
+}
+
+func TestExponentialBackoff(t *testing.T) {
+	b := ExponentialBackoff{Initial: 100 * time.Millisecond, Max: time.Second, Multiplier: 3}
+	want := []time.Duration{100 * time.Millisecond, 300 * time.Millisecond, 900 * time.Millisecond, time.Second, time.Second}
+	for i, w := range want {
+		if got := b.Delay(i + 1); got != w {
+			t.Fatalf("Delay(%d) = %s, want %s", i+1, got, w)
+		}
+	}
+}